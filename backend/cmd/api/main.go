@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,13 +14,16 @@ import (
 	"bc-reconciliation-backend/internal/blockchain"
 	"bc-reconciliation-backend/internal/config"
 	"bc-reconciliation-backend/internal/database"
+	"bc-reconciliation-backend/internal/grpcserver"
 	"bc-reconciliation-backend/internal/handler"
 	"bc-reconciliation-backend/internal/middleware"
 	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -59,16 +63,96 @@ func main() {
 	logger.Info("FISCO BCOS connected successfully")
 
 	// 5. 初始化服务层
-	// TODO: 从配置读取加密密钥
-	encryptionKey := "your-32-byte-encryption-key!!"
-
-	txService := service.NewTransactionService(db, bcClient, logger, encryptionKey)
+	txService := service.NewTransactionService(db, bcClient, logger,
+		cfg.Security.EncryptionKey, cfg.Security.BlindIndexKey)
+	profileService := service.NewImportProfileService(db, logger)
+	reportService := service.NewReportService(db, logger)
+	statisticsService := service.NewStatisticsService(db, logger)
+	certService := service.NewCertificateService(db, bcClient, cfg.Security.SigningKey, logger)
+	batchService := service.NewImportBatchService(db, logger)
+	groupService := service.NewGroupService(db, bcClient, txService, cfg.Security.EncryptionKey, logger)
+	matchingService := service.NewMatchingService(db, txService, cfg.Security.EncryptionKey, service.MatchTolerance{
+		AmountTolerance:     cfg.Matching.AmountTolerance,
+		AmountToleranceRate: cfg.Matching.AmountToleranceRate,
+		DateWindowDays:      cfg.Matching.DateWindowDays,
+		RequireSameParties:  cfg.Matching.RequireSameParties,
+		MinScore:            cfg.Matching.MinScore,
+		MaxCandidates:       cfg.Matching.MaxCandidates,
+		PoolLimit:           cfg.Matching.PoolLimit,
+	}, logger)
+	diagnosisService := service.NewDiagnosisService(db, cfg.Security.EncryptionKey, logger)
+	fxService := service.NewFXService(db, cfg.Security.EncryptionKey, service.FXOptions{
+		ReportingCurrency: cfg.FX.ReportingCurrency,
+		PivotCurrency:     cfg.FX.PivotCurrency,
+		MaxRateAgeDays:    cfg.FX.MaxRateAgeDays,
+	}, logger)
+	agingService := service.NewAgingService(db, service.AgingOptions{
+		SLADays:        cfg.Aging.SLADays,
+		Buckets:        cfg.Aging.Buckets,
+		AlertThreshold: cfg.Aging.AlertThreshold,
+	}, logger)
+	webhookService := service.NewWebhookService(db, cfg.Security.EncryptionKey, service.WebhookOptions{
+		Timeout:     time.Duration(cfg.Webhook.TimeoutSeconds) * time.Second,
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		RetryBase:   time.Duration(cfg.Webhook.RetryBaseSeconds) * time.Second,
+		RetryMax:    time.Duration(cfg.Webhook.RetryMaxSeconds) * time.Second,
+	}, logger)
+	txService.AddStatusNotifier(webhookService)
+	mailer := utils.NewSMTPMailer(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From)
+	notificationService := service.NewNotificationService(db, mailer, agingService, service.NotificationOptions{
+		DefaultLanguage: cfg.Mail.DefaultLanguage,
+		DigestHour:      cfg.Mail.DigestHour,
+	}, logger)
+	txService.AddStatusNotifier(notificationService)
+	consistencyService := service.NewConsistencyService(db, bcClient, txService, service.ConsistencyOptions{
+		AutoHeal:  cfg.Consistency.AutoHeal,
+		BatchSize: cfg.Consistency.BatchSize,
+	}, logger)
+	if err := consistencyService.MarkInterruptedRuns(); err != nil {
+		logger.Warn("Failed to mark interrupted consistency runs", zap.Error(err))
+	}
+	receiptTracker := service.NewReceiptTracker(db, bcClient, txService, logger)
 
 	// 6. 启动事件监听(Goroutine)
 	eventListener := blockchain.NewEventListener(bcClient, db, logger)
+	txService.AddStatusNotifier(eventListener.Broadcaster())
+	eventListener.OnTick(func(ctx context.Context) error {
+		_, err := txService.SyncUploadedStatuses(ctx)
+		return err
+	})
+	eventListener.OnTick(func(ctx context.Context) error {
+		_, err := groupService.SyncUploadedGroups(ctx)
+		return err
+	})
+	// 状态通知订阅方注册完成后再恢复中断的对账组上链
+	if err := groupService.RecoverInterruptedUploads(context.Background()); err != nil {
+		logger.Warn("Failed to recover interrupted group uploads", zap.Error(err))
+	}
 	go eventListener.Start()
 	logger.Info("Event listener started")
 
+	// 启动单边上链逾期告警检查、Webhook重试、上链回执追踪、每日摘要及链上一致性检查(Goroutine)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	if cfg.Aging.CheckIntervalMinutes > 0 {
+		go agingService.RunAlertChecks(workerCtx, time.Duration(cfg.Aging.CheckIntervalMinutes)*time.Minute)
+	}
+	webhookRetryInterval := time.Duration(cfg.Webhook.RetryIntervalSeconds) * time.Second
+	if webhookRetryInterval <= 0 {
+		webhookRetryInterval = 15 * time.Second
+	}
+	go webhookService.RunRetries(workerCtx, webhookRetryInterval)
+	receiptPollInterval := time.Duration(cfg.Blockchain.ReceiptPollSeconds) * time.Second
+	if receiptPollInterval <= 0 {
+		receiptPollInterval = 3 * time.Second
+	}
+	go receiptTracker.Run(workerCtx, receiptPollInterval)
+	if mailer.Configured() {
+		go notificationService.RunDailyDigest(workerCtx)
+	}
+	if cfg.Consistency.IntervalMinutes > 0 {
+		go consistencyService.RunScheduledChecks(workerCtx, time.Duration(cfg.Consistency.IntervalMinutes)*time.Minute)
+	}
+
 	// 7. 设置Gin
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -80,7 +164,7 @@ func main() {
 	router.Use(gin.Recovery())
 
 	// 8. 注册路由
-	setupRoutes(router, txService)
+	setupRoutes(router, txService, profileService, reportService, statisticsService, certService, batchService, groupService, matchingService, diagnosisService, fxService, agingService, webhookService, eventListener.Broadcaster(), notificationService, consistencyService)
 
 	// 9. 启动HTTP服务器
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -97,6 +181,33 @@ func main() {
 		}
 	}()
 
+	// 启动gRPC服务器(独立端口)
+	var grpcSrv *grpc.Server
+	if cfg.Server.GRPCPort > 0 {
+		grpcSrv, err = grpcserver.NewServer(txService, eventListener.Broadcaster(), grpcserver.Options{
+			TLSCertFile:     cfg.Server.TLSCertFile,
+			TLSKeyFile:      cfg.Server.TLSKeyFile,
+			ClientCA:        cfg.Server.ClientCA,
+			ContractAddress: cfg.Blockchain.ContractAddress,
+
+			InsecureInstitutionID: cfg.Server.GRPCInsecureInstitution,
+		}, logger)
+		if err != nil {
+			logger.Fatal("Failed to create gRPC server", zap.Error(err))
+		}
+		grpcAddr := fmt.Sprintf(":%d", cfg.Server.GRPCPort)
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Fatal("Failed to listen gRPC port", zap.Error(err))
+		}
+		go func() {
+			logger.Info("gRPC server started", zap.String("addr", grpcAddr))
+			if err := grpcSrv.Serve(lis); err != nil {
+				logger.Fatal("Failed to start gRPC server", zap.Error(err))
+			}
+		}()
+	}
+
 	// 10. 优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -104,9 +215,26 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
-	// 停止事件监听
+	// 停止事件监听(同时结束事件订阅流)
 	eventListener.Stop()
 
+	// 停止gRPC服务器, 超时未完成的流式调用将被强制关闭
+	if grpcSrv != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			grpcSrv.Stop()
+		}
+	}
+
+	// 停止后台任务
+	stopWorkers()
+
 	// 关闭数据库连接
 	database.Close(db)
 
@@ -165,7 +293,7 @@ func initLogger(cfg *config.Config) *zap.Logger {
 }
 
 // setupRoutes 注册路由
-func setupRoutes(router *gin.Engine, txService *service.TransactionService) {
+func setupRoutes(router *gin.Engine, txService *service.TransactionService, profileService *service.ImportProfileService, reportService *service.ReportService, statisticsService *service.StatisticsService, certService *service.CertificateService, batchService *service.ImportBatchService, groupService *service.GroupService, matchingService *service.MatchingService, diagnosisService *service.DiagnosisService, fxService *service.FXService, agingService *service.AgingService, webhookService *service.WebhookService, broadcaster *blockchain.Broadcaster, notificationService *service.NotificationService, consistencyService *service.ConsistencyService) {
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -178,25 +306,164 @@ func setupRoutes(router *gin.Engine, txService *service.TransactionService) {
 	v1 := router.Group("/api/v1")
 	{
 		// 交易相关
-		txHandler := handler.NewTransactionHandler(txService)
-		dashboardHandler := handler.NewDashboardHandler(txService)
+		txHandler := handler.NewTransactionHandler(txService, profileService)
+		dashboardHandler := handler.NewDashboardHandler(txService, fxService, broadcaster)
+		profileHandler := handler.NewImportProfileHandler(profileService)
+		reportHandler := handler.NewReportHandler(reportService)
+		statisticsHandler := handler.NewStatisticsHandler(statisticsService)
+		certHandler := handler.NewCertificateHandler(certService)
+		batchHandler := handler.NewImportBatchHandler(batchService)
+		groupHandler := handler.NewGroupHandler(groupService)
+		matchingHandler := handler.NewMatchingHandler(matchingService)
+		diagnosisHandler := handler.NewDiagnosisHandler(diagnosisService)
+		fxHandler := handler.NewFXHandler(fxService)
+		agingHandler := handler.NewAgingHandler(agingService)
+		webhookHandler := handler.NewWebhookHandler(webhookService)
+		notificationHandler := handler.NewNotificationHandler(notificationService)
+		consistencyHandler := handler.NewConsistencyHandler(consistencyService)
 
 		transactions := v1.Group("/transactions")
 		{
 			transactions.POST("", txHandler.CreateTransaction)
 			transactions.POST("/excel", txHandler.UploadExcel)
+			transactions.GET("/excel/errors/:reportId", txHandler.DownloadImportErrorReport)
 			transactions.POST("/upload-chain", txHandler.UploadToChain)
 			transactions.GET("/template", txHandler.DownloadExcelTemplate)
+			transactions.GET("/search", txHandler.SearchByAmount)
 			transactions.GET("/:bizId", txHandler.GetTransaction)
 			transactions.GET("", txHandler.ListTransactions)
 		}
 
+		// 导入方案相关
+		profiles := v1.Group("/import-profiles")
+		{
+			profiles.GET("", profileHandler.ListProfiles)
+			profiles.POST("", profileHandler.CreateProfile)
+			profiles.GET("/:id", profileHandler.GetProfile)
+			profiles.PUT("/:id", profileHandler.UpdateProfile)
+			profiles.DELETE("/:id", profileHandler.DeleteProfile)
+		}
+
+		// 导入批次相关
+		batches := v1.Group("/import-batches")
+		{
+			batches.GET("", batchHandler.ListBatches)
+			batches.GET("/:batchNo", batchHandler.GetBatch)
+			batches.GET("/:batchNo/transactions", batchHandler.ListBatchTransactions)
+			batches.POST("/:batchNo/rollback", batchHandler.RollbackBatch)
+		}
+
 		// 仪表板相关
 		dashboard := v1.Group("/dashboard")
 		{
 			dashboard.GET("/overview", dashboardHandler.GetOverview)
 			dashboard.GET("/statistics", txHandler.GetStatistics)
 			dashboard.GET("/chart-data", dashboardHandler.GetChartData)
+			dashboard.GET("/amount-totals", dashboardHandler.GetAmountTotals)
+			dashboard.GET("/stream", dashboardHandler.Stream)
+			dashboard.GET("/counterparty-matrix", statisticsHandler.GetCounterpartyMatrix)
+		}
+
+		// 报表相关
+		reports := v1.Group("/reports")
+		{
+			reports.GET("/reconciliation", reportHandler.ExportReconciliation)
+		}
+
+		// 对账证明相关
+		certificates := v1.Group("/certificates")
+		{
+			certificates.POST("", certHandler.CreateCertificate)
+			certificates.GET("", certHandler.ListCertificates)
+			certificates.GET("/:certNo", certHandler.GetCertificate)
+			certificates.GET("/:certNo/document", certHandler.DownloadCertificate)
+			certificates.POST("/:certNo/anchor", certHandler.AnchorCertificate)
+			certificates.GET("/:certNo/verify", certHandler.VerifyCertificate)
+		}
+
+		// 对账组相关(一对多/多对一)
+		groups := v1.Group("/groups")
+		{
+			groups.POST("", groupHandler.CreateGroup)
+			groups.GET("", groupHandler.ListGroups)
+			groups.GET("/:groupRef", groupHandler.GetGroup)
+			groups.DELETE("/:groupRef", groupHandler.DeleteGroup)
+			groups.POST("/:groupRef/upload", groupHandler.UploadGroup)
+			groups.POST("/:groupRef/sync", groupHandler.SyncGroup)
+		}
+
+		// 容差匹配相关
+		matching := v1.Group("/matching")
+		{
+			matching.GET("/candidates", matchingHandler.GetCandidates)
+			matching.GET("/suggestions", matchingHandler.GetSuggestions)
+			matching.POST("/accept", matchingHandler.AcceptMatch)
+			matching.GET("/adjustments", matchingHandler.ListAdjustments)
+			matching.POST("/adjustments/:id/confirm", matchingHandler.ConfirmAdjustment)
+			matching.DELETE("/adjustments/:id", matchingHandler.RevokeAdjustment)
+		}
+
+		// 对账失败字段诊断相关
+		diagnoses := v1.Group("/diagnoses")
+		{
+			diagnoses.POST("", diagnosisHandler.StartDiagnosis)
+			diagnoses.POST("/exchange", diagnosisHandler.ExchangePacket)
+			diagnoses.GET("/:bizId", diagnosisHandler.GetDiagnosis)
+		}
+
+		// 汇率相关
+		fxRates := v1.Group("/fx-rates")
+		{
+			fxRates.POST("", fxHandler.CreateRate)
+			fxRates.GET("", fxHandler.ListRates)
+			fxRates.POST("/import", fxHandler.ImportRates)
+			fxRates.DELETE("/:id", fxHandler.DeleteRate)
+		}
+
+		// 单边上链账龄相关
+		aging := v1.Group("/aging")
+		{
+			aging.GET("/report", agingHandler.GetReport)
+			aging.GET("/items", agingHandler.ListItems)
+			aging.GET("/alerts", agingHandler.ListAlerts)
+			aging.POST("/alerts/:id/ack", agingHandler.AcknowledgeAlert)
+			aging.POST("/check", agingHandler.CheckAlerts)
+		}
+
+		// Webhook订阅相关
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.POST("", webhookHandler.CreateSubscription)
+			webhooks.GET("", webhookHandler.ListSubscriptions)
+			webhooks.GET("/:id", webhookHandler.GetSubscription)
+			webhooks.PUT("/:id", webhookHandler.UpdateSubscription)
+			webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)
+			webhooks.POST("/:id/test", webhookHandler.TestDelivery)
+		}
+
+		webhookDeliveries := v1.Group("/webhook-deliveries")
+		{
+			webhookDeliveries.GET("", webhookHandler.ListDeliveries)
+			webhookDeliveries.GET("/:id", webhookHandler.GetDelivery)
+			webhookDeliveries.POST("/:id/replay", webhookHandler.ReplayDelivery)
+		}
+
+		// 邮件通知相关
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("/preferences", notificationHandler.GetPreference)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreference)
+			notifications.POST("/test", notificationHandler.SendTestMail)
+			notifications.POST("/digest", notificationHandler.SendDigest)
+		}
+
+		// 链上一致性检查相关
+		consistency := v1.Group("/consistency")
+		{
+			consistency.POST("/runs", consistencyHandler.StartRun)
+			consistency.GET("/runs", consistencyHandler.ListRuns)
+			consistency.GET("/runs/:id", consistencyHandler.GetRun)
+			consistency.GET("/runs/:id/drifts", consistencyHandler.ListDrifts)
 		}
 	}
 
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/config"
+	"bc-reconciliation-backend/internal/database"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// 导入吞吐量基准工具
+// 生成指定行数的CSV文件, 按不同并行度执行完整导入(校验、查重、加密、分块写入), 输出每秒行数, 结束后回滚批次
+// 用法: go run cmd/importbench/main.go -config configs/config.yaml -rows 100000 -workers 1,4,8 -chunk 1000
+func main() {
+	configPath := flag.String("config", "configs/config.yaml", "配置文件路径")
+	rows := flag.Int("rows", 100000, "生成的数据行数")
+	workerList := flag.String("workers", "1,4,8", "并行度列表, 逗号分隔")
+	chunk := flag.Int("chunk", 1000, "每次INSERT的行数")
+	institutionID := flag.String("institution", "BENCH", "导入使用的机构ID")
+	keep := flag.Bool("keep", false, "保留导入的数据(默认回滚批次)")
+	flag.Parse()
+
+	workers, err := parseWorkers(*workerList)
+	if err != nil {
+		log.Fatalf("Invalid workers: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger := zap.NewNop()
+
+	db, err := database.InitMySQL(&cfg.Database.MySQL)
+	if err != nil {
+		log.Fatalf("Failed to connect to MySQL: %v", err)
+	}
+	defer database.Close(db)
+
+	txService := service.NewTransactionService(db, nil, logger,
+		cfg.Security.EncryptionKey, cfg.Security.BlindIndexKey)
+	batchService := service.NewImportBatchService(db, logger)
+
+	fmt.Printf("rows=%d chunk=%d\n", *rows, *chunk)
+	fmt.Printf("%-8s %-12s %-12s %-12s %s\n", "workers", "validate", "import", "rows/s", "batch")
+
+	for _, w := range workers {
+		// 每轮使用不同的流水号前缀, 避免与上一轮数据重复
+		path, err := generateCSV(*rows, fmt.Sprintf("BENCH%d", time.Now().UnixNano()))
+		if err != nil {
+			log.Fatalf("Failed to generate file: %v", err)
+		}
+
+		start := time.Now()
+		if _, err := utils.ValidateImportFile(path, nil); err != nil {
+			log.Fatalf("Failed to validate file: %v", err)
+		}
+		validate := time.Since(start)
+
+		txService.SetImportConcurrency(w, *chunk)
+		start = time.Now()
+		result, err := txService.ImportFile(path, &service.ImportFileOptions{
+			FileName:      filepath.Base(path),
+			InstitutionID: *institutionID,
+			Uploader:      "importbench",
+			Mode:          service.ImportModeStrict,
+			Force:         true,
+		})
+		elapsed := time.Since(start)
+		os.Remove(path)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		if result.Success != *rows {
+			log.Fatalf("Import incomplete: %d/%d rows, %d errors", result.Success, *rows, len(result.Errors))
+		}
+
+		fmt.Printf("%-8d %-12s %-12s %-12.0f %s\n", w,
+			validate.Round(time.Millisecond), elapsed.Round(time.Millisecond),
+			float64(result.Success)/elapsed.Seconds(), result.BatchNo)
+
+		if !*keep {
+			if _, err := batchService.RollbackBatch(*institutionID, result.BatchNo); err != nil {
+				log.Fatalf("Failed to roll back batch %s: %v", result.BatchNo, err)
+			}
+		}
+	}
+}
+
+// parseWorkers 解析并行度列表
+func parseWorkers(s string) ([]int, error) {
+	var workers []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid worker count %q", part)
+		}
+		workers = append(workers, n)
+	}
+	return workers, nil
+}
+
+// generateCSV 生成默认表头的CSV导入文件
+func generateCSV(rows int, prefix string) (string, error) {
+	f, err := os.CreateTemp("", "importbench-*.csv")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "%s,%s,%s,%s,%s,%s\n",
+		utils.ColBizID, utils.ColAmount, utils.ColSender, utils.ColReceiver, utils.ColTxType, utils.ColTxDate)
+	date := time.Now().Format("2006-01-02")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(w, "%s-%08d,%d.%02d,付款方%d,收款方%d,1,%s\n",
+			prefix, i, 100+i%100000, i%100, i%50, i%70, date)
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
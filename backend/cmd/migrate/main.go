@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"bc-reconciliation-backend/internal/config"
+	"bc-reconciliation-backend/internal/database"
+
+	"go.uber.org/zap"
+)
+
+// 数据迁移工具
+// 用法: go run cmd/migrate/main.go -config configs/config.yaml
+func main() {
+	configPath := flag.String("config", "configs/config.yaml", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db, err := database.InitMySQL(&cfg.Database.MySQL)
+	if err != nil {
+		logger.Fatal("Failed to connect to MySQL", zap.Error(err))
+	}
+	defer database.Close(db)
+
+	// 1. 表结构迁移
+	if err := database.AutoMigrate(db); err != nil {
+		logger.Fatal("Auto migrate failed", zap.Error(err))
+	}
+	logger.Info("Auto migrate completed")
+
+	// 2. 金额盲索引重写
+	migrated, err := database.MigrateAmountBlindIndex(db, cfg.Security.EncryptionKey, cfg.Security.BlindIndexKey, logger)
+	if err != nil {
+		logger.Fatal("Amount blind index migration failed", zap.Error(err), zap.Int("migrated", migrated))
+	}
+	logger.Info("Amount blind index migration completed", zap.Int("migrated", migrated))
+}
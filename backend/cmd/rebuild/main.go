@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/config"
+	"bc-reconciliation-backend/internal/database"
+
+	"go.uber.org/zap"
+)
+
+// 链索引重建工具
+// 从指定区块高度重放链上区块, 解码对账合约的交易回执和事件, 重建 event_logs、chain_receipts、reconciliations
+// 写入均为幂等的 upsert, 可重复执行; 中断后按提示的高度使用 -from 续跑
+// 用法: go run cmd/rebuild/main.go -config configs/config.yaml -from 0 [-to 0] [-migrate=true]
+func main() {
+	configPath := flag.String("config", "configs/config.yaml", "配置文件路径")
+	from := flag.Int64("from", 0, "起始区块高度(含)")
+	to := flag.Int64("to", 0, "结束区块高度(含), 0 表示最新区块")
+	migrate := flag.Bool("migrate", true, "重建前执行表结构迁移(全新数据库需要)")
+	interval := flag.Duration("progress", 2*time.Second, "进度输出间隔")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 只输出告警及以上日志, 避免与进度输出混杂
+	logConfig := zap.NewProductionConfig()
+	logConfig.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
+	logger, err := logConfig.Build()
+	if err != nil {
+		log.Fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db, err := database.InitMySQL(&cfg.Database.MySQL)
+	if err != nil {
+		log.Fatalf("Failed to connect to MySQL: %v", err)
+	}
+	defer database.Close(db)
+
+	if *migrate {
+		if err := database.AutoMigrate(db); err != nil {
+			log.Fatalf("Auto migrate failed: %v", err)
+		}
+	}
+
+	bcClient, err := blockchain.NewClient(&cfg.Blockchain, logger)
+	if err != nil {
+		log.Fatalf("Failed to connect to blockchain: %v", err)
+	}
+	defer bcClient.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	var lastPrint time.Time
+	progress := func(stats *blockchain.RebuildStats) {
+		if time.Since(lastPrint) < *interval && stats.CurrentBlock != stats.ToBlock {
+			return
+		}
+		lastPrint = time.Now()
+		printProgress(stats, start)
+	}
+
+	fmt.Printf("rebuilding chain index from block %d (contract %s)\n", *from, cfg.Blockchain.ContractAddress)
+	stats, err := blockchain.NewRebuilder(bcClient, db, logger).Rebuild(ctx, *from, *to, progress)
+	if stats != nil {
+		printProgress(stats, start)
+	}
+	if err != nil {
+		if stats != nil && stats.CurrentBlock < stats.ToBlock {
+			fmt.Fprintf(os.Stderr, "stopped before block %d, resume with -from %d\n", stats.CurrentBlock+1, stats.CurrentBlock+1)
+		}
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
+		log.Fatalf("Rebuild failed: %v", err)
+	}
+	fmt.Printf("done in %s\n", time.Since(start).Round(time.Second))
+}
+
+// printProgress 输出重建进度
+func printProgress(stats *blockchain.RebuildStats, start time.Time) {
+	total := stats.ToBlock - stats.FromBlock + 1
+	done := stats.CurrentBlock - stats.FromBlock + 1
+	percent := 0.0
+	if total > 0 {
+		percent = float64(done) / float64(total) * 100
+	}
+	fmt.Printf("block %d/%d (%.1f%%) txs=%d events=%d receipts=%d reconciliations=%d elapsed=%s\n",
+		stats.CurrentBlock, stats.ToBlock, percent,
+		stats.Transactions, stats.Events, stats.Receipts, stats.Reconciliations,
+		time.Since(start).Round(time.Second))
+}
@@ -0,0 +1,149 @@
+package blockchain
+
+import (
+	"sync"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer 每个订阅者的事件缓冲长度, 缓冲满时丢弃新事件, 避免慢客户端阻塞监听
+const subscriberBuffer = 64
+
+// Broadcaster 事件广播器
+// 由事件监听器驱动, 将区块高度、状态变化及统计增量推送给仪表板订阅者
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	blockHeight int64
+	closed      bool
+	logger      *zap.Logger
+}
+
+// subscriber 订阅者
+type subscriber struct {
+	institutionID string
+	ch            chan *models.StreamEvent
+}
+
+// NewBroadcaster 创建事件广播器
+func NewBroadcaster(logger *zap.Logger) *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[*subscriber]struct{}),
+		logger:      logger,
+	}
+}
+
+// Subscribe 订阅指定机构的事件(含不区分机构的事件), 返回事件通道和取消订阅函数
+func (b *Broadcaster) Subscribe(institutionID string) (<-chan *models.StreamEvent, func()) {
+	sub := &subscriber{
+		institutionID: institutionID,
+		ch:            make(chan *models.StreamEvent, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		close(sub.ch)
+	} else {
+		b.subscribers[sub] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subscribers[sub]; ok {
+				delete(b.subscribers, sub)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+// Publish 推送事件, InstitutionID 为空时推送给所有订阅者
+func (b *Broadcaster) Publish(event *models.StreamEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if event.InstitutionID != "" && event.InstitutionID != sub.institutionID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			b.logger.Debug("stream subscriber too slow, event dropped",
+				zap.String("institution", sub.institutionID),
+				zap.String("type", event.Type))
+		}
+	}
+}
+
+// BlockHeight 最近一次观察到的区块高度
+func (b *Broadcaster) BlockHeight() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.blockHeight
+}
+
+// PublishBlockHeight 区块高度增长时推送
+func (b *Broadcaster) PublishBlockHeight(height int64) {
+	b.mu.Lock()
+	if height <= b.blockHeight {
+		b.mu.Unlock()
+		return
+	}
+	b.blockHeight = height
+	b.mu.Unlock()
+
+	b.Publish(&models.StreamEvent{
+		Type: models.StreamEventBlock,
+		Data: &models.BlockEventData{BlockHeight: height},
+	})
+}
+
+// NotifyStatusChange 推送交易状态变化及统计增量
+func (b *Broadcaster) NotifyStatusChange(tx *models.Transaction, previous int8) {
+	b.Publish(&models.StreamEvent{
+		Type:          models.StreamEventStatus,
+		InstitutionID: tx.InstitutionID,
+		Data: &models.StatusEventData{
+			BizID:          tx.BizID,
+			PreviousStatus: previous,
+			Status:         tx.Status,
+			StatusText:     tx.GetStatusText(),
+		},
+	})
+
+	delta := &models.StatisticsDelta{}
+	delta.Add(previous, -1)
+	delta.Add(tx.Status, 1)
+	b.Publish(&models.StreamEvent{
+		Type:          models.StreamEventStatistics,
+		InstitutionID: tx.InstitutionID,
+		Data:          delta,
+	})
+}
+
+// Close 关闭广播器, 结束所有订阅
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subscribers {
+		close(sub.ch)
+		delete(b.subscribers, sub)
+	}
+}
@@ -3,15 +3,19 @@ package blockchain
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
+	"time"
 
 	"bc-reconciliation-backend/internal/config"
 
 	"github.com/FISCO-BCOS/go-sdk/client"
 	"github.com/FISCO-BCOS/go-sdk/conf"
 	"github.com/FISCO-BCOS/go-sdk/core/types"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
@@ -23,6 +27,8 @@ type Client struct {
 	logger         *zap.Logger
 	contractHelper *ContractHelper
 	contractAddr   common.Address
+	isHTTP         bool // 节点连接方式, 影响异步提交交易的方式
+	signer         *Signer
 }
 
 // NewClient 创建区块链客户端
@@ -55,7 +61,14 @@ func NewClient(cfg *config.BlockchainConfig, logger *zap.Logger) (*Client, error
 		config:       cfg,
 		logger:       logger,
 		contractAddr: common.HexToAddress(cfg.ContractAddress),
+		isHTTP:       configs[0].IsHTTP,
+		signer: NewSigner(c, SignerOptions{
+			GasLimit:         cfg.GasLimit,
+			BlockLimitOffset: cfg.BlockLimitOffset,
+			BlockCacheTTL:    time.Duration(cfg.BlockCacheSeconds) * time.Second,
+		}),
 	}
+	blockchainClient.signer.ObserveBlockNumber(blockNumber)
 
 	// 加载智能合约ABI
 	if cfg.ContractAddress != "" && cfg.ContractAddress != `""` {
@@ -87,9 +100,14 @@ func (c *Client) GetContractAddress() common.Address {
 	return c.contractAddr
 }
 
-// GetBlockNumber 获取当前区块高度
+// GetBlockNumber 获取当前区块高度, 同时刷新签名器缓存的高度
 func (c *Client) GetBlockNumber(ctx context.Context) (int64, error) {
-	return c.client.GetBlockNumber(ctx)
+	blockNumber, err := c.client.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	c.signer.ObserveBlockNumber(blockNumber)
+	return blockNumber, nil
 }
 
 // GetSystemConfig 获取系统配置
@@ -103,33 +121,44 @@ func (c *Client) GetSystemConfig(ctx context.Context, key string) (string, error
 
 // ========== 合约调用方法 ==========
 
-// UploadTransaction 上传交易到区块链
-func (c *Client) UploadTransaction(ctx context.Context, bizId, dataHash string) (string, *types.Receipt, error) {
+// UploadTransaction 上传交易到区块链并等待回执, 交易执行失败(如合约拒绝)时返回 ErrTransactionReverted
+func (c *Client) UploadTransaction(ctx context.Context, bizId, dataHash string) (*ReceiptInfo, error) {
 	if c.contractHelper == nil {
-		return "", nil, fmt.Errorf("contract helper not initialized")
+		return nil, fmt.Errorf("contract helper not initialized")
 	}
 
 	// 编码合约调用数据
 	input, err := c.contractHelper.EncodeUploadTransaction(bizId, dataHash)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to encode uploadTransaction: %w", err)
+		return nil, fmt.Errorf("failed to encode uploadTransaction: %w", err)
 	}
 
 	// 发送交易
 	receipt, err := c.sendTransaction(ctx, input)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to send transaction: %w", err)
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	info, err := newReceiptInfo(receipt)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Success {
+		return info, fmt.Errorf("%w: %s (tx %s)", ErrTransactionReverted, info.Message, info.TxHash)
 	}
 
 	c.logger.Info("transaction uploaded to blockchain",
-		zap.String("tx_hash", receipt.TransactionHash),
+		zap.String("tx_hash", info.TxHash),
 		zap.String("biz_id", bizId),
-		zap.String("block_number", receipt.BlockNumber),
-		zap.String("gas_used", receipt.GasUsed))
+		zap.Int64("block_number", info.BlockNumber),
+		zap.Int64("gas_used", info.GasUsed))
 
-	return receipt.TransactionHash, receipt, nil
+	return info, nil
 }
 
+// ErrTransactionReverted 交易已打包但执行失败
+var ErrTransactionReverted = errors.New("transaction reverted")
+
 // BatchUploadTransactions 批量上传交易
 func (c *Client) BatchUploadTransactions(ctx context.Context, bizIds, dataHashes []string) (string, *types.Receipt, error) {
 	if c.contractHelper == nil {
@@ -173,10 +202,8 @@ func (c *Client) GetTransaction(ctx context.Context, bizId string) (*Transaction
 		return nil, fmt.Errorf("failed to pack getTransaction: %w", err)
 	}
 
-	// 调用合约 - 使用 FISCO SDK 的 CallContract
-	// 注意: FISCO SDK 可能使用不同的CallMsg结构
-	// 这里简化处理,直接调用
-	result, err := c.client.CallContract(ctx, c.contractAddr, input)
+	// 调用合约
+	result, err := c.callContract(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call getTransaction: %w", err)
 	}
@@ -197,6 +224,44 @@ func (c *Client) GetTransaction(ctx context.Context, bizId string) (*Transaction
 	}, nil
 }
 
+// TxExists 查询业务流水号在链上是否已有记录
+func (c *Client) TxExists(ctx context.Context, bizId string) (bool, error) {
+	if c.contractHelper == nil {
+		return false, fmt.Errorf("contract helper not initialized")
+	}
+
+	input, err := c.contractHelper.abi.Pack("txExists", BizIdToBytes32(bizId))
+	if err != nil {
+		return false, fmt.Errorf("failed to pack txExists: %w", err)
+	}
+
+	result, err := c.callContract(ctx, input)
+	if err != nil {
+		return false, fmt.Errorf("failed to call txExists: %w", err)
+	}
+
+	exists, err := c.contractHelper.DecodeTxExists(result)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode txExists result: %w", err)
+	}
+
+	return exists, nil
+}
+
+// callContract 以当前账户只读调用合约
+func (c *Client) callContract(ctx context.Context, input []byte) ([]byte, error) {
+	return c.client.CallContract(ctx, ethereum.CallMsg{
+		From: c.GetAccountAddress(),
+		To:   &c.contractAddr,
+		Data: input,
+	}, nil)
+}
+
+// GetAccountAddress 获取当前签名账户地址
+func (c *Client) GetAccountAddress() common.Address {
+	return c.client.GetCallOpts().From
+}
+
 // GetStatistics 获取统计信息
 func (c *Client) GetStatistics(ctx context.Context) (*StatisticsInfo, error) {
 	if c.contractHelper == nil {
@@ -210,7 +275,7 @@ func (c *Client) GetStatistics(ctx context.Context) (*StatisticsInfo, error) {
 	}
 
 	// 调用合约
-	result, err := c.client.CallContract(ctx, c.contractAddr, input)
+	result, err := c.callContract(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call getStatistics: %w", err)
 	}
@@ -255,37 +320,13 @@ func (c *Client) RegisterInstitution(ctx context.Context, name, address string)
 	return receipt.TransactionHash, receipt, nil
 }
 
-// sendTransaction 发送交易的内部方法
+// sendTransaction 发送交易并等待回执
 func (c *Client) sendTransaction(ctx context.Context, input []byte) (*types.Receipt, error) {
-	// 获取当前区块号
-	blockNumber, err := c.client.GetBlockNumber(ctx)
+	tx, _, err := c.signer.SignTransaction(ctx, c.contractAddr, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get block number: %w", err)
+		return nil, err
 	}
 
-	// 获取交易选项
-	auth := c.client.GetTransactOpts()
-
-	// 设置区块限制 (FISCO BCOS 特有)
-	// blockLimit = currentBlock + 500
-	blockLimit := big.NewInt(blockNumber + 500)
-
-	// 创建交易
-	// FISCO BCOS 交易格式
-	tx := types.NewTransaction(
-		auth.Nonce,
-		c.contractAddr,
-		big.NewInt(0),    // 金额为0
-		big.NewInt(300000), // Gas limit
-		auth.GasPrice,
-		big.NewInt(0),    // 附加的 blockLimit 参数位置
-		input,
-		blockLimit,
-		big.NewInt(1), // Chain ID
-		[]byte{},      // Extra data
-		false,         // Use SM2 crypto
-	)
-
 	// 发送交易
 	receipt, err := c.client.SendTransaction(ctx, tx)
 	if err != nil {
@@ -295,6 +336,100 @@ func (c *Client) sendTransaction(ctx context.Context, input []byte) (*types.Rece
 	return receipt, nil
 }
 
+// PreparedTransaction 已构造待提交的交易
+type PreparedTransaction struct {
+	TxHash     string // 交易哈希, 提交前即可确定
+	BlockLimit int64  // 超过该区块高度仍无回执时交易已失效
+
+	tx *types.Transaction
+}
+
+// PrepareUploadTransaction 构造上传交易, 不发送
+// 调用方先记录交易哈希再调用 SubmitTransaction, 提交后进程退出也能按哈希追踪回执
+func (c *Client) PrepareUploadTransaction(ctx context.Context, bizId, dataHash string) (*PreparedTransaction, error) {
+	if c.contractHelper == nil {
+		return nil, fmt.Errorf("contract helper not initialized")
+	}
+
+	// 编码合约调用数据
+	input, err := c.contractHelper.EncodeUploadTransaction(bizId, dataHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode uploadTransaction: %w", err)
+	}
+
+	tx, blockLimit, err := c.signer.SignTransaction(ctx, c.contractAddr, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedTransaction{
+		TxHash:     tx.Hash().Hex(),
+		BlockLimit: blockLimit,
+		tx:         tx,
+	}, nil
+}
+
+// SubmitTransaction 提交交易, 不等待回执; 回执通过 GetTransactionReceipt 查询
+func (c *Client) SubmitTransaction(ctx context.Context, prepared *PreparedTransaction) error {
+	handler := func(receipt *types.Receipt, err error) {
+		if err != nil {
+			c.logger.Debug("transaction receipt callback failed", zap.String("tx_hash", prepared.TxHash), zap.Error(err))
+		}
+	}
+
+	// HTTP 连接下 SDK 的异步发送会忽略提交错误并持续轮询回执, 改为后台同步发送并限定等待时间
+	if c.isHTTP {
+		go func() {
+			waitCtx, cancel := context.WithTimeout(context.Background(), receiptWaitTimeout)
+			defer cancel()
+			handler(c.client.SendTransaction(waitCtx, prepared.tx))
+		}()
+		return nil
+	}
+
+	if err := c.client.AsyncSendTransaction(ctx, prepared.tx, handler); err != nil {
+		return fmt.Errorf("failed to submit transaction: %w", err)
+	}
+	return nil
+}
+
+// receiptWaitTimeout HTTP 连接下后台等待回执的最长时间
+const receiptWaitTimeout = 5 * time.Minute
+
+// GetTransactionReceipt 查询交易回执, 交易尚未打包时返回 nil
+func (c *Client) GetTransactionReceipt(ctx context.Context, txHash string) (*ReceiptInfo, error) {
+	receipt, err := c.client.GetTransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		// SDK 对尚未打包的交易返回 "transaction ... is not on-chain"
+		if strings.Contains(err.Error(), "is not on-chain") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	return newReceiptInfo(receipt)
+}
+
+// newReceiptInfo 解析 SDK 回执中的十六进制字段
+func newReceiptInfo(receipt *types.Receipt) (*ReceiptInfo, error) {
+	blockNumber, err := parseChainInt(receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt block number %q: %w", receipt.BlockNumber, err)
+	}
+	gasUsed, _ := parseChainInt(receipt.GasUsed)
+
+	info := &ReceiptInfo{
+		TxHash:      receipt.TransactionHash,
+		BlockNumber: blockNumber,
+		BlockHash:   receipt.BlockHash,
+		GasUsed:     gasUsed,
+		Success:     receipt.Status == types.Success,
+	}
+	if !info.Success {
+		info.Message = receipt.GetErrorMessage()
+	}
+	return info, nil
+}
+
 // Close 关闭连接
 func (c *Client) Close() {
 	// FISCO BCOS Go SDK会自动管理连接
@@ -319,6 +454,16 @@ type TransactionInfo struct {
 	MatchHeight  *big.Int
 }
 
+// ReceiptInfo 交易回执信息
+type ReceiptInfo struct {
+	TxHash      string
+	BlockNumber int64
+	BlockHash   string
+	GasUsed     int64
+	Success     bool   // 交易是否执行成功
+	Message     string // 执行失败原因
+}
+
 // StatisticsInfo 统计信息
 type StatisticsInfo struct {
 	TotalTx          *big.Int
@@ -1,10 +1,11 @@
 package blockchain
 
 import (
+	"bytes"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -19,7 +20,7 @@ type ContractHelper struct {
 
 // NewContractHelper 创建合约辅助类
 func NewContractHelper(abiJSON string, contractAddr string) (*ContractHelper, error) {
-	parsedABI, err := abi.JSON(json.Unmarshaler{}) // 正确的方式
+	parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
@@ -94,7 +95,7 @@ func (h *ContractHelper) EncodeRegisterInstitution(name, address string) ([]byte
 // DecodeGetTransaction 解码 getTransaction 方法的返回值
 func (h *ContractHelper) DecodeGetTransaction(data []byte) (*TransactionResult, error) {
 	// 解码返回值: (bytes32, address, uint256, uint8, address, uint256)
-	results, err := h.abi.Methods["getTransaction"].Outputs.Unpack(data)
+	results, err := h.abi.Methods["getTransaction"].Outputs.UnpackValues(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unpack getTransaction: %w", err)
 	}
@@ -115,9 +116,23 @@ func (h *ContractHelper) DecodeGetTransaction(data []byte) (*TransactionResult,
 	return result, nil
 }
 
+// DecodeTxExists 解码 txExists 方法的返回值
+func (h *ContractHelper) DecodeTxExists(data []byte) (bool, error) {
+	results, err := h.abi.Methods["txExists"].Outputs.UnpackValues(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to unpack txExists: %w", err)
+	}
+
+	if len(results) != 1 {
+		return false, fmt.Errorf("unexpected number of return values: %d", len(results))
+	}
+
+	return results[0].(bool), nil
+}
+
 // DecodeGetStatistics 解码 getStatistics 方法的返回值
 func (h *ContractHelper) DecodeGetStatistics(data []byte) (*StatisticsResult, error) {
-	results, err := h.abi.Methods["getStatistics"].Outputs.Unpack(data)
+	results, err := h.abi.Methods["getStatistics"].Outputs.UnpackValues(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unpack getStatistics: %w", err)
 	}
@@ -185,6 +200,26 @@ func BizIdToBytes32(bizId string) [32]byte {
 	return stringToBytes32(bizId)
 }
 
+// Bytes32ToBizId 将链上的bytes32还原为业务流水号(去除末尾填充的零字节)
+func Bytes32ToBizId(b [32]byte) string {
+	return string(bytes.TrimRight(b[:], "\x00"))
+}
+
+// AnchorIDPrefix 锚定记录(如对账证明摘要)的链上ID前缀
+// 合约以 bizId 为键, 锚定记录与业务流水号共用命名空间; 业务流水号和组参考号不得使用该前缀,
+// 索引重建、一致性检查等按业务流水号处理链上记录的流程跳过带该前缀的ID
+const AnchorIDPrefix = "#"
+
+// AnchorID 生成锚定记录的链上ID
+func AnchorID(name string) string {
+	return AnchorIDPrefix + name
+}
+
+// IsAnchorID 判断链上ID是否为锚定记录
+func IsAnchorID(id string) bool {
+	return strings.HasPrefix(id, AnchorIDPrefix)
+}
+
 // ========== 数据结构 (解码结果) ==========
 
 // TransactionResult 解码后的交易结果
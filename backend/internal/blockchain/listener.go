@@ -14,10 +14,16 @@ type EventListener struct {
 	db     *gorm.DB
 	logger *zap.Logger
 
+	handlers    []TickHandler
+	broadcaster *Broadcaster
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// TickHandler 每个监听周期执行的处理函数
+type TickHandler func(ctx context.Context) error
+
 // NewEventListener 创建事件监听器
 func NewEventListener(client *Client, db *gorm.DB, logger *zap.Logger) *EventListener {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -25,12 +31,23 @@ func NewEventListener(client *Client, db *gorm.DB, logger *zap.Logger) *EventLis
 	return &EventListener{
 		client: client,
 		db:     db,
-		logger: logger,
-		ctx:    ctx,
-		cancel: cancel,
+		logger:      logger,
+		broadcaster: NewBroadcaster(logger),
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 }
 
+// OnTick 注册监听周期处理函数, 需在 Start 之前调用
+func (l *EventListener) OnTick(handler TickHandler) {
+	l.handlers = append(l.handlers, handler)
+}
+
+// Broadcaster 获取事件广播器, 用于仪表板实时推送
+func (l *EventListener) Broadcaster() *Broadcaster {
+	return l.broadcaster
+}
+
 // Start 启动事件监听
 func (l *EventListener) Start() {
 	l.logger.Info("event listener started")
@@ -44,13 +61,33 @@ func (l *EventListener) Start() {
 			l.logger.Info("event listener stopped")
 			return
 		case <-ticker.C:
-			// TODO: 实现事件监听逻辑
+			// TODO: 实现事件监听逻辑, 目前以轮询方式同步链上状态
 			l.logger.Debug("event listener tick")
+			l.pollBlockHeight()
+			for _, handler := range l.handlers {
+				if err := handler(l.ctx); err != nil {
+					l.logger.Error("event listener handler failed", zap.Error(err))
+				}
+			}
 		}
 	}
 }
 
+// pollBlockHeight 读取最新区块高度并推送给订阅者
+func (l *EventListener) pollBlockHeight() {
+	if l.client == nil {
+		return
+	}
+	height, err := l.client.GetBlockNumber(l.ctx)
+	if err != nil {
+		l.logger.Warn("failed to get block number", zap.Error(err))
+		return
+	}
+	l.broadcaster.PublishBlockHeight(height)
+}
+
 // Stop 停止监听
 func (l *EventListener) Stop() {
 	l.cancel()
+	l.broadcaster.Close()
 }
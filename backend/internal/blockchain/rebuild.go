@@ -0,0 +1,381 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+
+	"github.com/FISCO-BCOS/go-sdk/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rebuilder 按区块重放合约交易, 重建本地链索引(event_logs、chain_receipts、reconciliations)
+// 所有写入均以业务键 upsert, 可重复执行, 也可从任意高度续跑
+type Rebuilder struct {
+	client  *Client
+	db      *gorm.DB
+	logger  *zap.Logger
+	account string            // 本节点签名账户, 只有其发起的上链交易写入 chain_receipts
+	parties map[string]string // 小写地址 → 机构ID
+}
+
+// RebuildStats 重建统计
+type RebuildStats struct {
+	FromBlock       int64
+	ToBlock         int64
+	CurrentBlock    int64 // 最近处理完成的区块, 中断后从下一区块续跑
+	Transactions    int64 // 合约交易数
+	Events          int64 // 写入的合约事件数
+	Receipts        int64 // 写入的链上回执数
+	Reconciliations int64 // 写入的对账记录数
+}
+
+// RebuildProgress 进度回调, 每处理完一个区块调用一次
+type RebuildProgress func(stats *RebuildStats)
+
+// NewRebuilder 创建链索引重建器
+func NewRebuilder(client *Client, db *gorm.DB, logger *zap.Logger) *Rebuilder {
+	return &Rebuilder{
+		client: client,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Rebuild 重放 [from, to] 区间的区块, to <= 0 时处理到最新区块
+// 出错或 ctx 取消时返回已完成的进度
+func (r *Rebuilder) Rebuild(ctx context.Context, from, to int64, progress RebuildProgress) (*RebuildStats, error) {
+	if r.client.contractHelper == nil {
+		return nil, fmt.Errorf("contract helper not initialized")
+	}
+	if to <= 0 {
+		latest, err := r.client.GetBlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		to = latest
+	}
+	if from < 0 || from > to {
+		return nil, fmt.Errorf("invalid block range: %d-%d", from, to)
+	}
+	if err := r.loadParties(); err != nil {
+		return nil, err
+	}
+	r.account = r.client.GetAccountAddress().Hex()
+
+	stats := &RebuildStats{FromBlock: from, ToBlock: to, CurrentBlock: from - 1}
+	for n := from; n <= to; n++ {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		if err := r.rebuildBlock(ctx, n, stats); err != nil {
+			return stats, fmt.Errorf("block %d: %w", n, err)
+		}
+		stats.CurrentBlock = n
+		if progress != nil {
+			progress(stats)
+		}
+	}
+	return stats, nil
+}
+
+// loadParties 加载已登记机构的链上地址, 对账记录的双方以机构ID保存
+func (r *Rebuilder) loadParties() error {
+	var institutions []models.Institution
+	if err := r.db.Select("institution_id, address").Find(&institutions).Error; err != nil {
+		return fmt.Errorf("failed to list institutions: %w", err)
+	}
+	r.parties = make(map[string]string, len(institutions))
+	for _, inst := range institutions {
+		if inst.Address != "" {
+			r.parties[strings.ToLower(inst.Address)] = inst.InstitutionID
+		}
+	}
+	return nil
+}
+
+// party 地址对应的机构ID, 未登记时返回地址
+func (r *Rebuilder) party(address string) string {
+	if id, ok := r.parties[strings.ToLower(address)]; ok {
+		return id
+	}
+	return address
+}
+
+// rebuildBlock 读取区块内调用合约的交易回执, 在同一数据库事务中写入
+func (r *Rebuilder) rebuildBlock(ctx context.Context, number int64, stats *RebuildStats) error {
+	block, err := r.client.client.GetBlockByNumber(ctx, number, false)
+	if err != nil {
+		return fmt.Errorf("failed to get block: %w", err)
+	}
+
+	contract := r.client.contractAddr.Hex()
+	var receipts []*types.Receipt
+	for _, item := range block.Transactions {
+		hash, ok := item.(string)
+		if !ok {
+			continue
+		}
+		receipt, err := r.client.client.GetTransactionReceipt(ctx, common.HexToHash(hash))
+		if err != nil {
+			return fmt.Errorf("failed to get receipt %s: %w", hash, err)
+		}
+		if strings.EqualFold(receipt.To, contract) {
+			receipts = append(receipts, receipt)
+		}
+	}
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	ms, _ := parseChainInt(block.Timestamp)
+	blockTime := time.UnixMilli(ms)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, receipt := range receipts {
+			stats.Transactions++
+			if err := r.applyReceipt(tx, receipt, block, blockTime, stats); err != nil {
+				return fmt.Errorf("tx %s: %w", receipt.TransactionHash, err)
+			}
+		}
+		return nil
+	})
+}
+
+// applyReceipt 写入单笔合约交易的事件、回执及对账结果
+// 本节点上链失败的交易从调用参数中解析业务流水号, 记录失败回执
+// 锚定记录(链上ID带 AnchorIDPrefix)不是业务交易, 不写入事件、回执和对账结果
+func (r *Rebuilder) applyReceipt(tx *gorm.DB, receipt *types.Receipt, block *types.Block, blockTime time.Time, stats *RebuildStats) error {
+	height, _ := parseChainInt(receipt.BlockNumber)
+	gasUsed, _ := parseChainInt(receipt.GasUsed)
+	contract := r.client.contractAddr.Hex()
+	own := strings.EqualFold(receipt.From, r.account)
+
+	if receipt.Status != types.Success {
+		if !own {
+			return nil
+		}
+		for _, bizID := range r.decodeUploadInput(receipt.Input) {
+			if IsAnchorID(bizID) {
+				continue
+			}
+			if err := r.saveReceipt(tx, &models.ChainReceipt{
+				BizID:           bizID,
+				TxHash:          receipt.TransactionHash,
+				BlockHeight:     height,
+				BlockHash:       block.Hash,
+				ContractAddress: contract,
+				GasUsed:         gasUsed,
+				Status:          models.ChainReceiptStatusFailed,
+				CreatedAt:       blockTime,
+			}); err != nil {
+				return err
+			}
+			stats.Receipts++
+		}
+		return nil
+	}
+
+	var uploaded []string // 本笔交易上链的业务流水号, 按事件顺序
+	for i, log := range receipt.Logs {
+		if !strings.EqualFold(log.Address, contract) || len(log.Topics) == 0 {
+			continue
+		}
+		event, err := r.client.contractHelper.abi.EventByID(common.HexToHash(log.Topics[0]))
+		if err != nil {
+			continue // 不在ABI中的管理类事件
+		}
+		values, err := event.Inputs.NonIndexed().UnpackValues(common.FromHex(log.Data))
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.Name, err)
+		}
+
+		entry := &models.EventLog{
+			EventType:       event.Name,
+			TxHash:          receipt.TransactionHash,
+			LogIndex:        i,
+			BlockHeight:     height,
+			ContractAddress: contract,
+			Processed:       models.EventProcessed,
+			CreatedAt:       blockTime,
+		}
+		switch event.Name {
+		case models.EventTypeDataUploaded:
+			if len(log.Topics) < 3 || len(values) < 2 {
+				return fmt.Errorf("malformed %s log", event.Name)
+			}
+			dataHash := values[0].([32]byte)
+			entry.BizID = Bytes32ToBizId(common.HexToHash(log.Topics[1]))
+			if IsAnchorID(entry.BizID) {
+				continue // 锚定记录不是业务交易
+			}
+			entry.Data = models.EventData{
+				"data_hash": hex.EncodeToString(dataHash[:]),
+				"uploader":  common.HexToAddress(log.Topics[2]).Hex(),
+				"timestamp": values[1].(*big.Int).String(),
+			}
+			uploaded = append(uploaded, entry.BizID)
+
+		case models.EventTypeReconciliationEvent:
+			if len(log.Topics) < 4 || len(values) < 2 {
+				return fmt.Errorf("malformed %s log", event.Name)
+			}
+			status := int8(values[0].(uint8))
+			eventHeight := values[1].(*big.Int).Int64()
+			uploader := common.HexToAddress(log.Topics[2]).Hex()
+			counterparty := common.HexToAddress(log.Topics[3]).Hex()
+			entry.BizID = Bytes32ToBizId(common.HexToHash(log.Topics[1]))
+			if IsAnchorID(entry.BizID) {
+				continue
+			}
+			entry.Data = models.EventData{
+				"status":       status,
+				"uploader":     uploader,
+				"counterparty": counterparty,
+				"block_height": eventHeight,
+			}
+			uploaded = append(uploaded, entry.BizID)
+
+			saved, err := r.saveReconciliation(tx, entry.BizID, uploader, counterparty, status, eventHeight, blockTime)
+			if err != nil {
+				return err
+			}
+			if saved {
+				stats.Reconciliations++
+			}
+
+		case models.EventTypeInstitutionRegistered:
+			if len(log.Topics) < 2 || len(values) < 2 {
+				return fmt.Errorf("malformed %s log", event.Name)
+			}
+			entry.Data = models.EventData{
+				"institution_addr": common.HexToAddress(log.Topics[1]).Hex(),
+				"name":             values[0].(string),
+				"timestamp":        values[1].(*big.Int).String(),
+			}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tx_hash"}, {Name: "log_index"}},
+			DoUpdates: clause.AssignmentColumns([]string{"event_type", "biz_id", "block_height", "contract_address", "data", "processed", "created_at"}),
+		}).Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to save event log: %w", err)
+		}
+		stats.Events++
+	}
+
+	if !own {
+		return nil
+	}
+	for _, bizID := range uploaded {
+		if err := r.saveReceipt(tx, &models.ChainReceipt{
+			BizID:           bizID,
+			TxHash:          receipt.TransactionHash,
+			BlockHeight:     height,
+			BlockHash:       block.Hash,
+			ContractAddress: contract,
+			GasUsed:         gasUsed,
+			Status:          models.ChainReceiptStatusSuccess,
+			CreatedAt:       blockTime,
+		}); err != nil {
+			return err
+		}
+		stats.Receipts++
+	}
+	return nil
+}
+
+// saveReceipt 写入链上回执, 回执时间取区块时间(账龄从此起算)
+// 成功回执覆盖已有记录; 失败回执不覆盖已有记录, 避免重复上链被拒绝时覆盖此前的成功回执
+func (r *Rebuilder) saveReceipt(tx *gorm.DB, receipt *models.ChainReceipt) error {
+	columns := []string{"tx_hash", "block_height", "block_hash", "contract_address", "gas_used", "status", "created_at"}
+	conflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "biz_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}
+	if receipt.Status == models.ChainReceiptStatusFailed {
+		conflict = clause.OnConflict{Columns: []clause.Column{{Name: "biz_id"}}, DoNothing: true}
+	}
+	if err := tx.Model(&models.ChainReceipt{}).Clauses(conflict).Create(receipt.CreateValues()).Error; err != nil {
+		return fmt.Errorf("failed to save receipt: %w", err)
+	}
+	return nil
+}
+
+// saveReconciliation 写入链上对账结果, 已人工确认的容差匹配记录保持不变
+func (r *Rebuilder) saveReconciliation(tx *gorm.DB, bizID, uploader, counterparty string, status int8, height int64, at time.Time) (bool, error) {
+	var existing models.Reconciliation
+	err := tx.Select("status").Where("biz_id = ?", bizID).Take(&existing).Error
+	if err == nil && existing.Status == models.ReconciliationStatusAdjusted {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to get reconciliation: %w", err)
+	}
+
+	rec := &models.Reconciliation{
+		BizID:     bizID,
+		PartyA:    r.party(uploader),
+		PartyB:    r.party(counterparty),
+		Status:    status,
+		MatchedAt: &at,
+		CreatedAt: at,
+	}
+	if status == models.ReconciliationStatusMatched {
+		rec.BlockHeight = &height
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "biz_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"party_a", "party_b", "status", "matched_at", "block_height"}),
+	}).Create(rec).Error; err != nil {
+		return false, fmt.Errorf("failed to save reconciliation: %w", err)
+	}
+	return true, nil
+}
+
+// decodeUploadInput 从上链调用参数中解析业务流水号, 非上链调用返回空
+func (r *Rebuilder) decodeUploadInput(input string) []string {
+	data := common.FromHex(input)
+	if len(data) < 4 {
+		return nil
+	}
+	method, err := r.client.contractHelper.abi.MethodById(data[:4])
+	if err != nil {
+		return nil
+	}
+	values, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil || len(values) == 0 {
+		r.logger.Warn("failed to decode upload input", zap.String("method", method.Name), zap.Error(err))
+		return nil
+	}
+
+	switch method.Name {
+	case "uploadTransaction":
+		return []string{Bytes32ToBizId(values[0].([32]byte))}
+	case "batchUploadTransactions":
+		ids := values[0].([][32]byte)
+		bizIDs := make([]string, len(ids))
+		for i, id := range ids {
+			bizIDs[i] = Bytes32ToBizId(id)
+		}
+		return bizIDs
+	}
+	return nil
+}
+
+// parseChainInt 解析节点返回的数值, 支持 0x 前缀的十六进制和十进制
+func parseChainInt(s string) (int64, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return strconv.ParseInt(s[2:], 16, 64)
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
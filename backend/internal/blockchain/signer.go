@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/FISCO-BCOS/go-sdk/abi/bind"
+	"github.com/FISCO-BCOS/go-sdk/client"
+	"github.com/FISCO-BCOS/go-sdk/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxBlockLimitOffset 节点接受的区块限制上限为当前高度 + 1000
+const maxBlockLimitOffset = 1000
+
+// maxNonce FISCO BCOS 以随机 nonce 对交易去重, 取值范围与 SDK 一致 [0, 2^250)
+var maxNonce = new(big.Int).Lsh(big.NewInt(1), 250)
+
+// SignerOptions 交易签名参数
+type SignerOptions struct {
+	GasLimit         int64         // 单笔交易 Gas 上限
+	BlockLimitOffset int64         // 区块限制 = 当前区块高度 + 偏移量, 超过该高度仍未打包的交易失效
+	BlockCacheTTL    time.Duration // 区块高度缓存有效期
+}
+
+// withDefaults 补齐未配置的签名参数
+func (o SignerOptions) withDefaults() SignerOptions {
+	if o.GasLimit <= 0 {
+		o.GasLimit = 300000
+	}
+	if o.BlockLimitOffset <= 0 {
+		o.BlockLimitOffset = 500
+	}
+	// 缓存的高度落后于链上高度时区块限制相应缩短, 偏移量不能超过节点上限
+	if o.BlockLimitOffset > maxBlockLimitOffset {
+		o.BlockLimitOffset = maxBlockLimitOffset
+	}
+	if o.BlockCacheTTL <= 0 {
+		o.BlockCacheTTL = 5 * time.Second
+	}
+	return o
+}
+
+// Signer 交易签名器, 可被多个上链协程并发使用
+// 每笔交易使用独立的密码学随机 nonce; 区块高度按有效期缓存, 避免每笔交易查询一次节点
+type Signer struct {
+	client  *client.Client
+	auth    *bind.TransactOpts
+	options SignerOptions
+
+	mu          sync.Mutex
+	blockNumber int64
+	refreshedAt time.Time
+}
+
+// NewSigner 创建交易签名器, 使用 SDK 配置的账户私钥签名
+func NewSigner(c *client.Client, options SignerOptions) *Signer {
+	return &Signer{
+		client:  c,
+		auth:    c.GetTransactOpts(),
+		options: options.withDefaults(),
+	}
+}
+
+// SignTransaction 构造并签名调用合约的交易, 返回签名后的交易及其区块限制
+func (s *Signer) SignTransaction(ctx context.Context, to common.Address, input []byte) (*types.Transaction, int64, error) {
+	if s.auth == nil || s.auth.Signer == nil {
+		return nil, 0, errors.New("no signing account configured")
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	blockLimit, err := s.BlockLimit(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	chainID, err := s.client.GetChainID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get chain id: %w", err)
+	}
+
+	gasPrice := s.auth.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(30000000) // 与 SDK 默认值一致, FISCO BCOS 不按 Gas 计费
+	}
+
+	rawTx := types.NewTransaction(
+		nonce,
+		to,
+		big.NewInt(0), // 金额为0
+		big.NewInt(s.options.GasLimit),
+		gasPrice,
+		big.NewInt(blockLimit),
+		input,
+		chainID,
+		s.client.GetGroupID(),
+		[]byte{}, // Extra data
+		s.client.SMCrypto(),
+	)
+
+	signedTx, err := s.auth.Signer(types.HomesteadSigner{}, s.auth.From, rawTx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	return signedTx, blockLimit, nil
+}
+
+// newNonce 生成交易去重用的密码学随机 nonce
+func newNonce() (*big.Int, error) {
+	nonce, err := rand.Int(rand.Reader, maxNonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// BlockLimit 计算新交易的区块限制 = 当前区块高度 + 偏移量
+func (s *Signer) BlockLimit(ctx context.Context) (int64, error) {
+	blockNumber, err := s.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return blockNumber + s.options.BlockLimitOffset, nil
+}
+
+// BlockNumber 获取区块高度, 缓存未过期时不查询节点
+func (s *Signer) BlockNumber(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.refreshedAt.IsZero() && time.Since(s.refreshedAt) < s.options.BlockCacheTTL {
+		return s.blockNumber, nil
+	}
+
+	blockNumber, err := s.client.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	s.observe(blockNumber)
+	return blockNumber, nil
+}
+
+// ObserveBlockNumber 记录其他途径读取到的区块高度, 刷新缓存
+func (s *Signer) ObserveBlockNumber(blockNumber int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observe(blockNumber)
+}
+
+// observe 更新缓存的区块高度, 需持有锁; 高度只增不减
+func (s *Signer) observe(blockNumber int64) {
+	if blockNumber >= s.blockNumber {
+		s.blockNumber = blockNumber
+	}
+	s.refreshedAt = time.Now()
+}
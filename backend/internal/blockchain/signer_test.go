@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewNonce(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		nonce, err := newNonce()
+		if err != nil {
+			t.Fatalf("newNonce() error = %v", err)
+		}
+		if nonce.Sign() < 0 || nonce.Cmp(maxNonce) >= 0 {
+			t.Fatalf("newNonce() = %s, out of range [0, 2^250)", nonce)
+		}
+		if seen[nonce.String()] {
+			t.Fatalf("newNonce() returned duplicate %s", nonce)
+		}
+		seen[nonce.String()] = true
+	}
+}
+
+func TestSignerOptionsWithDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		options SignerOptions
+		want    SignerOptions
+	}{
+		{
+			name:    "defaults",
+			options: SignerOptions{},
+			want:    SignerOptions{GasLimit: 300000, BlockLimitOffset: 500, BlockCacheTTL: 5 * time.Second},
+		},
+		{
+			name:    "custom values kept",
+			options: SignerOptions{GasLimit: 100, BlockLimitOffset: 200, BlockCacheTTL: time.Second},
+			want:    SignerOptions{GasLimit: 100, BlockLimitOffset: 200, BlockCacheTTL: time.Second},
+		},
+		{
+			name:    "offset capped at node limit",
+			options: SignerOptions{BlockLimitOffset: 5000},
+			want:    SignerOptions{GasLimit: 300000, BlockLimitOffset: maxBlockLimitOffset, BlockCacheTTL: 5 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.withDefaults(); got != tt.want {
+				t.Errorf("withDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignerBlockLimit(t *testing.T) {
+	// 缓存有效期内不查询节点, 因此无需客户端
+	s := &Signer{options: SignerOptions{BlockLimitOffset: 100, BlockCacheTTL: time.Hour}.withDefaults()}
+
+	tests := []struct {
+		name     string
+		observed int64
+		want     int64
+	}{
+		{"offset added to cached height", 1000, 1100},
+		{"height advances", 1500, 1600},
+		{"stale height ignored", 1200, 1600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.ObserveBlockNumber(tt.observed)
+			got, err := s.BlockLimit(context.Background())
+			if err != nil {
+				t.Fatalf("BlockLimit() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BlockLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
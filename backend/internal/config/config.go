@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
@@ -12,6 +13,13 @@ type Config struct {
 	Database  DatabaseConfig   `mapstructure:"database"`
 	Blockchain BlockchainConfig `mapstructure:"blockchain"`
 	Fabric    *FabricConfig    `mapstructure:"fabric"` // Fabric配置(可选)
+	Security  SecurityConfig   `mapstructure:"security"`
+	Matching  MatchingConfig   `mapstructure:"matching"`
+	FX        FXConfig         `mapstructure:"fx"`
+	Aging     AgingConfig      `mapstructure:"aging"`
+	Webhook   WebhookConfig    `mapstructure:"webhook"`
+	Consistency ConsistencyConfig `mapstructure:"consistency"`
+	Mail      MailConfig       `mapstructure:"mail"`
 	Log       LogConfig        `mapstructure:"log"`
 }
 
@@ -19,6 +27,15 @@ type Config struct {
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+
+	// gRPC 接口, 与 REST API 使用不同端口
+	GRPCPort    int    `mapstructure:"grpc_port"`     // gRPC端口, 0 表示不启动
+	TLSCertFile string `mapstructure:"tls_cert_file"` // 服务端证书
+	TLSKeyFile  string `mapstructure:"tls_key_file"`  // 服务端私钥
+	ClientCA    string `mapstructure:"client_ca"`     // 签发机构客户端证书的CA, 用于双向TLS认证
+
+	// GRPCInsecureInstitution 仅限开发环境: 未配置证书时以明文启动gRPC, 所有调用视为该机构
+	GRPCInsecureInstitution string `mapstructure:"grpc_insecure_institution"`
 }
 
 // DatabaseConfig 数据库配置
@@ -45,6 +62,13 @@ type BlockchainConfig struct {
 	ContractAddress string `mapstructure:"contract_address"` // for FISCO
 	NetworkURL      string `mapstructure:"network_url"`      // for Ethereum-style
 	ChainID         int64  `mapstructure:"chain_id"`         // for Ethereum-style
+
+	ReceiptPollSeconds int `mapstructure:"receipt_poll_seconds"` // 已提交交易回执的轮询间隔(秒)
+
+	// FISCO 交易签名
+	GasLimit          int64 `mapstructure:"gas_limit"`           // 单笔交易 Gas 上限, 默认 300000
+	BlockLimitOffset  int64 `mapstructure:"block_limit_offset"`  // 区块限制 = 当前高度 + 偏移量, 默认 500, 最大 1000
+	BlockCacheSeconds int   `mapstructure:"block_cache_seconds"` // 区块高度缓存有效期(秒), 默认 5
 }
 
 // FabricConfig Fabric专属配置
@@ -56,6 +80,66 @@ type FabricConfig struct {
 	User        string `mapstructure:"user"`         // 用户名
 }
 
+// SecurityConfig 安全配置
+type SecurityConfig struct {
+	EncryptionKey string `mapstructure:"encryption_key"`  // AES加密密钥(32字节)
+	BlindIndexKey string `mapstructure:"blind_index_key"` // 金额盲索引主密钥(按机构派生)
+	SigningKey    string `mapstructure:"signing_key"`     // 机构签名私钥(secp256k1, hex), 用于签发对账证明
+}
+
+// MatchingConfig 容差匹配配置
+type MatchingConfig struct {
+	AmountTolerance     string  `mapstructure:"amount_tolerance"`      // 金额绝对容差, 如 "0.05"
+	AmountToleranceRate float64 `mapstructure:"amount_tolerance_rate"` // 金额相对容差, 如 0.001 表示千分之一
+	DateWindowDays      int     `mapstructure:"date_window_days"`      // 交易日期窗口(天)
+	RequireSameParties  bool    `mapstructure:"require_same_parties"`  // 是否要求付款方/收款方一致
+	MinScore            float64 `mapstructure:"min_score"`             // 候选最低得分(0-100)
+	MaxCandidates       int     `mapstructure:"max_candidates"`        // 每条记录返回的候选数量
+	PoolLimit           int     `mapstructure:"pool_limit"`            // 单次匹配加载的对手方记录上限
+}
+
+// FXConfig 汇率配置
+type FXConfig struct {
+	ReportingCurrency string `mapstructure:"reporting_currency"` // 统计折算的默认报告币种
+	PivotCurrency     string `mapstructure:"pivot_currency"`     // 交叉汇率的中间币种
+	MaxRateAgeDays    int    `mapstructure:"max_rate_age_days"`  // 汇率最长有效天数, 超过视为缺失
+}
+
+// AgingConfig 单边上链账龄配置
+type AgingConfig struct {
+	SLADays              int   `mapstructure:"sla_days"`               // 上链后超过该天数对手方仍未上链视为逾期
+	Buckets              []int `mapstructure:"buckets"`                // 账龄分段边界(天), 如 [1, 3, 7, 14, 30]
+	AlertThreshold       int   `mapstructure:"alert_threshold"`        // 单个对手方逾期笔数达到该值时告警
+	CheckIntervalMinutes int   `mapstructure:"check_interval_minutes"` // 告警检查间隔(分钟), 0 表示不启动后台检查
+}
+
+// WebhookConfig Webhook推送配置
+type WebhookConfig struct {
+	TimeoutSeconds       int `mapstructure:"timeout_seconds"`        // 单次推送超时(秒)
+	MaxAttempts          int `mapstructure:"max_attempts"`           // 最大尝试次数(含首次推送)
+	RetryBaseSeconds     int `mapstructure:"retry_base_seconds"`     // 首次重试间隔(秒), 之后按2倍递增
+	RetryMaxSeconds      int `mapstructure:"retry_max_seconds"`      // 重试间隔上限(秒)
+	RetryIntervalSeconds int `mapstructure:"retry_interval_seconds"` // 扫描待重试推送的间隔(秒)
+}
+
+// ConsistencyConfig 数据库与链上状态一致性检查配置
+type ConsistencyConfig struct {
+	IntervalMinutes int  `mapstructure:"interval_minutes"` // 定时检查间隔(分钟), 0 表示不启动后台检查
+	AutoHeal        bool `mapstructure:"auto_heal"`        // 定时检查是否按链上状态修复本地状态
+	BatchSize       int  `mapstructure:"batch_size"`       // 每批读取的交易数
+}
+
+// MailConfig 邮件通知配置
+type MailConfig struct {
+	Host            string `mapstructure:"host"`             // SMTP服务器, 为空时不发送邮件
+	Port            int    `mapstructure:"port"`             // SMTP端口
+	Username        string `mapstructure:"username"`         // SMTP用户名, 为空时不认证
+	Password        string `mapstructure:"password"`         // SMTP密码
+	From            string `mapstructure:"from"`             // 发件人地址
+	DefaultLanguage string `mapstructure:"default_language"` // 用户未设置时的邮件语言(zh/en)
+	DigestHour      int    `mapstructure:"digest_hour"`      // 每日摘要发送时间(0-23点), 负数表示不发送
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
@@ -81,9 +165,32 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := config.Security.validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// blindIndexKeyPlaceholder 示例配置中的盲索引密钥占位值, 不得用于运行
+const blindIndexKeyPlaceholder = "change-me-blind-index-master-key"
+
+// minBlindIndexKeyLength 盲索引主密钥最小长度(字节)
+const minBlindIndexKeyLength = 16
+
+// validate 校验安全配置, 盲索引密钥为空或仍为占位值时金额索引可被任意推算
+func (c *SecurityConfig) validate() error {
+	switch {
+	case c.BlindIndexKey == "":
+		return fmt.Errorf("security.blind_index_key is required")
+	case c.BlindIndexKey == blindIndexKeyPlaceholder:
+		return fmt.Errorf("security.blind_index_key must be changed from the example value")
+	case len(c.BlindIndexKey) < minBlindIndexKeyLength:
+		return fmt.Errorf("security.blind_index_key must be at least %d bytes", minBlindIndexKeyLength)
+	}
+	return nil
+}
+
 // GetBlockchainType 获取区块链类型
 func (c *Config) GetBlockchainType() string {
 	if c.Blockchain.Type != "" {
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrateBatchSize 数据迁移每批处理的行数
+const migrateBatchSize = 500
+
+// MigrateAmountBlindIndex 将 amount_hash 从无盐SHA256重写为机构级HMAC盲索引
+// 逐批解密 amount_cipher 后重新计算, 可重复执行(结果幂等)
+func MigrateAmountBlindIndex(db *gorm.DB, encryptionKey, blindIndexKey string, logger *zap.Logger) (int, error) {
+	if blindIndexKey == "" {
+		return 0, fmt.Errorf("blind index key is empty")
+	}
+
+	keys := make(map[string][]byte)
+	migrated := 0
+	var txs []models.Transaction
+
+	result := db.Select("id", "institution_id", "amount_cipher", "amount_hash").
+		FindInBatches(&txs, migrateBatchSize, func(batch *gorm.DB, _ int) error {
+			for _, tx := range txs {
+				amount, err := utils.DecryptAmount(encryptionKey, tx.AmountCipher)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt amount of transaction %d: %w", tx.ID, err)
+				}
+
+				key, ok := keys[tx.InstitutionID]
+				if !ok {
+					key = utils.DeriveInstitutionKey(blindIndexKey, tx.InstitutionID)
+					keys[tx.InstitutionID] = key
+				}
+
+				index := utils.AmountBlindIndex(key, amount)
+				if index == tx.AmountHash {
+					continue
+				}
+
+				if err := batch.Model(&models.Transaction{}).
+					Where("id = ?", tx.ID).
+					UpdateColumn("amount_hash", index).Error; err != nil {
+					return fmt.Errorf("failed to update transaction %d: %w", tx.ID, err)
+				}
+				migrated++
+			}
+
+			logger.Info("amount blind index batch migrated",
+				zap.Int("batch_size", len(txs)),
+				zap.Int("migrated", migrated))
+			return nil
+		})
+	if result.Error != nil {
+		return migrated, result.Error
+	}
+
+	return migrated, nil
+}
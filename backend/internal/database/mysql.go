@@ -55,6 +55,20 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Reconciliation{},
 		&models.EventLog{},
 		&models.User{},
+		&models.ImportProfile{},
+		&models.ReconciliationCertificate{},
+		&models.ImportBatch{},
+		&models.ReconciliationGroup{},
+		&models.ReconciliationGroupItem{},
+		&models.MatchAdjustment{},
+		&models.MismatchDiagnosis{},
+		&models.FXRate{},
+		&models.AgingAlert{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.NotificationPreference{},
+		&models.ConsistencyRun{},
+		&models.ConsistencyDrift{},
 	)
 }
 
@@ -0,0 +1,293 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	reconciliationv1 "bc-reconciliation-backend/api/proto/reconciliation/v1"
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// reconciliationServer 对账服务实现, 与 REST 接口共用 TransactionService
+type reconciliationServer struct {
+	reconciliationv1.UnimplementedReconciliationServiceServer
+
+	txService           *service.TransactionService
+	broadcaster         *blockchain.Broadcaster
+	contractAddress     string
+	insecureInstitution string // 开发环境明文启动时所有调用使用的机构ID
+	logger              *zap.Logger
+}
+
+// CreateTransaction 创建单笔交易
+func (s *reconciliationServer) CreateTransaction(ctx context.Context, req *reconciliationv1.CreateTransactionRequest) (*reconciliationv1.CreateTransactionResponse, error) {
+	institution, err := s.institutionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+
+	result, err := s.txService.CreateTransaction(toCreateRequest(req, institution), institution)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toCreateResponse(result), nil
+}
+
+// IngestTransactions 流式批量创建交易
+// 单条记录校验失败或重复不会中断流, 在汇总结果中返回
+func (s *reconciliationServer) IngestTransactions(stream reconciliationv1.ReconciliationService_IngestTransactionsServer) error {
+	institution, err := s.institutionID(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := &reconciliationv1.IngestTransactionsResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		resp.Total++
+
+		if err := validateCreateRequest(req); err != nil {
+			resp.Failed++
+			resp.Failures = append(resp.Failures, &reconciliationv1.CreateTransactionResponse{
+				BizId:   req.BizId,
+				Message: status.Convert(err).Message(),
+			})
+			continue
+		}
+
+		result, err := s.txService.CreateTransaction(toCreateRequest(req, institution), institution)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if !result.Success {
+			resp.Failed++
+			resp.Failures = append(resp.Failures, toCreateResponse(result))
+			continue
+		}
+		resp.Created++
+	}
+
+	s.logger.Info("grpc ingest completed",
+		zap.String("institution", institution),
+		zap.Int32("total", resp.Total),
+		zap.Int32("created", resp.Created))
+
+	return stream.SendAndClose(resp)
+}
+
+// UploadToChain 上链, 仅允许上传本机构的交易
+func (s *reconciliationServer) UploadToChain(ctx context.Context, req *reconciliationv1.UploadToChainRequest) (*reconciliationv1.UploadToChainResponse, error) {
+	institution, err := s.institutionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.BizIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "biz_ids 不能为空")
+	}
+
+	resp := &reconciliationv1.UploadToChainResponse{Total: int32(len(req.BizIds))}
+	owned := make([]string, 0, len(req.BizIds))
+	for _, bizID := range req.BizIds {
+		tx, err := s.txService.GetTransaction(bizID)
+		if err != nil || tx.InstitutionID != institution {
+			resp.Failed++
+			resp.FailedIds = append(resp.FailedIds, bizID)
+			continue
+		}
+		owned = append(owned, bizID)
+	}
+
+	if len(owned) > 0 {
+		result := s.txService.BatchUploadToChain(ctx, owned, s.contractAddress)
+		resp.Success = int32(result.Success)
+		resp.Failed += int32(result.Failed)
+		resp.SuccessIds = result.SuccessIDs
+		resp.FailedIds = append(resp.FailedIds, result.FailedIDs...)
+	}
+	return resp, nil
+}
+
+// GetTransaction 查询交易状态, 其他机构的交易视为不存在
+func (s *reconciliationServer) GetTransaction(ctx context.Context, req *reconciliationv1.GetTransactionRequest) (*reconciliationv1.Transaction, error) {
+	institution, err := s.institutionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BizId == "" {
+		return nil, status.Error(codes.InvalidArgument, "biz_id 不能为空")
+	}
+
+	tx, err := s.txService.GetTransaction(req.BizId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "交易不存在")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if tx.InstitutionID != institution {
+		return nil, status.Error(codes.NotFound, "交易不存在")
+	}
+	return toTransaction(tx), nil
+}
+
+// SubscribeEvents 订阅本机构事件, 连接建立时先推送当前区块高度
+// 服务关闭时以 Unavailable 结束, 客户端应重新订阅
+func (s *reconciliationServer) SubscribeEvents(req *reconciliationv1.SubscribeEventsRequest, stream reconciliationv1.ReconciliationService_SubscribeEventsServer) error {
+	institution, err := s.institutionID(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	types := make(map[string]bool, len(req.Types))
+	for _, t := range req.Types {
+		switch t {
+		case models.StreamEventBlock, models.StreamEventStatus, models.StreamEventStatistics:
+			types[t] = true
+		default:
+			return status.Errorf(codes.InvalidArgument, "不支持的事件类型: %s", t)
+		}
+	}
+	wanted := func(t string) bool {
+		return len(types) == 0 || types[t]
+	}
+
+	events, unsubscribe := s.broadcaster.Subscribe(institution)
+	defer unsubscribe()
+
+	if wanted(models.StreamEventBlock) {
+		if err := stream.Send(toEvent(&models.StreamEvent{
+			Type: models.StreamEventBlock,
+			Data: &models.BlockEventData{BlockHeight: s.broadcaster.BlockHeight()},
+		})); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "服务正在关闭")
+			}
+			if !wanted(event.Type) {
+				continue
+			}
+			if err := stream.Send(toEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// validateCreateRequest 校验必填字段, 与 REST 接口的 binding 规则一致
+func validateCreateRequest(req *reconciliationv1.CreateTransactionRequest) error {
+	switch {
+	case req.BizId == "":
+		return status.Error(codes.InvalidArgument, "biz_id 不能为空")
+	case req.Amount == "":
+		return status.Error(codes.InvalidArgument, "amount 不能为空")
+	case req.Receiver == "":
+		return status.Error(codes.InvalidArgument, "receiver 不能为空")
+	case req.Sender == "":
+		return status.Error(codes.InvalidArgument, "sender 不能为空")
+	}
+	return nil
+}
+
+// toCreateRequest 转换为服务层请求
+func toCreateRequest(req *reconciliationv1.CreateTransactionRequest, institution string) *models.CreateTransactionRequest {
+	return &models.CreateTransactionRequest{
+		BizID:         req.BizId,
+		InstitutionID: institution,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Receiver:      req.Receiver,
+		Sender:        req.Sender,
+		TxType:        int8(req.TxType),
+		TxDate:        req.TxDate,
+	}
+}
+
+// toCreateResponse 转换创建结果
+func toCreateResponse(result *service.CreateTransactionResult) *reconciliationv1.CreateTransactionResponse {
+	return &reconciliationv1.CreateTransactionResponse{
+		Success: result.Success,
+		BizId:   result.BizID,
+		Message: result.Message,
+	}
+}
+
+// toTransaction 转换交易
+func toTransaction(tx *models.TransactionResponse) *reconciliationv1.Transaction {
+	out := &reconciliationv1.Transaction{
+		Id:            uint64(tx.ID),
+		BizId:         tx.BizID,
+		InstitutionId: tx.InstitutionID,
+		Currency:      tx.Currency,
+		Receiver:      tx.Receiver,
+		Sender:        tx.Sender,
+		TxType:        int32(tx.TxType),
+		Status:        int32(tx.Status),
+		StatusText:    tx.StatusText,
+		CreatedAt:     timestamppb.New(tx.CreatedAt),
+		UpdatedAt:     timestamppb.New(tx.UpdatedAt),
+	}
+	if tx.TxDate != nil {
+		out.TxDate = tx.TxDate.Format("2006-01-02")
+	}
+	return out
+}
+
+// toEvent 转换推送事件
+func toEvent(event *models.StreamEvent) *reconciliationv1.Event {
+	out := &reconciliationv1.Event{
+		Type: event.Type,
+		Time: timestamppb.New(event.Time),
+	}
+	if event.Time.IsZero() {
+		out.Time = timestamppb.Now()
+	}
+
+	switch data := event.Data.(type) {
+	case *models.BlockEventData:
+		out.Data = &reconciliationv1.Event_Block{Block: &reconciliationv1.BlockEvent{
+			BlockHeight: data.BlockHeight,
+		}}
+	case *models.StatusEventData:
+		out.Data = &reconciliationv1.Event_Status{Status: &reconciliationv1.StatusEvent{
+			BizId:          data.BizID,
+			PreviousStatus: int32(data.PreviousStatus),
+			Status:         int32(data.Status),
+			StatusText:     data.StatusText,
+		}}
+	case *models.StatisticsDelta:
+		// gRPC 统计消息没有已提交计数, 计入待上链
+		out.Data = &reconciliationv1.Event_Statistics{Statistics: &reconciliationv1.StatisticsDelta{
+			PendingCount:  data.PendingCount + data.SubmittedCount,
+			UploadedCount: data.UploadedCount,
+			MatchedCount:  data.MatchedCount,
+			MismatchCount: data.MismatchCount,
+		}}
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	reconciliationv1 "bc-reconciliation-backend/api/proto/reconciliation/v1"
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/service"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Options gRPC服务配置
+type Options struct {
+	TLSCertFile     string // 服务端证书
+	TLSKeyFile      string // 服务端私钥
+	ClientCA        string // 签发机构客户端证书的CA
+	ContractAddress string // 上链使用的合约地址
+
+	// InsecureInstitutionID 仅限开发环境: 未配置证书时以明文启动, 所有调用视为该机构; 为空时未配置证书拒绝启动
+	InsecureInstitutionID string
+}
+
+// NewServer 创建gRPC服务并注册对账服务
+// 要求客户端出示由 ClientCA 签发的证书(双向TLS), 证书 CommonName 即机构ID
+func NewServer(txService *service.TransactionService, broadcaster *blockchain.Broadcaster, options Options, logger *zap.Logger) (*grpc.Server, error) {
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryLogger(logger)),
+		grpc.ChainStreamInterceptor(streamLogger(logger)),
+	}
+
+	insecureInstitution := ""
+	switch {
+	case options.TLSCertFile != "":
+		creds, err := loadTLSCredentials(options.TLSCertFile, options.TLSKeyFile, options.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	case options.InsecureInstitutionID != "":
+		insecureInstitution = options.InsecureInstitutionID
+		logger.Warn("grpc server started without tls for development, all calls act as " + insecureInstitution)
+	default:
+		return nil, errors.New("grpc server requires tls, configure a certificate or an insecure development institution")
+	}
+
+	srv := grpc.NewServer(serverOpts...)
+	reconciliationv1.RegisterReconciliationServiceServer(srv, &reconciliationServer{
+		txService:           txService,
+		broadcaster:         broadcaster,
+		contractAddress:     options.ContractAddress,
+		insecureInstitution: insecureInstitution,
+		logger:              logger,
+	})
+	return srv, nil
+}
+
+// loadTLSCredentials 加载双向TLS证书
+func loadTLSCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load grpc server certificate: %w", err)
+	}
+
+	if clientCAFile == "" {
+		return nil, errors.New("client ca is required for mutual tls")
+	}
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client ca: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no valid certificate found in client ca")
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// institutionID 从客户端证书获取机构ID
+// 未使用TLS的连接一律拒绝, 仅开发环境明文启动时使用配置的机构ID
+func (s *reconciliationServer) institutionID(ctx context.Context) (string, error) {
+	if s.insecureInstitution != "" {
+		return s.insecureInstitution, nil
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "无法识别客户端")
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "连接未使用TLS")
+	}
+	chains := tlsInfo.State.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 || chains[0][0].Subject.CommonName == "" {
+		return "", status.Error(codes.Unauthenticated, "客户端证书未包含机构ID")
+	}
+	return chains[0][0].Subject.CommonName, nil
+}
+
+// unaryLogger 记录一元调用日志
+func unaryLogger(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// streamLogger 记录流式调用日志
+func streamLogger(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// logCall 记录调用结果
+func logCall(ctx context.Context, logger *zap.Logger, method string, start time.Time, err error) {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	logger.Info("gRPC Request",
+		zap.String("code", status.Code(err).String()),
+		zap.String("method", method),
+		zap.String("peer", addr),
+		zap.Duration("latency", time.Since(start)),
+	)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AgingHandler 单边上链账龄处理器
+type AgingHandler struct {
+	agingService *service.AgingService
+}
+
+// NewAgingHandler 创建账龄处理器
+func NewAgingHandler(agingService *service.AgingService) *AgingHandler {
+	return &AgingHandler{
+		agingService: agingService,
+	}
+}
+
+// asOfParam 解析统计时点参数, 默认当前时间
+func asOfParam(c *gin.Context) (time.Time, error) {
+	value := c.Query("as_of")
+	if value == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 仅给出日期时按当日结束统计
+	return d.Add(24*time.Hour - time.Second), nil
+}
+
+// GetReport 获取账龄报表
+// @Summary 获取单边上链账龄报表
+// @Description 统计本方已上链、对手方尚未上链的交易, 按对手方和账龄分段汇总; 账龄从链上回执时间起算, 超过 SLA 天数计为逾期
+// @Tags aging
+// @Produce json
+// @Param counterparty query string false "对手方"
+// @Param as_of query string false "统计时点(YYYY-MM-DD 或 YYYY-MM-DD HH:MM:SS), 默认当前时间"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/aging/report [get]
+func (h *AgingHandler) GetReport(c *gin.Context) {
+	asOf, err := asOfParam(c)
+	if err != nil {
+		utils.BadRequest(c, "统计时点格式错误")
+		return
+	}
+
+	report, err := h.agingService.Report(currentInstitutionID(c), c.Query("counterparty"), asOf)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, report)
+}
+
+// ListItems 查询单边上链明细
+// @Summary 查询单边上链明细
+// @Description 按上链时间升序(账龄最长的在前)列出单边上链交易
+// @Tags aging
+// @Produce json
+// @Param counterparty query string false "对手方"
+// @Param overdue query bool false "仅返回逾期记录"
+// @Param as_of query string false "统计时点, 默认当前时间"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/aging/items [get]
+func (h *AgingHandler) ListItems(c *gin.Context) {
+	page, size := pageParams(c)
+
+	asOf, err := asOfParam(c)
+	if err != nil {
+		utils.BadRequest(c, "统计时点格式错误")
+		return
+	}
+	overdueOnly, _ := strconv.ParseBool(c.DefaultQuery("overdue", "false"))
+
+	result, err := h.agingService.ListItems(currentInstitutionID(c), c.Query("counterparty"), overdueOnly, asOf, page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// ListAlerts 查询逾期告警
+// @Summary 查询逾期告警
+// @Tags aging
+// @Produce json
+// @Param status query int false "状态(0-告警中 1-已确认 2-已解除)"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/aging/alerts [get]
+func (h *AgingHandler) ListAlerts(c *gin.Context) {
+	page, size := pageParams(c)
+
+	var status *int8
+	if value := c.Query("status"); value != "" {
+		s, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			utils.BadRequest(c, "状态参数错误")
+			return
+		}
+		v := int8(s)
+		status = &v
+	}
+
+	result, err := h.agingService.ListAlerts(currentInstitutionID(c), status, page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// AcknowledgeAlert 确认逾期告警
+// @Summary 确认逾期告警
+// @Description 确认后告警保持已确认状态, 逾期笔数回落到阈值以下时自动解除
+// @Tags aging
+// @Produce json
+// @Param id path int true "告警ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/aging/alerts/{id}/ack [post]
+func (h *AgingHandler) AcknowledgeAlert(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "告警ID错误")
+		return
+	}
+
+	alert, err := h.agingService.AcknowledgeAlert(currentInstitutionID(c), uint(id), currentUsername(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "告警已确认", alert)
+}
+
+// CheckAlerts 立即执行告警检查
+// @Summary 立即执行告警检查
+// @Description 不等待后台定时任务, 立即按当前数据维护各机构的逾期告警, 返回本次新建的告警
+// @Tags aging
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/v1/aging/check [post]
+func (h *AgingHandler) CheckAlerts(c *gin.Context) {
+	raised, err := h.agingService.CheckAlerts(time.Now())
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, raised)
+}
+
+// handleError 统一处理账龄服务错误
+func (h *AgingHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAgingAlertNotFound):
+		utils.NotFound(c, "告警不存在")
+	case errors.Is(err, service.ErrAgingAlertResolved):
+		utils.BadRequest(c, "告警已解除")
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CertificateHandler 对账证明处理器
+type CertificateHandler struct {
+	certService *service.CertificateService
+}
+
+// NewCertificateHandler 创建对账证明处理器
+func NewCertificateHandler(certService *service.CertificateService) *CertificateHandler {
+	return &CertificateHandler{
+		certService: certService,
+	}
+}
+
+// CreateCertificate 出具对账证明
+// @Summary 出具对账证明
+// @Description 汇总期间内与对手机构对账成功的交易, 机构私钥签名后将摘要锚定上链
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCertificateRequest true "证明请求"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/certificates [post]
+func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
+	var req models.CreateCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	cert, err := h.certService.CreateCertificate(c.Request.Context(), currentInstitutionID(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoMatchedTransactions):
+			utils.BadRequest(c, "该期间内无与对手机构对账成功的交易")
+		case errors.Is(err, service.ErrSigningKeyMissing):
+			utils.ServerError(c, "未配置机构签名私钥")
+		default:
+			utils.BadRequest(c, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "证明出具成功", cert)
+}
+
+// ListCertificates 查询对账证明列表
+// @Summary 查询对账证明列表
+// @Description 查询当前机构出具的对账证明
+// @Tags certificates
+// @Produce json
+// @Param counterparty query string false "对手机构ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/certificates [get]
+func (h *CertificateHandler) ListCertificates(c *gin.Context) {
+	page, size := pageParams(c)
+
+	result, err := h.certService.ListCertificates(currentInstitutionID(c), c.Query("counterparty"), page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// GetCertificate 查询对账证明详情
+// @Summary 查询对账证明详情
+// @Description 查询证明内容、摘要、签名和锚定信息
+// @Tags certificates
+// @Produce json
+// @Param certNo path string true "证明编号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/certificates/{certNo} [get]
+func (h *CertificateHandler) GetCertificate(c *gin.Context) {
+	cert, err := h.certService.GetCertificate(currentInstitutionID(c), c.Param("certNo"))
+	if err != nil {
+		if errors.Is(err, service.ErrCertificateNotFound) {
+			utils.NotFound(c, "证明不存在")
+			return
+		}
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, cert)
+}
+
+// DownloadCertificate 下载对账证明文档
+// @Summary 下载对账证明文档
+// @Description 在本地渲染HTML或PDF格式的证明文档
+// @Tags certificates
+// @Produce octet-stream
+// @Param certNo path string true "证明编号"
+// @Param format query string false "文档格式: html, pdf" default(html)
+// @Success 200 {file} file
+// @Router /api/v1/certificates/{certNo}/document [get]
+func (h *CertificateHandler) DownloadCertificate(c *gin.Context) {
+	format := c.DefaultQuery("format", service.CertificateFormatHTML)
+	if format != service.CertificateFormatHTML && format != service.CertificateFormatPDF {
+		utils.BadRequest(c, "文档格式仅支持 html 或 pdf")
+		return
+	}
+
+	certNo := c.Param("certNo")
+	data, err := h.certService.RenderCertificate(currentInstitutionID(c), certNo, format)
+	if err != nil {
+		if errors.Is(err, service.ErrCertificateNotFound) {
+			utils.NotFound(c, "证明不存在")
+			return
+		}
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if format == service.CertificateFormatPDF {
+		contentType = "application/pdf"
+	}
+	c.Header("Content-Disposition", "attachment; filename="+certNo+"."+format)
+	c.Data(200, contentType, data)
+}
+
+// AnchorCertificate 重新锚定对账证明
+// @Summary 重新锚定对账证明
+// @Description 对锚定失败的证明重新上链
+// @Tags certificates
+// @Produce json
+// @Param certNo path string true "证明编号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/certificates/{certNo}/anchor [post]
+func (h *CertificateHandler) AnchorCertificate(c *gin.Context) {
+	cert, err := h.certService.AnchorCertificate(c.Request.Context(), currentInstitutionID(c), c.Param("certNo"))
+	if err != nil {
+		if errors.Is(err, service.ErrCertificateNotFound) {
+			utils.NotFound(c, "证明不存在")
+			return
+		}
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, cert)
+}
+
+// VerifyCertificate 校验对账证明
+// @Summary 校验对账证明
+// @Description 校验证明摘要、机构签名及链上锚定记录, 审计方可提供所持文档上的摘要进行比对
+// @Tags certificates
+// @Produce json
+// @Param certNo path string true "证明编号"
+// @Param digest query string false "证明文档上的摘要"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/certificates/{certNo}/verify [get]
+func (h *CertificateHandler) VerifyCertificate(c *gin.Context) {
+	result, err := h.certService.VerifyCertificate(c.Request.Context(), c.Param("certNo"), c.Query("digest"))
+	if err != nil {
+		if errors.Is(err, service.ErrCertificateNotFound) {
+			utils.NotFound(c, "证明不存在")
+			return
+		}
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
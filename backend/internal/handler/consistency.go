@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsistencyHandler 链上一致性检查处理器
+type ConsistencyHandler struct {
+	consistencyService *service.ConsistencyService
+}
+
+// NewConsistencyHandler 创建一致性检查处理器
+func NewConsistencyHandler(consistencyService *service.ConsistencyService) *ConsistencyHandler {
+	return &ConsistencyHandler{
+		consistencyService: consistencyService,
+	}
+}
+
+// StartRun 发起一致性检查
+// @Summary 发起链上一致性检查
+// @Description 按业务流水号范围(含两端, 为空不限)逐笔比对本地交易与合约记录的数据哈希和状态, 检查在后台执行; auto_heal 为 true 时按链上状态修复本地状态, 未指定时使用配置
+// @Tags consistency
+// @Accept json
+// @Produce json
+// @Param request body models.StartConsistencyCheckRequest false "检查范围"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/consistency/runs [post]
+func (h *ConsistencyHandler) StartRun(c *gin.Context) {
+	var req models.StartConsistencyCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	run, err := h.consistencyService.StartCheck(&req, currentUsername(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "一致性检查已开始", run)
+}
+
+// ListRuns 查询检查记录
+// @Summary 查询链上一致性检查记录
+// @Tags consistency
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/consistency/runs [get]
+func (h *ConsistencyHandler) ListRuns(c *gin.Context) {
+	page, size := pageParams(c)
+
+	result, err := h.consistencyService.ListRuns(page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// GetRun 查询检查记录详情
+// @Summary 查询链上一致性检查详情
+// @Description 检查进行中时计数按批次更新
+// @Tags consistency
+// @Produce json
+// @Param id path int true "检查记录ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/consistency/runs/{id} [get]
+func (h *ConsistencyHandler) GetRun(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "检查记录ID错误")
+		return
+	}
+
+	run, err := h.consistencyService.GetRun(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, run)
+}
+
+// ListDrifts 查询偏差明细
+// @Summary 查询链上一致性偏差明细
+// @Tags consistency
+// @Produce json
+// @Param id path int true "检查记录ID"
+// @Param drift_type query string false "偏差类型(missing_on_chain/hash_mismatch/status_mismatch)"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/consistency/runs/{id}/drifts [get]
+func (h *ConsistencyHandler) ListDrifts(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "检查记录ID错误")
+		return
+	}
+	page, size := pageParams(c)
+
+	result, err := h.consistencyService.ListDrifts(id, c.Query("drift_type"), page, size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// handleError 统一处理一致性检查服务错误
+func (h *ConsistencyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrConsistencyRunNotFound):
+		utils.NotFound(c, "检查记录不存在")
+	case errors.Is(err, service.ErrConsistencyCheckRunning):
+		utils.BadRequest(c, "已有一致性检查在进行中, 请稍后再试")
+	case errors.Is(err, service.ErrInvalidBizIDRange):
+		utils.BadRequest(c, "业务流水号范围无效: 起始值不能大于结束值")
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
 	"bc-reconciliation-backend/internal/service"
 	"bc-reconciliation-backend/internal/utils"
 
@@ -9,25 +15,36 @@ import (
 
 // DashboardHandler 仪表板处理器
 type DashboardHandler struct {
-	txService *service.TransactionService
+	txService   *service.TransactionService
+	fxService   *service.FXService
+	broadcaster *blockchain.Broadcaster
 }
 
+// streamHeartbeat 实时推送的心跳间隔, 防止代理因空闲断开连接
+const streamHeartbeat = 15 * time.Second
+
+// defaultAmountRangeDays 金额合计未指定日期范围时统计的天数(含当天)
+const defaultAmountRangeDays = 30
+
 // NewDashboardHandler 创建仪表板处理器
-func NewDashboardHandler(txService *service.TransactionService) *DashboardHandler {
+func NewDashboardHandler(txService *service.TransactionService, fxService *service.FXService, broadcaster *blockchain.Broadcaster) *DashboardHandler {
 	return &DashboardHandler{
-		txService: txService,
+		txService:   txService,
+		fxService:   fxService,
+		broadcaster: broadcaster,
 	}
 }
 
 // GetOverview 获取概览数据
 // @Summary 获取概览数据
-// @Description 获取系统概览统计数据
+// @Description 获取当前机构的概览统计数据
 // @Tags dashboard
 // @Produce json
+// @Param reporting_currency query string false "报告币种, 提供时附带近30天按该币种折算的金额合计"
 // @Success 200 {object} utils.Response
 // @Router /api/v1/dashboard/overview [get]
 func (h *DashboardHandler) GetOverview(c *gin.Context) {
-	institutionID := c.Query("institution_id")
+	institutionID := currentInstitutionID(c)
 
 	stats, err := h.txService.GetStatistics(institutionID)
 	if err != nil {
@@ -45,9 +62,122 @@ func (h *DashboardHandler) GetOverview(c *gin.Context) {
 		"uploaded_count":     stats.UploadedCount,
 	}
 
+	if currency := c.Query("reporting_currency"); currency != "" {
+		today := time.Now()
+		start := today.AddDate(0, 0, 1-defaultAmountRangeDays)
+		amounts, err := h.fxService.AmountStatistics(institutionID, currency, start, today, today)
+		if err != nil {
+			h.handleFXError(c, err)
+			return
+		}
+		overview["amount_totals"] = amounts
+	}
+
 	utils.Success(c, overview)
 }
 
+// GetAmountTotals 获取金额合计
+// @Summary 获取金额合计
+// @Description 按币种汇总当前机构在日期范围内创建的交易金额, 并以参考汇率折算为报告币种; 缺少汇率的币种在 missing_rates 中列出, 不计入折算合计
+// @Tags dashboard
+// @Produce json
+// @Param start_date query string false "起始日期(YYYY-MM-DD), 默认截止日期前30天(含)"
+// @Param end_date query string false "截止日期(YYYY-MM-DD), 默认当天; 范围不超过366天"
+// @Param reporting_currency query string false "报告币种, 默认取配置"
+// @Param as_of query string false "汇率日期(YYYY-MM-DD), 默认当天"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/dashboard/amount-totals [get]
+func (h *DashboardHandler) GetAmountTotals(c *gin.Context) {
+	asOf := time.Now()
+	if value := c.Query("as_of"); value != "" {
+		d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			utils.BadRequest(c, "汇率日期格式错误, 应为 YYYY-MM-DD")
+			return
+		}
+		asOf = d
+	}
+
+	end := time.Now()
+	if value := c.Query("end_date"); value != "" {
+		d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			utils.BadRequest(c, "截止日期格式错误, 应为 YYYY-MM-DD")
+			return
+		}
+		end = d
+	}
+	start := end.AddDate(0, 0, 1-defaultAmountRangeDays)
+	if value := c.Query("start_date"); value != "" {
+		d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			utils.BadRequest(c, "起始日期格式错误, 应为 YYYY-MM-DD")
+			return
+		}
+		start = d
+	}
+
+	amounts, err := h.fxService.AmountStatistics(currentInstitutionID(c), c.Query("reporting_currency"), start, end, asOf)
+	if err != nil {
+		h.handleFXError(c, err)
+		return
+	}
+
+	utils.Success(c, amounts)
+}
+
+// Stream 实时推送
+// @Summary 仪表板实时推送
+// @Description 以 Server-Sent Events 推送当前机构的事件: block(新区块高度), status(交易状态变化), statistics(统计计数增量, 与 overview 中的计数对应); 连接建立时先推送当前区块高度, 每15秒发送 ping 心跳
+// @Tags dashboard
+// @Produce text/event-stream
+// @Success 200 {string} string "event stream"
+// @Router /api/v1/dashboard/stream [get]
+func (h *DashboardHandler) Stream(c *gin.Context) {
+	events, unsubscribe := h.broadcaster.Subscribe(currentInstitutionID(c))
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent(models.StreamEventBlock, &models.StreamEvent{
+		Type: models.StreamEventBlock,
+		Time: time.Now(),
+		Data: &models.BlockEventData{BlockHeight: h.broadcaster.BlockHeight()},
+	})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case t := <-heartbeat.C:
+			c.SSEvent("ping", t.Unix())
+			return true
+		}
+	})
+}
+
+// handleFXError 处理金额折算错误
+func (h *DashboardHandler) handleFXError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrFXRateInvalid) {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	utils.ServerError(c, err.Error())
+}
+
 // GetChartData 获取图表数据
 // @Summary 获取图表数据
 // @Description 获取用于前端图表展示的数据
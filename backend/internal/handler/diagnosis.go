@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiagnosisHandler 对账失败字段诊断处理器
+type DiagnosisHandler struct {
+	diagnosisService *service.DiagnosisService
+}
+
+// NewDiagnosisHandler 创建字段诊断处理器
+func NewDiagnosisHandler(diagnosisService *service.DiagnosisService) *DiagnosisHandler {
+	return &DiagnosisHandler{
+		diagnosisService: diagnosisService,
+	}
+}
+
+// StartDiagnosis 发起字段诊断
+// @Summary 发起字段诊断
+// @Description 对账失败的交易生成一次性私钥, 计算金额、币种、付款方、收款方、交易类型的盲化值; 返回的交换包(不含私钥)需发送给对手方
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param request body models.StartDiagnosisRequest true "诊断请求"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/diagnoses [post]
+func (h *DiagnosisHandler) StartDiagnosis(c *gin.Context) {
+	var req models.StartDiagnosisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	resp, err := h.diagnosisService.StartDiagnosis(currentInstitutionID(c), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "诊断已发起, 请将交换包发送给对手方", resp)
+}
+
+// ExchangePacket 导入对手方交换包
+// @Summary 导入对手方交换包
+// @Description 导入对手方交换包并返回需回传对手方的交换包; 本方未发起诊断时作为响应方参与, 交换包携带本方字段的二次盲化值时完成比对并返回不一致的字段
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param request body models.DiagnosisPacket true "对手方交换包"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/diagnoses/exchange [post]
+func (h *DiagnosisHandler) ExchangePacket(c *gin.Context) {
+	var packet models.DiagnosisPacket
+	if err := c.ShouldBindJSON(&packet); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	resp, err := h.diagnosisService.ExchangePacket(currentInstitutionID(c), &packet)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// GetDiagnosis 查询诊断结果
+// @Summary 查询诊断结果
+// @Description 查询本方交换包及字段比对结果
+// @Tags diagnoses
+// @Produce json
+// @Param bizId path string true "业务流水号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/diagnoses/{bizId} [get]
+func (h *DiagnosisHandler) GetDiagnosis(c *gin.Context) {
+	resp, err := h.diagnosisService.GetDiagnosis(currentInstitutionID(c), c.Param("bizId"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// handleError 统一处理字段诊断服务错误
+func (h *DiagnosisHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrDiagnosisTxNotFound):
+		utils.NotFound(c, "交易不存在")
+	case errors.Is(err, service.ErrDiagnosisNotFound):
+		utils.NotFound(c, "诊断记录不存在")
+	case errors.Is(err, service.ErrDiagnosisNotMismatch):
+		utils.BadRequest(c, "仅对账失败的交易可进行字段诊断")
+	case errors.Is(err, service.ErrDiagnosisKeyMismatch):
+		utils.BadRequest(c, "交换包与本方诊断不属于同一会话")
+	case errors.Is(err, service.ErrDiagnosisPacketInvalid):
+		utils.BadRequest(c, err.Error())
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FXHandler 汇率处理器
+type FXHandler struct {
+	fxService *service.FXService
+}
+
+// NewFXHandler 创建汇率处理器
+func NewFXHandler(fxService *service.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+	}
+}
+
+// ImportRates 导入汇率文件
+// @Summary 导入汇率文件
+// @Description 上传汇率文件(.xlsx/.csv), 列: 日期, 基准币种, 报价币种, 汇率, 来源(可选); 同一币种对同一日期的汇率覆盖写入
+// @Tags fx-rates
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "汇率文件"
+// @Param source formData string false "汇率来源(行内未填写时使用)"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/fx-rates/import [post]
+func (h *FXHandler) ImportRates(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequest(c, "请上传汇率文件")
+		return
+	}
+
+	uploadID, err := utils.GenerateRandomID()
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	filePath := filepath.Join(os.TempDir(), "imports", uploadID+filepath.Ext(file.Filename))
+	if err := c.SaveUploadedFile(file, filePath); err != nil {
+		utils.ServerError(c, "保存文件失败: "+err.Error())
+		return
+	}
+	defer os.Remove(filePath)
+
+	source := c.PostForm("source")
+	if source == "" {
+		source = file.Filename
+	}
+
+	result, err := h.fxService.ImportRates(filePath, source)
+	if err != nil {
+		utils.BadRequest(c, "汇率文件解析失败: "+err.Error())
+		return
+	}
+
+	if result.Failed > 0 {
+		utils.SuccessWithMessage(c, fmt.Sprintf("已导入%d行, %d行校验失败", result.Imported, result.Failed), result)
+		return
+	}
+	utils.SuccessWithMessage(c, "汇率导入成功", result)
+}
+
+// CreateRate 录入汇率
+// @Summary 录入汇率
+// @Description 录入单条参考汇率: 1 单位基准币种 = rate 单位报价币种
+// @Tags fx-rates
+// @Accept json
+// @Produce json
+// @Param request body models.CreateFXRateRequest true "汇率"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/fx-rates [post]
+func (h *FXHandler) CreateRate(c *gin.Context) {
+	var req models.CreateFXRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	rate, err := h.fxService.CreateRate(&req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "汇率已保存", rate)
+}
+
+// ListRates 查询汇率
+// @Summary 查询汇率
+// @Description 按币种对查询参考汇率, 日期倒序
+// @Tags fx-rates
+// @Produce json
+// @Param base query string false "基准币种"
+// @Param quote query string false "报价币种"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/fx-rates [get]
+func (h *FXHandler) ListRates(c *gin.Context) {
+	page, size := pageParams(c)
+
+	result, err := h.fxService.ListRates(c.Query("base"), c.Query("quote"), page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// DeleteRate 删除汇率
+// @Summary 删除汇率
+// @Tags fx-rates
+// @Produce json
+// @Param id path int true "汇率ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/fx-rates/{id} [delete]
+func (h *FXHandler) DeleteRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "汇率ID错误")
+		return
+	}
+
+	if err := h.fxService.DeleteRate(uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// handleError 统一处理汇率服务错误
+func (h *FXHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFXRateNotFound):
+		utils.NotFound(c, "汇率不存在")
+	case errors.Is(err, service.ErrFXRateUnavailable):
+		utils.BadRequest(c, "缺少可用于折算的汇率")
+	case errors.Is(err, service.ErrFXRateInvalid):
+		utils.BadRequest(c, err.Error())
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
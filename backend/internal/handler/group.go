@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GroupHandler 对账组处理器
+type GroupHandler struct {
+	groupService *service.GroupService
+}
+
+// NewGroupHandler 创建对账组处理器
+func NewGroupHandler(groupService *service.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// CreateGroup 创建对账组
+// @Summary 创建对账组
+// @Description 以组参考号聚合多笔明细(一对多/多对一), 计算明细承诺和组承诺; 关联的本方交易须为待上链, 归组后不能单独上链
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param request body models.CreateGroupRequest true "对账组请求"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/groups [post]
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req models.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(currentInstitutionID(c), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "对账组创建成功", group)
+}
+
+// ListGroups 查询对账组列表
+// @Summary 查询对账组列表
+// @Description 查询当前机构的对账组
+// @Tags groups
+// @Produce json
+// @Param status query int false "状态: 0-待上链, 1-已上链, 2-对账成功, 3-对账失败, 4-上链中"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/groups [get]
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	page, size := pageParams(c)
+
+	var status *int8
+	if value := c.Query("status"); value != "" {
+		st, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			utils.BadRequest(c, "状态参数错误")
+			return
+		}
+		s := int8(st)
+		status = &s
+	}
+
+	result, err := h.groupService.ListGroups(currentInstitutionID(c), status, page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// GetGroup 查询对账组详情
+// @Summary 查询对账组详情
+// @Description 查询对账组的合计金额、组承诺、链上状态及明细拆分
+// @Tags groups
+// @Produce json
+// @Param groupRef path string true "组参考号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/groups/{groupRef} [get]
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	group, err := h.groupService.GetGroup(currentInstitutionID(c), c.Param("groupRef"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, group)
+}
+
+// DeleteGroup 删除对账组
+// @Summary 删除对账组
+// @Description 删除待上链的对账组并释放关联交易
+// @Tags groups
+// @Produce json
+// @Param groupRef path string true "组参考号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/groups/{groupRef} [delete]
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	if err := h.groupService.DeleteGroup(currentInstitutionID(c), c.Param("groupRef")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// UploadGroup 对账组上链
+// @Summary 对账组上链
+// @Description 以组参考号为链上ID上传组承诺, 与对手方的组承诺进行哈希碰撞对账
+// @Tags groups
+// @Produce json
+// @Param groupRef path string true "组参考号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/groups/{groupRef}/upload [post]
+func (h *GroupHandler) UploadGroup(c *gin.Context) {
+	group, err := h.groupService.UploadGroup(c.Request.Context(), currentInstitutionID(c), c.Param("groupRef"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "上链成功", group)
+}
+
+// SyncGroup 同步对账组链上状态
+// @Summary 同步对账组链上状态
+// @Description 查询链上记录, 对手方上传后更新对账结果
+// @Tags groups
+// @Produce json
+// @Param groupRef path string true "组参考号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/groups/{groupRef}/sync [post]
+func (h *GroupHandler) SyncGroup(c *gin.Context) {
+	group, err := h.groupService.SyncGroup(c.Request.Context(), currentInstitutionID(c), c.Param("groupRef"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, group)
+}
+
+// handleError 统一处理对账组服务错误
+func (h *GroupHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound):
+		utils.NotFound(c, "对账组不存在")
+	case errors.Is(err, service.ErrGroupExists):
+		utils.BadRequest(c, "组参考号已存在")
+	case errors.Is(err, service.ErrGroupNotPending):
+		utils.BadRequest(c, "对账组已上链或正在上链")
+	case errors.Is(err, service.ErrGroupInvalid):
+		utils.BadRequest(c, err.Error())
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportBatchHandler 导入批次处理器
+type ImportBatchHandler struct {
+	batchService *service.ImportBatchService
+}
+
+// NewImportBatchHandler 创建导入批次处理器
+func NewImportBatchHandler(batchService *service.ImportBatchService) *ImportBatchHandler {
+	return &ImportBatchHandler{
+		batchService: batchService,
+	}
+}
+
+// pageParams 解析分页参数
+func pageParams(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 10
+	}
+	return page, size
+}
+
+// ListBatches 查询导入批次列表
+// @Summary 查询导入批次列表
+// @Description 查询当前机构的文件导入批次
+// @Tags import-batches
+// @Produce json
+// @Param status query int false "状态: 0-导入中, 1-已完成, 2-已拒绝, 3-导入失败, 4-已回滚"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-batches [get]
+func (h *ImportBatchHandler) ListBatches(c *gin.Context) {
+	page, size := pageParams(c)
+
+	var status *int8
+	if value := c.Query("status"); value != "" {
+		st, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			utils.BadRequest(c, "状态参数错误")
+			return
+		}
+		s := int8(st)
+		status = &s
+	}
+
+	result, err := h.batchService.ListBatches(currentInstitutionID(c), status, page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// GetBatch 查询导入批次详情
+// @Summary 查询导入批次详情
+// @Description 查询批次信息及批次内交易的状态分布
+// @Tags import-batches
+// @Produce json
+// @Param batchNo path string true "批次号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-batches/{batchNo} [get]
+func (h *ImportBatchHandler) GetBatch(c *gin.Context) {
+	batch, err := h.batchService.GetBatch(currentInstitutionID(c), c.Param("batchNo"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, batch)
+}
+
+// ListBatchTransactions 查询批次内交易
+// @Summary 查询批次内交易
+// @Description 分页查询某一导入批次创建的交易
+// @Tags import-batches
+// @Produce json
+// @Param batchNo path string true "批次号"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-batches/{batchNo}/transactions [get]
+func (h *ImportBatchHandler) ListBatchTransactions(c *gin.Context) {
+	page, size := pageParams(c)
+
+	result, err := h.batchService.ListBatchTransactions(currentInstitutionID(c), c.Param("batchNo"), page, size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// RollbackBatch 回滚导入批次
+// @Summary 回滚导入批次
+// @Description 删除批次创建的交易, 仅当批次内交易均为待上链状态且未归入对账组时允许
+// @Tags import-batches
+// @Produce json
+// @Param batchNo path string true "批次号"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-batches/{batchNo}/rollback [post]
+func (h *ImportBatchHandler) RollbackBatch(c *gin.Context) {
+	batch, err := h.batchService.RollbackBatch(currentInstitutionID(c), c.Param("batchNo"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "回滚成功", batch)
+}
+
+// handleError 统一处理批次服务错误
+func (h *ImportBatchHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrImportBatchNotFound):
+		utils.NotFound(c, "导入批次不存在")
+	case errors.Is(err, service.ErrBatchNotRollbackable):
+		utils.BadRequest(c, "批次不可回滚: "+err.Error())
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
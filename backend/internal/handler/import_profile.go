@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportProfileHandler 导入方案处理器
+type ImportProfileHandler struct {
+	profileService *service.ImportProfileService
+}
+
+// NewImportProfileHandler 创建导入方案处理器
+func NewImportProfileHandler(profileService *service.ImportProfileService) *ImportProfileHandler {
+	return &ImportProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// ListProfiles 查询导入方案列表
+// @Summary 查询导入方案列表
+// @Description 查询当前机构的导入配置方案
+// @Tags import-profiles
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-profiles [get]
+func (h *ImportProfileHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.profileService.ListProfiles(currentInstitutionID(c))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, profiles)
+}
+
+// CreateProfile 创建导入方案
+// @Summary 创建导入方案
+// @Description 定义表头别名、列位置、工作表、表头行、日期/金额格式和常量默认值
+// @Tags import-profiles
+// @Accept json
+// @Produce json
+// @Param request body models.ImportProfileRequest true "导入方案"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-profiles [post]
+func (h *ImportProfileHandler) CreateProfile(c *gin.Context) {
+	var req models.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	profile, err := h.profileService.CreateProfile(currentInstitutionID(c), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, profile)
+}
+
+// GetProfile 查询导入方案详情
+// @Summary 查询导入方案详情
+// @Tags import-profiles
+// @Produce json
+// @Param id path int true "方案ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-profiles/{id} [get]
+func (h *ImportProfileHandler) GetProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "方案ID格式错误")
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(currentInstitutionID(c), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, profile)
+}
+
+// UpdateProfile 更新导入方案
+// @Summary 更新导入方案
+// @Tags import-profiles
+// @Accept json
+// @Produce json
+// @Param id path int true "方案ID"
+// @Param request body models.ImportProfileRequest true "导入方案"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-profiles/{id} [put]
+func (h *ImportProfileHandler) UpdateProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "方案ID格式错误")
+		return
+	}
+
+	var req models.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	profile, err := h.profileService.UpdateProfile(currentInstitutionID(c), uint(id), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, profile)
+}
+
+// DeleteProfile 删除导入方案
+// @Summary 删除导入方案
+// @Tags import-profiles
+// @Produce json
+// @Param id path int true "方案ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/import-profiles/{id} [delete]
+func (h *ImportProfileHandler) DeleteProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "方案ID格式错误")
+		return
+	}
+
+	if err := h.profileService.DeleteProfile(currentInstitutionID(c), uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// handleError 将服务层错误映射为响应
+func (h *ImportProfileHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrImportProfileNotFound):
+		utils.NotFound(c, "导入方案不存在")
+	case errors.Is(err, service.ErrInvalidImportProfile):
+		utils.BadRequest(c, err.Error())
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
+
+// currentInstitutionID 获取当前请求的机构ID
+func currentInstitutionID(c *gin.Context) string {
+	institutionID := c.GetString("institution_id")
+	if institutionID == "" {
+		institutionID = "INST001" // 默认机构ID,实际应从JWT中获取
+	}
+	return institutionID
+}
+
+// currentUsername 获取当前用户名
+func currentUsername(c *gin.Context) string {
+	username := c.GetString("username")
+	if username == "" {
+		username = "anonymous" // 未接入认证时的默认用户
+	}
+	return username
+}
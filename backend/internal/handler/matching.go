@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MatchingHandler 容差匹配处理器
+type MatchingHandler struct {
+	matchingService *service.MatchingService
+}
+
+// NewMatchingHandler 创建容差匹配处理器
+func NewMatchingHandler(matchingService *service.MatchingService) *MatchingHandler {
+	return &MatchingHandler{
+		matchingService: matchingService,
+	}
+}
+
+// tolerance 读取请求中覆盖的容差参数, 未提供时使用配置值
+func (h *MatchingHandler) tolerance(c *gin.Context) (service.MatchTolerance, error) {
+	tol := h.matchingService.Tolerance()
+
+	if value := c.Query("amount_tolerance"); value != "" {
+		if _, err := utils.ParseAmount(value); err != nil {
+			return tol, errors.New("金额容差格式错误")
+		}
+		tol.AmountTolerance = value
+	}
+	if value := c.Query("amount_tolerance_rate"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return tol, errors.New("金额相对容差格式错误")
+		}
+		tol.AmountToleranceRate = rate
+	}
+	if value := c.Query("date_window_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return tol, errors.New("日期窗口格式错误")
+		}
+		tol.DateWindowDays = days
+	}
+	if value := c.Query("require_same_parties"); value != "" {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			return tol, errors.New("require_same_parties 参数错误")
+		}
+		tol.RequireSameParties = required
+	}
+	if value := c.Query("min_score"); value != "" {
+		minScore, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return tol, errors.New("最低得分格式错误")
+		}
+		tol.MinScore = minScore
+	}
+	return tol, nil
+}
+
+// GetCandidates 查询候选配对
+// @Summary 查询候选配对
+// @Description 对对账失败或单边上链的交易, 按金额差额、日期窗口和付款方/收款方容差在对手方记录中查找候选并打分
+// @Tags matching
+// @Produce json
+// @Param biz_id query string true "本方业务流水号"
+// @Param counterparty query string true "对手机构ID"
+// @Param amount_tolerance query string false "金额绝对容差"
+// @Param amount_tolerance_rate query number false "金额相对容差"
+// @Param date_window_days query int false "日期窗口(天)"
+// @Param require_same_parties query bool false "要求付款方/收款方一致"
+// @Param min_score query number false "最低得分"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/matching/candidates [get]
+func (h *MatchingHandler) GetCandidates(c *gin.Context) {
+	bizID := c.Query("biz_id")
+	if bizID == "" {
+		utils.BadRequest(c, "请提供业务流水号")
+		return
+	}
+
+	tol, err := h.tolerance(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	candidates, err := h.matchingService.FindCandidates(currentInstitutionID(c), bizID, c.Query("counterparty"), tol)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, candidates)
+}
+
+// GetSuggestions 批量配对建议
+// @Summary 批量配对建议
+// @Description 扫描本机构对账失败及单边上链的交易, 返回存在候选配对的记录
+// @Tags matching
+// @Produce json
+// @Param counterparty query string true "对手机构ID"
+// @Param limit query int false "扫描记录上限" default(100)
+// @Param amount_tolerance query string false "金额绝对容差"
+// @Param date_window_days query int false "日期窗口(天)"
+// @Param min_score query number false "最低得分"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/matching/suggestions [get]
+func (h *MatchingHandler) GetSuggestions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit < 1 || limit > 1000 {
+		limit = 100
+	}
+
+	tol, err := h.tolerance(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	suggestions, err := h.matchingService.Suggest(currentInstitutionID(c), c.Query("counterparty"), tol, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, suggestions)
+}
+
+// AcceptMatch 提出配对
+// @Summary 提出配对
+// @Description 运营人员选定候选配对, 记录差额、调整类型和原因; 生成的调整记录需对手方确认后生效
+// @Tags matching
+// @Accept json
+// @Produce json
+// @Param request body models.AcceptMatchRequest true "配对请求"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/matching/accept [post]
+func (h *MatchingHandler) AcceptMatch(c *gin.Context) {
+	var req models.AcceptMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	tol, err := h.tolerance(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	adjustment, err := h.matchingService.AcceptMatch(currentInstitutionID(c), currentUsername(c), &req, tol)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "配对已提交, 等待对手方确认", adjustment)
+}
+
+// ConfirmAdjustment 确认配对
+// @Summary 确认配对
+// @Description 对手方确认调整记录, 双方交易更新为对账成功, 对账记录标记为容差匹配
+// @Tags matching
+// @Produce json
+// @Param id path int true "调整记录ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/matching/adjustments/{id}/confirm [post]
+func (h *MatchingHandler) ConfirmAdjustment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "调整记录ID错误")
+		return
+	}
+
+	adjustment, err := h.matchingService.ConfirmAdjustment(currentInstitutionID(c), currentUsername(c), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "配对已确认", adjustment)
+}
+
+// ListAdjustments 查询调整记录
+// @Summary 查询调整记录
+// @Description 查询本机构参与的容差匹配调整记录
+// @Tags matching
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/matching/adjustments [get]
+func (h *MatchingHandler) ListAdjustments(c *gin.Context) {
+	page, size := pageParams(c)
+
+	result, err := h.matchingService.ListAdjustments(currentInstitutionID(c), page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// RevokeAdjustment 撤销配对
+// @Summary 撤销配对
+// @Description 提出方撤回或对手方拒绝配对, 删除调整记录; 已确认的配对双方交易和对账记录恢复为确认前状态
+// @Tags matching
+// @Produce json
+// @Param id path int true "调整记录ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/matching/adjustments/{id} [delete]
+func (h *MatchingHandler) RevokeAdjustment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(c, "调整记录ID错误")
+		return
+	}
+
+	if err := h.matchingService.RevokeAdjustment(currentInstitutionID(c), uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已撤销", nil)
+}
+
+// handleError 统一处理容差匹配服务错误
+func (h *MatchingHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMatchSourceNotFound):
+		utils.NotFound(c, "交易不存在")
+	case errors.Is(err, service.ErrAdjustmentNotFound):
+		utils.NotFound(c, "调整记录不存在")
+	case errors.Is(err, service.ErrAdjustmentNotProposed):
+		utils.BadRequest(c, "配对已确认")
+	case errors.Is(err, service.ErrMatchNotEligible):
+		utils.BadRequest(c, "交易无需容差匹配: "+err.Error())
+	case errors.Is(err, service.ErrMatchCounterpartyRequired):
+		utils.BadRequest(c, "请提供对手机构ID")
+	case errors.Is(err, service.ErrMatchOutOfTolerance):
+		utils.BadRequest(c, "配对超出容差范围")
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 邮件通知处理器
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler 创建邮件通知处理器
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetPreference 查询当前用户的通知偏好
+// @Summary 查询邮件通知偏好
+// @Description 邮件发送到用户登记的邮箱; 未设置偏好时不接收邮件
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/preferences [get]
+func (h *NotificationHandler) GetPreference(c *gin.Context) {
+	pref, err := h.notificationService.GetPreference(currentUsername(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, pref)
+}
+
+// UpdatePreference 更新当前用户的通知偏好
+// @Summary 更新邮件通知偏好
+// @Description 设置邮件语言(zh/en)、对账失败即时通知和每日摘要, 未提供的字段保持不变
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateNotificationPreferenceRequest true "通知偏好"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreference(c *gin.Context) {
+	var req models.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	pref, err := h.notificationService.UpdatePreference(currentUsername(c), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "通知偏好已更新", pref)
+}
+
+// SendTestMail 发送测试邮件
+// @Summary 发送测试邮件
+// @Description 向当前用户的邮箱发送一封测试邮件, 用于检查SMTP配置
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/test [post]
+func (h *NotificationHandler) SendTestMail(c *gin.Context) {
+	if err := h.notificationService.SendTestMail(currentUsername(c)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "测试邮件已发送", nil)
+}
+
+// SendDigest 立即发送摘要
+// @Summary 立即发送对账摘要
+// @Description 立即向当前用户发送自上次摘要以来的对账摘要(不影响每日摘要的统计窗口), 返回摘要内容
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/digest [post]
+func (h *NotificationHandler) SendDigest(c *gin.Context) {
+	digest, err := h.notificationService.SendDigestNow(currentUsername(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "摘要已发送", digest)
+}
+
+// handleError 统一处理通知服务错误
+func (h *NotificationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotificationUserNotFound):
+		utils.NotFound(c, "用户不存在")
+	case errors.Is(err, service.ErrNotificationNoEmail):
+		utils.BadRequest(c, "用户未登记邮箱")
+	case errors.Is(err, utils.ErrMailNotConfigured):
+		utils.BadRequest(c, "未配置邮件服务器")
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
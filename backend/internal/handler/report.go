@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler 报表处理器
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+// NewReportHandler 创建报表处理器
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// ExportReconciliation 导出对账报表
+// @Summary 导出对账报表
+// @Description 按日期范围和状态导出当前机构的交易对账结果, Excel包含汇总/对账成功/对账失败/待上链/已上链未对账工作表, CSV为单表明细
+// @Tags reports
+// @Produce application/octet-stream
+// @Param format query string false "导出格式: xlsx, csv" default(xlsx)
+// @Param start_date query string false "起始日期(YYYY-MM-DD)"
+// @Param end_date query string false "截止日期(YYYY-MM-DD)"
+// @Param status query int false "交易状态: 0-待上链, 1-已上链, 2-对账成功, 3-对账失败, 4-已提交(待回执确认)"
+// @Success 200 {file} file
+// @Router /api/v1/reports/reconciliation [get]
+func (h *ReportHandler) ExportReconciliation(c *gin.Context) {
+	format := c.DefaultQuery("format", service.ReportFormatExcel)
+	if format != service.ReportFormatExcel && format != service.ReportFormatCSV {
+		utils.BadRequest(c, "导出格式仅支持 xlsx 或 csv")
+		return
+	}
+
+	filter := &service.ReconciliationReportFilter{
+		InstitutionID: currentInstitutionID(c),
+	}
+
+	for param, target := range map[string]**time.Time{"start_date": &filter.StartDate, "end_date": &filter.EndDate} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			utils.BadRequest(c, fmt.Sprintf("日期格式错误(%s), 应为YYYY-MM-DD", param))
+			return
+		}
+		*target = &date
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		utils.BadRequest(c, "截止日期不能早于起始日期")
+		return
+	}
+
+	if value := c.Query("status"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil || status < int(models.TxStatusPending) || status > int(models.TxStatusSubmitted) {
+			utils.BadRequest(c, "状态参数错误")
+			return
+		}
+		s := int8(status)
+		filter.Status = &s
+	}
+
+	filename := fmt.Sprintf("reconciliation_report_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	var err error
+	if format == service.ReportFormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		err = h.reportService.ExportReconciliationCSV(c.Writer, filter)
+	} else {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = h.reportService.ExportReconciliationExcel(c.Writer, filter)
+	}
+
+	if err != nil {
+		// 已开始输出文件内容时无法再返回JSON错误, 只能中断响应
+		if c.Writer.Written() {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Header("Content-Disposition", "")
+		utils.ServerError(c, "导出报表失败: "+err.Error())
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatisticsHandler 对账统计处理器
+type StatisticsHandler struct {
+	statisticsService *service.StatisticsService
+}
+
+// NewStatisticsHandler 创建对账统计处理器
+func NewStatisticsHandler(statisticsService *service.StatisticsService) *StatisticsHandler {
+	return &StatisticsHandler{
+		statisticsService: statisticsService,
+	}
+}
+
+// GetCounterpartyMatrix 获取对手方对账矩阵
+// @Summary 获取对手方对账矩阵
+// @Description 按当前机构与各对手方统计交易量、已上链、对账成功/失败、待上链笔数及平均对账耗时(本方上链至对账成功), 并与上一周期比较; 交易按创建时间归入周期
+// @Tags dashboard
+// @Produce json
+// @Param period query string false "统计周期: day/week/month/quarter/custom" default(month)
+// @Param date query string false "周期内任意一天(YYYY-MM-DD), 默认今天"
+// @Param from query string false "自定义周期起始日期(YYYY-MM-DD, 含当天)"
+// @Param to query string false "自定义周期截止日期(YYYY-MM-DD, 含当天)"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/dashboard/counterparty-matrix [get]
+func (h *StatisticsHandler) GetCounterpartyMatrix(c *gin.Context) {
+	period := c.DefaultQuery("period", models.StatisticsPeriodMonth)
+
+	var anchor, from, to time.Time
+	for param, target := range map[string]*time.Time{"date": &anchor, "from": &from, "to": &to} {
+		if v := c.Query(param); v != "" {
+			d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+			if err != nil {
+				utils.BadRequest(c, param+" 格式错误, 应为 YYYY-MM-DD")
+				return
+			}
+			*target = d
+		}
+	}
+	if anchor.IsZero() {
+		anchor = time.Now()
+	}
+
+	current, previous, err := service.PeriodRange(period, anchor, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	matrix, err := h.statisticsService.CounterpartyMatrix(currentInstitutionID(c), period, current, previous)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, matrix)
+}
+
+// handleError 统一处理统计服务错误
+func (h *StatisticsHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPeriod):
+		utils.BadRequest(c, "统计周期无效: period 须为 day/week/month/quarter/custom, custom 须提供 from 和 to")
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
@@ -1,7 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"bc-reconciliation-backend/internal/models"
 	"bc-reconciliation-backend/internal/service"
@@ -12,13 +18,15 @@ import (
 
 // TransactionHandler 交易处理器
 type TransactionHandler struct {
-	txService *service.TransactionService
+	txService      *service.TransactionService
+	profileService *service.ImportProfileService
 }
 
 // NewTransactionHandler 创建交易处理器
-func NewTransactionHandler(txService *service.TransactionService) *TransactionHandler {
+func NewTransactionHandler(txService *service.TransactionService, profileService *service.ImportProfileService) *TransactionHandler {
 	return &TransactionHandler{
-		txService: txService,
+		txService:      txService,
+		profileService: profileService,
 	}
 }
 
@@ -58,53 +66,128 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	utils.Success(c, result)
 }
 
-// UploadExcel 上传Excel文件
-// @Summary 上传Excel文件
-// @Description 上传Excel文件并批量创建交易
+// UploadExcel 上传导入文件
+// @Summary 上传导入文件
+// @Description 上传Excel/CSV/定长文本/MT940/camt.053对账单文件并批量创建交易, 文本文件自动识别GBK/GB18030/UTF-8编码; 每次导入生成一个批次, 同一文件重复上传将被拒绝
 // @Tags transactions
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "Excel文件"
+// @Param file formData file true "导入文件(.xlsx/.csv/.txt/.sta/.xml)"
+// @Param mode formData string false "导入模式: partial-仅导入有效行, strict-存在错误则整体拒绝" default(partial)
+// @Param profile formData string false "导入方案名称, 为空使用默认表头"
+// @Param force formData bool false "忽略重复文件检测" default(false)
+// @Param dry_run formData bool false "仅预检: 返回每行的预测结果, 不写入任何数据" default(false)
 // @Success 200 {object} utils.Response
 // @Router /api/v1/transactions/excel [post]
 func (h *TransactionHandler) UploadExcel(c *gin.Context) {
 	// 获取上传的文件
 	file, err := c.FormFile("file")
 	if err != nil {
-		utils.BadRequest(c, "请上传Excel文件")
+		utils.BadRequest(c, "请上传导入文件")
 		return
 	}
 
-	// 保存临时文件
-	filePath := "/tmp/" + file.Filename
+	// 导入模式
+	mode := c.DefaultPostForm("mode", service.ImportModePartial)
+	if mode != service.ImportModePartial && mode != service.ImportModeStrict {
+		utils.BadRequest(c, "导入模式仅支持 partial 或 strict")
+		return
+	}
+
+	// 保存临时文件(随机文件名, 保留扩展名用于格式识别)
+	uploadID, err := utils.GenerateRandomID()
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+	filePath := filepath.Join(os.TempDir(), "imports", uploadID+filepath.Ext(file.Filename))
 	if err := c.SaveUploadedFile(file, filePath); err != nil {
 		utils.ServerError(c, "保存文件失败: "+err.Error())
 		return
 	}
+	defer os.Remove(filePath)
 
 	// 获取机构ID
-	institutionID := c.GetString("institution_id")
-	if institutionID == "" {
-		institutionID = "INST001"
+	institutionID := currentInstitutionID(c)
+
+	// 解析导入方案
+	profileName := c.PostForm("profile")
+	opts, err := h.profileService.ResolveParseOptions(institutionID, profileName)
+	if err != nil {
+		if errors.Is(err, service.ErrImportProfileNotFound) {
+			utils.BadRequest(c, "导入方案不存在或已禁用")
+			return
+		}
+		utils.ServerError(c, err.Error())
+		return
 	}
 
-	// 解析Excel并创建交易
-	result, err := h.txService.ParseExcelAndCreate(filePath, institutionID)
+	// 预检模式
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	if dryRun {
+		result, err := h.txService.PreviewImport(c.Request.Context(), filePath, institutionID, opts)
+		if err != nil {
+			utils.ServerError(c, "解析导入文件失败: "+err.Error())
+			return
+		}
+		utils.Success(c, result)
+		return
+	}
+
+	// 按批次导入
+	force, _ := strconv.ParseBool(c.DefaultPostForm("force", "false"))
+	result, err := h.txService.ImportFile(filePath, &service.ImportFileOptions{
+		FileName:      file.Filename,
+		InstitutionID: institutionID,
+		Uploader:      currentUsername(c),
+		Mode:          mode,
+		ProfileName:   profileName,
+		Parse:         opts,
+		Force:         force,
+	})
 	if err != nil {
-		utils.ServerError(c, "解析Excel失败: "+err.Error())
+		var dupErr *service.DuplicateImportError
+		if errors.As(err, &dupErr) {
+			utils.FailWithData(c, utils.CodeBadRequest, "该文件已导入过(批次 "+dupErr.Batch.BatchNo+"), 如需重复导入请设置 force=true", dupErr.Batch)
+			return
+		}
+		utils.ServerError(c, "解析导入文件失败: "+err.Error())
+		return
+	}
+
+	if result.Rejected {
+		utils.FailWithData(c, utils.CodeBadRequest, "存在错误行, 已整体拒绝导入", result)
 		return
 	}
 
 	utils.Success(c, result)
 }
 
+// DownloadImportErrorReport 下载导入错误标注工作簿
+// @Summary 下载导入错误报告
+// @Description 下载标注了错误单元格的Excel工作簿, 仅可下载本机构的报告, 报告生成24小时后过期
+// @Tags transactions
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param reportId path string true "错误报告ID"
+// @Router /api/v1/transactions/excel/errors/{reportId} [get]
+func (h *TransactionHandler) DownloadImportErrorReport(c *gin.Context) {
+	path, err := h.txService.GetImportErrorReportPath(currentInstitutionID(c), c.Param("reportId"))
+	if err != nil {
+		utils.NotFound(c, "错误报告不存在")
+		return
+	}
+
+	c.FileAttachment(path, "导入错误报告.xlsx")
+}
+
 // UploadToChain 上链
 // @Summary 交易上链
-// @Description 将交易上传到区块链
+// @Description 将交易提交到区块链, 提交成功后状态为已提交(4), 回执确认后转为已上链, 超过区块限制未打包或执行失败时退回待上链; dry_run 为 true 时仅预检并返回每笔交易的预测对账结果
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param request body models.UploadChainRequest true "上链请求"
+// @Param dry_run query bool false "仅预检, 不上链" default(false)
 // @Success 200 {object} utils.Response
 // @Router /api/v1/transactions/upload-chain [post]
 func (h *TransactionHandler) UploadToChain(c *gin.Context) {
@@ -119,6 +202,17 @@ func (h *TransactionHandler) UploadToChain(c *gin.Context) {
 		return
 	}
 
+	// 预检模式
+	if dryRun, _ := strconv.ParseBool(c.Query("dry_run")); dryRun || req.DryRun {
+		result, err := h.txService.PreviewUploadToChain(c.Request.Context(), currentInstitutionID(c), req.BizIDs)
+		if err != nil {
+			utils.ServerError(c, err.Error())
+			return
+		}
+		utils.Success(c, result)
+		return
+	}
+
 	// 获取合约地址(从配置或数据库读取)
 	contractAddress := c.GetString("contract_address")
 	if contractAddress == "" {
@@ -158,43 +252,137 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 
 // ListTransactions 查询交易列表
 // @Summary 查询交易列表
-// @Description 分页查询交易列表
+// @Description 游标分页查询交易列表: 首次请求不带 cursor, 之后以响应中的 next_cursor 查询下一页, has_more 为 false 时结束; 更换排序条件需从第一页开始
 // @Tags transactions
 // @Produce json
-// @Param page query int false "页码" default(1)
-// @Param size query int false "每页数量" default(10)
-// @Param status query int false "状态"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param size query int false "每页数量(1-100)" default(10)
+// @Param status query int false "状态: 0-待上链 1-已上链 2-对账成功 3-对账失败 4-已提交(待回执确认), 不传查询全部"
 // @Param institution_id query string false "机构ID"
+// @Param date_from query string false "交易日期起(YYYY-MM-DD)"
+// @Param date_to query string false "交易日期止(YYYY-MM-DD)"
+// @Param sender query string false "付款方"
+// @Param receiver query string false "收款方"
+// @Param counterparty query string false "对手方(付款方或收款方)"
+// @Param tx_type query int false "交易类型"
+// @Param biz_id_prefix query string false "业务流水号前缀"
+// @Param tx_hash query string false "链上交易哈希"
+// @Param block_from query int false "上链区块高度起"
+// @Param block_to query int false "上链区块高度止"
+// @Param sort query string false "排序字段: created_at/updated_at/tx_date/biz_id/status/block_height/id" default(created_at)
+// @Param order query string false "排序方向: asc/desc" default(desc)
 // @Success 200 {object} utils.Response
 // @Router /api/v1/transactions [get]
 func (h *TransactionHandler) ListTransactions(c *gin.Context) {
-	// 解析分页参数
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	query, err := listQueryParams(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.txService.ListTransactions(query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			utils.BadRequest(c, "游标无效或与排序条件不一致")
+		case errors.Is(err, service.ErrInvalidSortField):
+			utils.BadRequest(c, "不支持的排序字段")
+		default:
+			utils.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// listQueryParams 解析交易列表查询参数
+func listQueryParams(c *gin.Context) (*models.TransactionListQuery, error) {
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-	if page < 1 {
-		page = 1
+	query := &models.TransactionListQuery{
+		InstitutionID: c.Query("institution_id"),
+		Sender:        c.Query("sender"),
+		Receiver:      c.Query("receiver"),
+		Counterparty:  c.Query("counterparty"),
+		BizIDPrefix:   c.Query("biz_id_prefix"),
+		TxHash:        c.Query("tx_hash"),
+		SortBy:        c.Query("sort"),
+		Cursor:        c.Query("cursor"),
+		Size:          size,
 	}
-	if size < 1 || size > 100 {
-		size = 10
+	if query.InstitutionID == "" {
+		query.InstitutionID = c.GetString("institution_id")
 	}
 
-	// 解析状态参数
-	status, _ := strconv.ParseInt(c.Query("status"), 10, 8)
+	switch strings.ToLower(c.DefaultQuery("order", "desc")) {
+	case "desc":
+		query.Desc = true
+	case "asc":
+	default:
+		return nil, errors.New("排序方向仅支持 asc 或 desc")
+	}
 
-	// 获取机构ID
-	institutionID := c.Query("institution_id")
-	if institutionID == "" {
-		institutionID = c.GetString("institution_id")
+	// 状态 0(待上链) 为有效过滤值, 未传时不过滤
+	if v := c.Query("status"); v != "" {
+		status, err := strconv.ParseInt(v, 10, 8)
+		if err != nil || status < int64(models.TxStatusPending) || status > int64(models.TxStatusSubmitted) {
+			return nil, errors.New("状态参数无效")
+		}
+		s := int8(status)
+		query.Status = &s
+	}
+	if v := c.Query("tx_type"); v != "" {
+		txType, err := strconv.ParseInt(v, 10, 8)
+		if err != nil {
+			return nil, errors.New("交易类型参数无效")
+		}
+		t := int8(txType)
+		query.TxType = &t
+	}
+
+	for param, target := range map[string]**time.Time{"date_from": &query.DateFrom, "date_to": &query.DateTo} {
+		if v := c.Query(param); v != "" {
+			d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("%s 格式错误, 应为 YYYY-MM-DD", param)
+			}
+			*target = &d
+		}
+	}
+	for param, target := range map[string]**int64{"block_from": &query.BlockFrom, "block_to": &query.BlockTo} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s 参数无效", param)
+			}
+			*target = &n
+		}
+	}
+	return query, nil
+}
+
+// SearchByAmount 按金额检索交易
+// @Summary 按金额检索交易
+// @Description 基于金额盲索引在当前机构的交易中进行等值检索, 机构取自认证信息, 不能检索其他机构
+// @Tags transactions
+// @Produce json
+// @Param amount query string true "金额"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/transactions/search [get]
+func (h *TransactionHandler) SearchByAmount(c *gin.Context) {
+	amount := c.Query("amount")
+	if amount == "" {
+		utils.BadRequest(c, "金额不能为空")
+		return
 	}
 
-	// 查询列表
-	result, err := h.txService.ListTransactions(institutionID, page, size, int8(status))
+	txs, err := h.txService.FindTransactionsByAmount(currentInstitutionID(c), amount)
 	if err != nil {
 		utils.ServerError(c, err.Error())
 		return
 	}
 
-	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+	utils.Success(c, txs)
 }
 
 // GetStatistics 获取统计数据
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/service"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler Webhook订阅处理器
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler 创建Webhook订阅处理器
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// idParam 解析路径中的数字ID
+func idParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateSubscription 创建订阅
+// @Summary 创建Webhook订阅
+// @Description 订阅对账状态变化事件(reconciliation.matched / reconciliation.mismatch); 未指定签名密钥时自动生成, 密钥仅在本次响应中返回
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body models.CreateWebhookRequest true "订阅"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(currentInstitutionID(c), currentUsername(c), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "订阅已创建, 请妥善保存签名密钥", sub)
+}
+
+// ListSubscriptions 查询订阅列表
+// @Summary 查询Webhook订阅列表
+// @Tags webhooks
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	page, size := pageParams(c)
+
+	result, err := h.webhookService.ListSubscriptions(currentInstitutionID(c), page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// GetSubscription 查询订阅详情
+// @Summary 查询Webhook订阅详情
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "订阅ID错误")
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(currentInstitutionID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, sub)
+}
+
+// UpdateSubscription 更新订阅
+// @Summary 更新Webhook订阅
+// @Description 更新推送地址、事件类型、说明或启用状态, 未提供的字段保持不变
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body models.UpdateWebhookRequest true "订阅"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "订阅ID错误")
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	sub, err := h.webhookService.UpdateSubscription(currentInstitutionID(c), id, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "订阅已更新", sub)
+}
+
+// DeleteSubscription 删除订阅
+// @Summary 删除Webhook订阅
+// @Description 删除后不再推送, 推送记录保留备查
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "订阅ID错误")
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(currentInstitutionID(c), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// TestDelivery 测试推送
+// @Summary Webhook测试推送
+// @Description 立即向订阅地址发送一次 webhook.test 事件(不重试), 返回推送记录(含响应码)
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhooks/{id}/test [post]
+func (h *WebhookHandler) TestDelivery(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "订阅ID错误")
+		return
+	}
+
+	delivery, err := h.webhookService.TestDelivery(c.Request.Context(), currentInstitutionID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if delivery.Status != models.WebhookDeliverySucceeded {
+		utils.SuccessWithMessage(c, "测试推送失败: "+delivery.LastError, delivery)
+		return
+	}
+	utils.SuccessWithMessage(c, "测试推送成功", delivery)
+}
+
+// ListDeliveries 查询推送记录
+// @Summary 查询Webhook推送记录
+// @Tags webhooks
+// @Produce json
+// @Param subscription_id query int false "订阅ID"
+// @Param status query int false "状态(0-待推送 1-推送中 2-推送成功 3-推送失败)"
+// @Param biz_id query string false "业务流水号"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhook-deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	page, size := pageParams(c)
+
+	var subscriptionID *uint
+	if value := c.Query("subscription_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			utils.BadRequest(c, "订阅ID错误")
+			return
+		}
+		v := uint(id)
+		subscriptionID = &v
+	}
+
+	var status *int8
+	if value := c.Query("status"); value != "" {
+		s, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			utils.BadRequest(c, "状态参数错误")
+			return
+		}
+		v := int8(s)
+		status = &v
+	}
+
+	result, err := h.webhookService.ListDeliveries(currentInstitutionID(c), subscriptionID, status, c.Query("biz_id"), page, size)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.PageSuccess(c, result.Total, result.Page, result.Size, result.Data)
+}
+
+// GetDelivery 查询推送记录详情
+// @Summary 查询Webhook推送记录详情
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "推送记录ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhook-deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "推送记录ID错误")
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(currentInstitutionID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, delivery)
+}
+
+// ReplayDelivery 重放推送
+// @Summary 重放Webhook推送
+// @Description 以原事件内容(事件ID不变)生成新的推送记录并立即推送, 失败时按重试策略继续
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "推送记录ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/webhook-deliveries/{id}/replay [post]
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		utils.BadRequest(c, "推送记录ID错误")
+		return
+	}
+
+	delivery, err := h.webhookService.ReplayDelivery(c.Request.Context(), currentInstitutionID(c), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已重放", delivery)
+}
+
+// handleError 统一处理Webhook服务错误
+func (h *WebhookHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		utils.NotFound(c, "订阅不存在")
+	case errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		utils.NotFound(c, "推送记录不存在")
+	case errors.Is(err, service.ErrWebhookDisabled):
+		utils.BadRequest(c, "订阅已停用")
+	case errors.Is(err, service.ErrWebhookInvalid):
+		utils.BadRequest(c, err.Error())
+	default:
+		utils.ServerError(c, err.Error())
+	}
+}
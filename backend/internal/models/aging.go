@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// AgingAlert 单边上链逾期告警表
+// 本方已上链而对手方超过 SLA 仍未上链的交易, 按对手方统计逾期笔数, 达到阈值时生成告警
+type AgingAlert struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	InstitutionID  string     `json:"institution_id" gorm:"index:idx_aging_alert_party,priority:1;size:64;comment:机构ID"`
+	Counterparty   string     `json:"counterparty" gorm:"index:idx_aging_alert_party,priority:2;size:128;comment:对手方"`
+	OverdueCount   int64      `json:"overdue_count" gorm:"comment:逾期笔数"`
+	Threshold      int        `json:"threshold" gorm:"comment:告警阈值"`
+	OldestDays     int        `json:"oldest_days" gorm:"comment:最长账龄(天)"`
+	Status         int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty" gorm:"size:64;comment:确认人"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" gorm:"comment:确认时间"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" gorm:"comment:解除时间"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (AgingAlert) TableName() string {
+	return "aging_alerts"
+}
+
+// AgingAlertStatus 告警状态常量
+const (
+	AgingAlertStatusOpen         int8 = 0 // 告警中
+	AgingAlertStatusAcknowledged int8 = 1 // 已确认(仍逾期)
+	AgingAlertStatusResolved     int8 = 2 // 已解除(逾期笔数回落到阈值以下)
+)
+
+// GetStatusText 获取状态文本
+func (a *AgingAlert) GetStatusText() string {
+	switch a.Status {
+	case AgingAlertStatusOpen:
+		return "告警中"
+	case AgingAlertStatusAcknowledged:
+		return "已确认"
+	case AgingAlertStatusResolved:
+		return "已解除"
+	default:
+		return "未知"
+	}
+}
+
+// AgingBucket 账龄分段
+type AgingBucket struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// AgingRow 单个对手方的账龄统计
+type AgingRow struct {
+	Counterparty string         `json:"counterparty"`
+	Total        int64          `json:"total"`
+	Buckets      []*AgingBucket `json:"buckets"`
+	Overdue      int64          `json:"overdue"`
+	OldestDays   int            `json:"oldest_days"`
+	Alert        bool           `json:"alert"` // 逾期笔数达到告警阈值
+}
+
+// AgingReport 单边上链账龄报表
+type AgingReport struct {
+	AsOf           time.Time   `json:"as_of"`
+	SLADays        int         `json:"sla_days"`
+	AlertThreshold int         `json:"alert_threshold"`
+	Total          int64       `json:"total"`
+	Overdue        int64       `json:"overdue"`
+	Rows           []*AgingRow `json:"rows"`
+}
+
+// AgingItem 单边上链明细
+type AgingItem struct {
+	BizID        string    `json:"biz_id"`
+	Counterparty string    `json:"counterparty"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+	AgeDays      int       `json:"age_days"`
+	Overdue      bool      `json:"overdue"`
+}
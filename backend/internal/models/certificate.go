@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// ReconciliationCertificate 对账证明表
+// 针对某一对手机构、某一期间内对账成功的交易出具证明, 规范化内容的摘要经机构私钥签名后锚定上链
+type ReconciliationCertificate struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	CertNo            string     `json:"cert_no" gorm:"uniqueIndex;size:32;comment:证明编号(加锚定前缀作为链上ID)"`
+	InstitutionID     string     `json:"institution_id" gorm:"index;size:64;comment:出具机构ID"`
+	Counterparty      string     `json:"counterparty" gorm:"index;size:64;comment:对手机构ID"`
+	PeriodStart       time.Time  `json:"period_start" gorm:"type:date;comment:期间起始日期"`
+	PeriodEnd         time.Time  `json:"period_end" gorm:"type:date;comment:期间截止日期"`
+	ItemCount         int        `json:"item_count" gorm:"comment:交易笔数"`
+	Payload           string     `json:"-" gorm:"type:mediumtext;comment:规范化证明内容(JSON)"`
+	Digest            string     `json:"digest" gorm:"size:64;comment:证明摘要(SHA256)"`
+	Signature         string     `json:"signature" gorm:"size:132;comment:机构签名(secp256k1)"`
+	Signer            string     `json:"signer" gorm:"size:42;comment:签名地址"`
+	AnchorTxHash      string     `json:"anchor_tx_hash" gorm:"size:128;comment:锚定交易哈希"`
+	AnchorBlockHeight int64      `json:"anchor_block_height" gorm:"default:0;comment:锚定区块高度"`
+	AnchoredAt        *time.Time `json:"anchored_at,omitempty" gorm:"comment:锚定时间"`
+	Status            int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ReconciliationCertificate) TableName() string {
+	return "reconciliation_certificates"
+}
+
+// CertificateStatus 对账证明状态常量
+const (
+	CertificateStatusSigned       int8 = 0 // 已签名待锚定
+	CertificateStatusAnchored     int8 = 1 // 已锚定上链
+	CertificateStatusAnchorFailed int8 = 2 // 锚定失败
+)
+
+// GetStatusText 获取状态文本
+func (c *ReconciliationCertificate) GetStatusText() string {
+	switch c.Status {
+	case CertificateStatusSigned:
+		return "待锚定"
+	case CertificateStatusAnchored:
+		return "已锚定"
+	case CertificateStatusAnchorFailed:
+		return "锚定失败"
+	default:
+		return "未知"
+	}
+}
+
+// CertificateItem 证明中的单笔交易
+type CertificateItem struct {
+	BizID       string `json:"biz_id"`
+	TxHash      string `json:"tx_hash"`
+	BlockHeight int64  `json:"block_height"`
+	MatchedAt   string `json:"matched_at"`
+}
+
+// CertificatePayload 规范化证明内容, 字段顺序固定, 其JSON编码的SHA256即证明摘要
+type CertificatePayload struct {
+	CertNo        string            `json:"cert_no"`
+	InstitutionID string            `json:"institution_id"`
+	Counterparty  string            `json:"counterparty"`
+	PeriodStart   string            `json:"period_start"`
+	PeriodEnd     string            `json:"period_end"`
+	IssuedAt      string            `json:"issued_at"`
+	Items         []CertificateItem `json:"items"`
+}
+
+// CreateCertificateRequest 出具对账证明请求
+type CreateCertificateRequest struct {
+	Counterparty string `json:"counterparty" binding:"required"`
+	PeriodStart  string `json:"period_start" binding:"required"` // YYYY-MM-DD
+	PeriodEnd    string `json:"period_end" binding:"required"`   // YYYY-MM-DD
+}
+
+// CertificateResponse 对账证明响应
+type CertificateResponse struct {
+	*ReconciliationCertificate
+	StatusText string              `json:"status_text"`
+	Payload    *CertificatePayload `json:"payload,omitempty"`
+}
+
+// CertificateVerifyResult 对账证明校验结果
+type CertificateVerifyResult struct {
+	CertNo           string `json:"cert_no"`
+	Digest           string `json:"digest"`
+	DigestValid      bool   `json:"digest_valid"`    // 存储内容重新计算的摘要与记录一致
+	DigestMatched    bool   `json:"digest_matched"`  // 与调用方提供的摘要一致(未提供时恒为true)
+	SignatureValid   bool   `json:"signature_valid"` // 签名可恢复出签名地址
+	Signer           string `json:"signer"`
+	SignerRegistered bool   `json:"signer_registered"` // 签名地址为出具机构登记的链上地址
+	ChainAnchored    bool   `json:"chain_anchored"`    // 链上锚定摘要与证明摘要一致
+	ChainUploader    string `json:"chain_uploader,omitempty"`
+	ChainTimestamp   int64  `json:"chain_timestamp,omitempty"`
+	ChainError       string `json:"chain_error,omitempty"`
+	Valid            bool   `json:"valid"`
+}
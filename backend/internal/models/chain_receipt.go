@@ -14,7 +14,8 @@ type ChainReceipt struct {
 	BlockHash       string    `json:"block_hash" gorm:"size:128;comment:区块哈希"`
 	ContractAddress string    `json:"contract_address" gorm:"index;size:42;comment:合约地址"`
 	GasUsed         int64     `json:"gas_used" gorm:"default:0;comment:Gas消耗"`
-	Status          int8      `json:"status" gorm:"default:1;comment:状态"`
+	BlockLimit      int64     `json:"block_limit,omitempty" gorm:"default:0;comment:交易有效的最大区块高度"`
+	Status          int8      `json:"status" gorm:"index;default:1;comment:状态"`
 	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
@@ -25,10 +26,31 @@ func (ChainReceipt) TableName() string {
 
 // ChainReceiptStatus 链上回执状态常量
 const (
-	ChainReceiptStatusFailed  int8 = 0 // 失败
-	ChainReceiptStatusSuccess int8 = 1 // 成功
+	ChainReceiptStatusFailed    int8 = 0 // 失败
+	ChainReceiptStatusSuccess   int8 = 1 // 成功
+	ChainReceiptStatusSubmitted int8 = 2 // 已提交, 等待回执
 )
 
+// CreateValues 写入数据库的列值
+// 以 map 写入: 按结构体写入时零值状态(失败)会被 status 列的默认值替换
+func (c *ChainReceipt) CreateValues() map[string]interface{} {
+	createdAt := c.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return map[string]interface{}{
+		"biz_id":           c.BizID,
+		"tx_hash":          c.TxHash,
+		"block_height":     c.BlockHeight,
+		"block_hash":       c.BlockHash,
+		"contract_address": c.ContractAddress,
+		"gas_used":         c.GasUsed,
+		"block_limit":      c.BlockLimit,
+		"status":           c.Status,
+		"created_at":       createdAt,
+	}
+}
+
 // ToResponse 转换为响应格式
 func (c *ChainReceipt) ToResponse() map[string]interface{} {
 	return map[string]interface{}{
@@ -39,6 +61,7 @@ func (c *ChainReceipt) ToResponse() map[string]interface{} {
 		"block_hash":       c.BlockHash,
 		"contract_address": c.ContractAddress,
 		"gas_used":         c.GasUsed,
+		"block_limit":      c.BlockLimit,
 		"status":           c.Status,
 		"created_at":       c.CreatedAt,
 	}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+// ConsistencyRun 链上一致性检查记录表
+// 逐笔比对已上链交易的本地状态、数据哈希与合约记录, 偏差明细见 consistency_drifts
+type ConsistencyRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Trigger    string     `json:"trigger" gorm:"size:16;comment:触发方式(scheduled/manual)"`
+	BizIDFrom  string     `json:"biz_id_from,omitempty" gorm:"size:64;comment:业务流水号起(含)"`
+	BizIDTo    string     `json:"biz_id_to,omitempty" gorm:"size:64;comment:业务流水号止(含)"`
+	AutoHeal   bool       `json:"auto_heal" gorm:"comment:是否自动修复本地状态"`
+	Status     int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	Checked    int64      `json:"checked" gorm:"comment:已检查笔数"`
+	Drifted    int64      `json:"drifted" gorm:"comment:存在偏差笔数"`
+	Healed     int64      `json:"healed" gorm:"comment:已修复笔数"`
+	Errors     int64      `json:"errors" gorm:"comment:查询链上失败笔数"`
+	LastError  string     `json:"last_error,omitempty" gorm:"size:512;comment:最近一次错误"`
+	StartedBy  string     `json:"started_by,omitempty" gorm:"size:64;comment:发起人"`
+	StartedAt  time.Time  `json:"started_at" gorm:"comment:开始时间"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" gorm:"comment:结束时间"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ConsistencyRun) TableName() string {
+	return "consistency_runs"
+}
+
+// ConsistencyRun 触发方式常量
+const (
+	ConsistencyTriggerScheduled = "scheduled" // 定时检查
+	ConsistencyTriggerManual    = "manual"    // 手动发起
+)
+
+// ConsistencyRunStatus 检查状态常量
+const (
+	ConsistencyRunStatusRunning   int8 = 0 // 进行中
+	ConsistencyRunStatusCompleted int8 = 1 // 已完成
+	ConsistencyRunStatusFailed    int8 = 2 // 异常中止
+)
+
+// GetStatusText 获取状态文本
+func (r *ConsistencyRun) GetStatusText() string {
+	switch r.Status {
+	case ConsistencyRunStatusRunning:
+		return "进行中"
+	case ConsistencyRunStatusCompleted:
+		return "已完成"
+	case ConsistencyRunStatusFailed:
+		return "异常中止"
+	default:
+		return "未知"
+	}
+}
+
+// ConsistencyDrift 链上一致性偏差明细表
+type ConsistencyDrift struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	RunID         uint      `json:"run_id" gorm:"index;comment:检查记录ID"`
+	BizID         string    `json:"biz_id" gorm:"index;size:64;comment:业务流水号"`
+	InstitutionID string    `json:"institution_id" gorm:"index;size:64;comment:机构ID"`
+	DriftType     string    `json:"drift_type" gorm:"index;size:32;comment:偏差类型"`
+	LocalStatus   int8      `json:"local_status" gorm:"comment:本地状态"`
+	ChainStatus   *int8     `json:"chain_status,omitempty" gorm:"comment:链上状态"`
+	LocalHash     string    `json:"local_hash,omitempty" gorm:"size:66;comment:本地数据哈希"`
+	ChainHash     string    `json:"chain_hash,omitempty" gorm:"size:66;comment:链上数据哈希"`
+	Healed        bool      `json:"healed" gorm:"comment:是否已修复"`
+	Detail        string    `json:"detail,omitempty" gorm:"size:255;comment:说明"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (ConsistencyDrift) TableName() string {
+	return "consistency_drifts"
+}
+
+// ConsistencyDrift 偏差类型常量
+const (
+	DriftMissingOnChain = "missing_on_chain" // 本地已上链, 链上无记录
+	DriftHashMismatch   = "hash_mismatch"    // 本方上链(或已对账成功)的记录哈希与本地不一致
+	DriftStatusMismatch = "status_mismatch"  // 本地状态与链上状态不一致
+)
+
+// StartConsistencyCheckRequest 发起一致性检查请求
+type StartConsistencyCheckRequest struct {
+	BizIDFrom string `json:"biz_id_from"` // 业务流水号起(含), 为空不限
+	BizIDTo   string `json:"biz_id_to"`   // 业务流水号止(含), 为空不限
+	AutoHeal  *bool  `json:"auto_heal"`   // 为空时使用配置
+}
@@ -12,7 +12,8 @@ type EventLog struct {
 	ID              uint                  `json:"id" gorm:"primaryKey"`
 	EventType       string                `json:"event_type" gorm:"index;size:64;comment:事件类型"`
 	BizID           string                `json:"biz_id,omitempty" gorm:"index;size:64;comment:业务流水号"`
-	TxHash          string                `json:"tx_hash" gorm:"index;size:128;comment:交易哈希"`
+	TxHash          string                `json:"tx_hash" gorm:"index;uniqueIndex:uk_tx_log;size:128;comment:交易哈希"`
+	LogIndex        int                   `json:"log_index" gorm:"uniqueIndex:uk_tx_log;default:0;comment:日志在交易回执中的序号"`
 	BlockHeight     int64                 `json:"block_height" gorm:"index;comment:区块高度"`
 	ContractAddress string                `json:"contract_address" gorm:"index;size:42;comment:合约地址"`
 	Data            EventData             `json:"data" gorm:"type:json;comment:事件数据"`
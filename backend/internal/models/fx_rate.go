@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// FXRate 汇率参考表
+// 1 单位基准币种 = Rate 单位报价币种, 仅用于统计报表折算, 不参与对账哈希
+type FXRate struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	BaseCurrency  string    `json:"base_currency" gorm:"uniqueIndex:idx_fx_pair_date,priority:1;size:3;comment:基准币种"`
+	QuoteCurrency string    `json:"quote_currency" gorm:"uniqueIndex:idx_fx_pair_date,priority:2;size:3;comment:报价币种"`
+	RateDate      time.Time `json:"rate_date" gorm:"uniqueIndex:idx_fx_pair_date,priority:3;type:date;comment:汇率日期"`
+	Rate          string    `json:"rate" gorm:"size:40;comment:汇率"`
+	Source        string    `json:"source" gorm:"size:64;comment:来源"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (FXRate) TableName() string {
+	return "fx_rates"
+}
+
+// CreateFXRateRequest 录入汇率请求
+type CreateFXRateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,len=3"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,len=3"`
+	RateDate      string `json:"rate_date" binding:"required"` // YYYY-MM-DD
+	Rate          string `json:"rate" binding:"required"`
+	Source        string `json:"source" binding:"max=64"`
+}
+
+// CurrencyTotal 单一币种的金额合计及折算结果
+type CurrencyTotal struct {
+	Currency        string     `json:"currency"`
+	Count           int64      `json:"count"`
+	Amount          string     `json:"amount"`
+	Rate            string     `json:"rate,omitempty"`             // 折算汇率, 缺失时为空
+	RateDate        *time.Time `json:"rate_date,omitempty"`        // 所用汇率日期(交叉汇率取较早者)
+	ConvertedAmount string     `json:"converted_amount,omitempty"` // 折算为报告币种的金额
+}
+
+// AmountStatistics 金额统计(按报告币种折算)
+type AmountStatistics struct {
+	ReportingCurrency string           `json:"reporting_currency"`
+	StartDate         string           `json:"start_date"` // 统计起始日期(按创建时间, 含当天)
+	EndDate           string           `json:"end_date"`   // 统计截止日期(按创建时间, 含当天)
+	AsOf              string           `json:"as_of"`
+	Totals            []*CurrencyTotal `json:"totals"`
+	TotalAmount       string           `json:"total_amount"`            // 可折算部分的合计
+	MissingRates      []string         `json:"missing_rates,omitempty"` // 缺少汇率、未计入合计的币种
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// ImportBatch 导入批次表
+// 每次文件导入生成一个批次, 记录文件摘要、上传人、导入方案和行数统计, 交易通过 batch_id 关联批次
+type ImportBatch struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	BatchNo       string    `json:"batch_no" gorm:"uniqueIndex;size:32;comment:批次号"`
+	InstitutionID string    `json:"institution_id" gorm:"index:idx_institution_digest,priority:1;size:64;comment:机构ID"`
+	FileName      string    `json:"file_name" gorm:"size:256;comment:原始文件名"`
+	FileDigest    string    `json:"file_digest" gorm:"index:idx_institution_digest,priority:2;size:64;comment:文件摘要(SHA256)"`
+	FileSize      int64     `json:"file_size" gorm:"default:0;comment:文件大小(字节)"`
+	FileFormat    string    `json:"file_format" gorm:"size:16;comment:文件格式"`
+	Uploader      string    `json:"uploader" gorm:"size:64;comment:上传人"`
+	ProfileName   string    `json:"profile_name" gorm:"size:64;comment:导入方案名称"`
+	Mode          string    `json:"mode" gorm:"size:16;comment:导入模式"`
+	TotalRows     int       `json:"total_rows" gorm:"default:0;comment:数据总行数"`
+	SuccessRows   int       `json:"success_rows" gorm:"default:0;comment:成功导入行数"`
+	FailedRows    int       `json:"failed_rows" gorm:"default:0;comment:失败行数"`
+	RollbackRows  int       `json:"rollback_rows" gorm:"default:0;comment:回滚删除行数"`
+	ErrorReportID string    `json:"error_report_id,omitempty" gorm:"size:32;comment:错误标注工作簿ID"`
+	ErrorMessage  string    `json:"error_message,omitempty" gorm:"size:512;comment:失败原因"`
+	Status        int8      `json:"status" gorm:"index;default:0;comment:状态"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ImportBatch) TableName() string {
+	return "import_batches"
+}
+
+// ImportBatchStatus 导入批次状态常量
+const (
+	ImportBatchStatusProcessing int8 = 0 // 导入中
+	ImportBatchStatusCompleted  int8 = 1 // 已完成
+	ImportBatchStatusRejected   int8 = 2 // 已拒绝(严格模式存在错误行)
+	ImportBatchStatusFailed     int8 = 3 // 导入失败(文件无法解析等)
+	ImportBatchStatusRolledBack int8 = 4 // 已回滚
+)
+
+// GetStatusText 获取状态文本
+func (b *ImportBatch) GetStatusText() string {
+	switch b.Status {
+	case ImportBatchStatusProcessing:
+		return "导入中"
+	case ImportBatchStatusCompleted:
+		return "已完成"
+	case ImportBatchStatusRejected:
+		return "已拒绝"
+	case ImportBatchStatusFailed:
+		return "导入失败"
+	case ImportBatchStatusRolledBack:
+		return "已回滚"
+	default:
+		return "未知"
+	}
+}
+
+// ImportBatchResponse 导入批次响应
+type ImportBatchResponse struct {
+	*ImportBatch
+	StatusText   string         `json:"status_text"`
+	StatusCounts map[int8]int64 `json:"status_counts,omitempty"` // 批次内交易按状态统计(详情返回)
+	Rollbackable bool           `json:"rollbackable"`            // 批次内交易均待上链时可回滚
+}
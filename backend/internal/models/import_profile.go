@@ -0,0 +1,130 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// ImportProfile 导入配置方案表
+// 每个机构可定义多个方案, 描述银行导出文件的表头别名、列位置、工作表、日期/金额格式和常量默认值
+type ImportProfile struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	InstitutionID      string         `json:"institution_id" gorm:"uniqueIndex:uk_institution_name;size:64;comment:机构ID"`
+	Name               string         `json:"name" gorm:"uniqueIndex:uk_institution_name;size:64;comment:方案名称"`
+	Description        string         `json:"description" gorm:"size:256;comment:方案说明"`
+	FileFormat         string         `json:"file_format" gorm:"size:16;comment:文件格式"`
+	Encoding           string         `json:"encoding" gorm:"size:16;comment:文本编码"`
+	Delimiter          string         `json:"delimiter" gorm:"size:4;comment:CSV分隔符"`
+	QuoteChar          string         `json:"quote_char" gorm:"size:4;comment:CSV引号字符"`
+	FixedColumns       FixedColumns   `json:"fixed_columns" gorm:"type:json;comment:定长列定义"`
+	FixedUnit          string         `json:"fixed_unit" gorm:"size:8;comment:定长计量单位"`
+	SkipLines          int            `json:"skip_lines" gorm:"default:0;comment:定长文本跳过行数"`
+	SheetName          string         `json:"sheet_name" gorm:"size:64;comment:工作表名称"`
+	HeaderRow          int            `json:"header_row" gorm:"default:1;comment:表头所在行"`
+	Columns            ImportColumns  `json:"columns" gorm:"type:json;comment:列映射"`
+	DateFormat         string         `json:"date_format" gorm:"size:32;comment:日期格式"`
+	DecimalSeparator   string         `json:"decimal_separator" gorm:"size:4;comment:小数分隔符"`
+	ThousandsSeparator string         `json:"thousands_separator" gorm:"size:4;comment:千分位分隔符"`
+	Defaults           ImportDefaults `json:"defaults" gorm:"type:json;comment:常量默认值"`
+	Status             int8           `json:"status" gorm:"index;default:1;comment:状态"`
+	CreatedAt          time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ImportProfile) TableName() string {
+	return "import_profiles"
+}
+
+// ImportProfileStatus 导入方案状态常量
+const (
+	ImportProfileStatusDisabled int8 = 0 // 禁用
+	ImportProfileStatusEnabled  int8 = 1 // 启用
+)
+
+// ImportColumn 列映射定义
+type ImportColumn struct {
+	Aliases  []string `json:"aliases"`            // 表头别名(可含英文)
+	Position int      `json:"position,omitempty"` // 列位置(从1开始), 0表示按别名匹配
+}
+
+// ImportColumns 字段 -> 列映射(JSON格式)
+// 字段取值: biz_id, amount, sender, receiver, tx_type, tx_date, currency
+type ImportColumns map[string]ImportColumn
+
+// Scan 实现sql.Scanner接口
+func (c *ImportColumns) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Value 实现driver.Valuer接口
+func (c ImportColumns) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// FixedColumn 定长列定义
+type FixedColumn struct {
+	Name  string `json:"name"`  // 列名(参与别名匹配)
+	Start int    `json:"start"` // 起始位置(从1开始)
+	Width int    `json:"width"` // 宽度
+}
+
+// FixedColumns 定长列定义列表(JSON格式)
+type FixedColumns []FixedColumn
+
+// Scan 实现sql.Scanner接口
+func (f *FixedColumns) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+// Value 实现driver.Valuer接口
+func (f FixedColumns) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// ImportDefaults 字段 -> 常量默认值(JSON格式)
+type ImportDefaults map[string]string
+
+// Scan 实现sql.Scanner接口
+func (d *ImportDefaults) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, d)
+}
+
+// Value 实现driver.Valuer接口
+func (d ImportDefaults) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// ImportProfileRequest 创建/更新导入方案请求
+type ImportProfileRequest struct {
+	Name               string         `json:"name" binding:"required"`
+	Description        string         `json:"description"`
+	FileFormat         string         `json:"file_format"` // excel, csv, fixed_width, 为空按扩展名推断
+	Encoding           string         `json:"encoding"`    // auto, utf-8, gbk, gb18030
+	Delimiter          string         `json:"delimiter"`
+	QuoteChar          string         `json:"quote_char"`
+	FixedColumns       FixedColumns   `json:"fixed_columns"`
+	FixedUnit          string         `json:"fixed_unit"` // byte, char
+	SkipLines          int            `json:"skip_lines"`
+	SheetName          string         `json:"sheet_name"`
+	HeaderRow          int            `json:"header_row"`
+	Columns            ImportColumns  `json:"columns" binding:"required"`
+	DateFormat         string         `json:"date_format"`
+	DecimalSeparator   string         `json:"decimal_separator"`
+	ThousandsSeparator string         `json:"thousands_separator"`
+	Defaults           ImportDefaults `json:"defaults"`
+	Status             *int8          `json:"status"`
+}
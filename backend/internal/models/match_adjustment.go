@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// MatchAdjustment 容差匹配调整表
+// 哈希碰撞失败或单边上链的记录, 由一方运营人员提出与对手方记录配对, 对手方确认后双方交易视为对账成功;
+// 记录差额、原因、提出人和确认人
+type MatchAdjustment struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	InstitutionID      string     `json:"institution_id" gorm:"index;size:64;comment:机构ID"`
+	BizID              string     `json:"biz_id" gorm:"uniqueIndex;size:64;comment:本方业务流水号"`
+	Counterparty       string     `json:"counterparty" gorm:"index;size:64;comment:对手机构ID"`
+	CounterpartyBizID  string     `json:"counterparty_biz_id" gorm:"uniqueIndex;size:64;comment:对手方业务流水号"`
+	AmountDelta        string     `json:"amount_delta" gorm:"size:64;comment:金额差额(本方-对手方)"`
+	DateDeltaDays      int        `json:"date_delta_days" gorm:"comment:交易日期相差天数"`
+	Score              float64    `json:"score" gorm:"comment:匹配得分"`
+	AdjustmentType     string     `json:"adjustment_type" gorm:"size:16;comment:调整类型"`
+	Reason             string     `json:"reason" gorm:"size:512;comment:调整原因"`
+	Operator           string     `json:"operator" gorm:"size:64;comment:提出人"`
+	Status             int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	LocalStatus        int8       `json:"local_status" gorm:"default:0;comment:本方交易确认前状态"`
+	CounterpartyStatus int8       `json:"counterparty_status" gorm:"default:0;comment:对手方交易确认前状态"`
+	ConfirmedBy        string     `json:"confirmed_by,omitempty" gorm:"size:64;comment:对手方确认人"`
+	ConfirmedAt        *time.Time `json:"confirmed_at,omitempty" gorm:"comment:确认时间"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (MatchAdjustment) TableName() string {
+	return "match_adjustments"
+}
+
+// AdjustmentStatus 调整状态常量
+const (
+	AdjustmentStatusProposed  int8 = 0 // 待对手方确认
+	AdjustmentStatusConfirmed int8 = 1 // 对手方已确认
+)
+
+// AdjustmentType 调整类型常量
+const (
+	AdjustmentTypeFee        = "fee"         // 手续费
+	AdjustmentTypeFXRounding = "fx_rounding" // 汇率折算尾差
+	AdjustmentTypeTiming     = "timing"      // 入账日期差异
+	AdjustmentTypeOther      = "other"       // 其他
+)
+
+// MatchCandidate 候选配对
+type MatchCandidate struct {
+	BizID             string  `json:"biz_id"`
+	CounterpartyBizID string  `json:"counterparty_biz_id"`
+	Counterparty      string  `json:"counterparty"`
+	AmountDelta       string  `json:"amount_delta"`
+	DateDeltaDays     *int    `json:"date_delta_days,omitempty"` // 任一方缺少交易日期时为空
+	SenderMatched     bool    `json:"sender_matched"`
+	ReceiverMatched   bool    `json:"receiver_matched"`
+	Score             float64 `json:"score"`
+}
+
+// MatchSuggestion 单条记录的配对建议
+type MatchSuggestion struct {
+	BizID      string            `json:"biz_id"`
+	Status     int8              `json:"status"`
+	StatusText string            `json:"status_text"`
+	Candidates []*MatchCandidate `json:"candidates"`
+}
+
+// AcceptMatchRequest 提出配对请求
+type AcceptMatchRequest struct {
+	BizID             string `json:"biz_id" binding:"required"`
+	CounterpartyBizID string `json:"counterparty_biz_id" binding:"required"`
+	AdjustmentType    string `json:"adjustment_type" binding:"required,oneof=fee fx_rounding timing other"`
+	Reason            string `json:"reason" binding:"required,max=512"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"time"
+)
+
+// MismatchDiagnosis 对账失败字段诊断表
+// 合约判定对账失败后, 双方各自以一次性私钥对金额、币种、付款方、收款方、交易类型做可交换盲化并交换,
+// 再以本方私钥对对方的盲化值二次盲化; 比对二次盲化值即可得知哪些字段不一致,
+// 任何一方都拿不到对方的字段明文, 也不能离线穷举对方的盲化值
+type MismatchDiagnosis struct {
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	BizID                string     `json:"biz_id" gorm:"uniqueIndex;size:64;comment:业务流水号"`
+	InstitutionID        string     `json:"institution_id" gorm:"index;size:64;comment:机构ID"`
+	Counterparty         string     `json:"counterparty" gorm:"index;size:64;comment:对手机构ID"`
+	Role                 string     `json:"role" gorm:"size:16;comment:角色"`
+	KeyCipher            string     `json:"-" gorm:"size:256;comment:本方诊断私钥密文"`
+	KeyID                string     `json:"key_id" gorm:"size:16;comment:诊断会话标识(发起方私钥指纹)"`
+	LocalAmountHash      string     `json:"local_amount_hash" gorm:"size:64;comment:本方金额盲化值"`
+	LocalSenderHash      string     `json:"local_sender_hash" gorm:"size:64;comment:本方付款方盲化值"`
+	LocalReceiverHash    string     `json:"local_receiver_hash" gorm:"size:64;comment:本方收款方盲化值"`
+	LocalTypeHash        string     `json:"local_type_hash" gorm:"size:64;comment:本方交易类型盲化值"`
+	LocalCurrencyHash    string     `json:"local_currency_hash" gorm:"size:64;comment:本方币种盲化值"`
+	RemoteAmountHash     string     `json:"remote_amount_hash,omitempty" gorm:"size:64;comment:对手方金额二次盲化值(本方计算)"`
+	RemoteSenderHash     string     `json:"remote_sender_hash,omitempty" gorm:"size:64;comment:对手方付款方二次盲化值(本方计算)"`
+	RemoteReceiverHash   string     `json:"remote_receiver_hash,omitempty" gorm:"size:64;comment:对手方收款方二次盲化值(本方计算)"`
+	RemoteTypeHash       string     `json:"remote_type_hash,omitempty" gorm:"size:64;comment:对手方交易类型二次盲化值(本方计算)"`
+	RemoteCurrencyHash   string     `json:"remote_currency_hash,omitempty" gorm:"size:64;comment:对手方币种二次盲化值(本方计算)"`
+	ReturnedAmountHash   string     `json:"returned_amount_hash,omitempty" gorm:"size:64;comment:本方金额二次盲化值(对手方回传)"`
+	ReturnedSenderHash   string     `json:"returned_sender_hash,omitempty" gorm:"size:64;comment:本方付款方二次盲化值(对手方回传)"`
+	ReturnedReceiverHash string     `json:"returned_receiver_hash,omitempty" gorm:"size:64;comment:本方收款方二次盲化值(对手方回传)"`
+	ReturnedTypeHash     string     `json:"returned_type_hash,omitempty" gorm:"size:64;comment:本方交易类型二次盲化值(对手方回传)"`
+	ReturnedCurrencyHash string     `json:"returned_currency_hash,omitempty" gorm:"size:64;comment:本方币种二次盲化值(对手方回传)"`
+	Status               int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	CompletedAt          *time.Time `json:"completed_at,omitempty" gorm:"comment:完成比对时间"`
+	CreatedAt            time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (MismatchDiagnosis) TableName() string {
+	return "mismatch_diagnoses"
+}
+
+// DiagnosisRole 诊断角色常量
+const (
+	DiagnosisRoleInitiator = "initiator" // 发起方: 先发出本方盲化值
+	DiagnosisRoleResponder = "responder" // 响应方: 收到发起方盲化值后参与
+)
+
+// DiagnosisStatus 诊断状态常量
+const (
+	DiagnosisStatusAwaiting  int8 = 0 // 等待对手方承诺
+	DiagnosisStatusCompleted int8 = 1 // 已完成比对
+)
+
+// DiagnosisField 参与诊断的字段
+const (
+	DiagnosisFieldAmount   = "amount"
+	DiagnosisFieldSender   = "sender"
+	DiagnosisFieldReceiver = "receiver"
+	DiagnosisFieldTxType   = "tx_type"
+	DiagnosisFieldCurrency = "currency"
+)
+
+// GetStatusText 获取状态文本
+func (d *MismatchDiagnosis) GetStatusText() string {
+	switch d.Status {
+	case DiagnosisStatusAwaiting:
+		return "等待对手方"
+	case DiagnosisStatusCompleted:
+		return "已完成"
+	default:
+		return "未知"
+	}
+}
+
+// FieldCommitments 各字段的盲化值
+type FieldCommitments struct {
+	Amount   string `json:"amount" binding:"required,len=64,hexadecimal"`
+	Sender   string `json:"sender" binding:"required,len=64,hexadecimal"`
+	Receiver string `json:"receiver" binding:"required,len=64,hexadecimal"`
+	TxType   string `json:"tx_type" binding:"required,len=64,hexadecimal"`
+	Currency string `json:"currency,omitempty" binding:"omitempty,len=64,hexadecimal"` // 未携带时不比对币种
+}
+
+// DiagnosisPacket 诊断交换包, 不携带任何私钥
+// 交换顺序: 发起方发出本方盲化值 → 响应方回传本方盲化值及对发起方盲化值的二次盲化 → 发起方回传对响应方盲化值的二次盲化
+type DiagnosisPacket struct {
+	Version       int               `json:"version"`
+	BizID         string            `json:"biz_id" binding:"required"`
+	InstitutionID string            `json:"institution_id" binding:"required"`
+	KeyID         string            `json:"key_id" binding:"required"`
+	Commitments   FieldCommitments  `json:"commitments" binding:"required"` // 发送方字段盲化值
+	Blinded       *FieldCommitments `json:"blinded,omitempty"`              // 发送方对接收方盲化值的二次盲化, 发起方的首个交换包不携带
+}
+
+// StartDiagnosisRequest 发起诊断请求
+type StartDiagnosisRequest struct {
+	BizID        string `json:"biz_id" binding:"required"`
+	Counterparty string `json:"counterparty"`
+}
+
+// FieldDiff 字段比对结果
+type FieldDiff struct {
+	Field   string `json:"field"`
+	Matched bool   `json:"matched"`
+}
+
+// DiagnosisResponse 诊断响应
+type DiagnosisResponse struct {
+	*MismatchDiagnosis
+	StatusText string           `json:"status_text"`
+	Packet     *DiagnosisPacket `json:"packet"`                // 发送给对手方的交换包
+	Fields     []FieldDiff      `json:"fields,omitempty"`      // 完成比对后返回
+	DiffFields []string         `json:"diff_fields,omitempty"` // 不一致的字段
+}
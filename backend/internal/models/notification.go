@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationPreference 邮件通知订阅偏好表
+// 邮件发送到 users.email, 未设置偏好的用户不接收邮件
+type NotificationPreference struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"user_id" gorm:"uniqueIndex;comment:用户ID"`
+	Language          string     `json:"language" gorm:"size:8;default:zh;comment:邮件语言(zh/en)"`
+	ImmediateMismatch bool       `json:"immediate_mismatch" gorm:"comment:对账失败时立即通知"`
+	DailyDigest       bool       `json:"daily_digest" gorm:"comment:接收每日摘要"`
+	LastDigestAt      *time.Time `json:"last_digest_at,omitempty" gorm:"comment:最近一次摘要截止时间"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// Notification 邮件语言常量
+const (
+	NotificationLanguageZH = "zh" // 中文
+	NotificationLanguageEN = "en" // 英文
+)
+
+// UpdateNotificationPreferenceRequest 更新通知偏好请求, 未提供的字段保持不变
+type UpdateNotificationPreferenceRequest struct {
+	Language          string `json:"language" binding:"omitempty,oneof=zh en"`
+	ImmediateMismatch *bool  `json:"immediate_mismatch"`
+	DailyDigest       *bool  `json:"daily_digest"`
+}
+
+// NotificationPreferenceResponse 通知偏好响应
+type NotificationPreferenceResponse struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	*NotificationPreference
+}
+
+// DigestMismatch 摘要中的对账失败记录
+type DigestMismatch struct {
+	BizID     string    `json:"biz_id"`
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DigestFailedSubmission 摘要中的上链失败记录
+type DigestFailedSubmission struct {
+	BizID    string    `json:"biz_id"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Digest 每日摘要内容
+type Digest struct {
+	InstitutionID     string                    `json:"institution_id"`
+	Since             time.Time                 `json:"since"`
+	Until             time.Time                 `json:"until"`
+	MismatchCount     int64                     `json:"mismatch_count"`
+	Mismatches        []*DigestMismatch         `json:"mismatches"` // 最多列出前若干条
+	OverdueCount      int64                     `json:"overdue_count"`
+	SLADays           int                       `json:"sla_days"`
+	OverdueParties    []*AgingRow               `json:"overdue_parties"`
+	FailedCount       int64                     `json:"failed_count"`
+	FailedSubmissions []*DigestFailedSubmission `json:"failed_submissions"`
+}
+
+// Empty 摘要是否没有需要关注的内容
+func (d *Digest) Empty() bool {
+	return d.MismatchCount == 0 && d.OverdueCount == 0 && d.FailedCount == 0
+}
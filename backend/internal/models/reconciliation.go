@@ -14,6 +14,7 @@ type Reconciliation struct {
 	Status      int8      `json:"status" gorm:"index;comment:对账状态"`
 	MatchedAt   *time.Time `json:"matched_at,omitempty" gorm:"comment:对账时间"`
 	BlockHeight *int64     `json:"block_height,omitempty" gorm:"comment:区块高度"`
+	AdjustmentID *uint     `json:"adjustment_id,omitempty" gorm:"index;comment:容差匹配调整记录ID"`
 	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -27,6 +28,7 @@ func (Reconciliation) TableName() string {
 const (
 	ReconciliationStatusMatched  int8 = 2 // 对账成功
 	ReconciliationStatusMismatch int8 = 3 // 对账失败
+	ReconciliationStatusAdjusted int8 = 4 // 容差匹配(人工确认调整)
 )
 
 // GetStatusText 获取状态文本
@@ -36,6 +38,8 @@ func (r *Reconciliation) GetStatusText() string {
 		return "对账成功"
 	case ReconciliationStatusMismatch:
 		return "对账失败"
+	case ReconciliationStatusAdjusted:
+		return "容差匹配"
 	default:
 		return "未知"
 	}
@@ -51,6 +55,7 @@ type ReconciliationResponse struct {
 	StatusText  string     `json:"status_text"`
 	MatchedAt   *time.Time `json:"matched_at,omitempty"`
 	BlockHeight *int64     `json:"block_height,omitempty"`
+	AdjustmentID *uint     `json:"adjustment_id,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
 
@@ -65,6 +70,7 @@ func (r *Reconciliation) ToResponse() *ReconciliationResponse {
 		StatusText:  r.GetStatusText(),
 		MatchedAt:   r.MatchedAt,
 		BlockHeight: r.BlockHeight,
+		AdjustmentID: r.AdjustmentID,
 		CreatedAt:   r.CreatedAt,
 	}
 }
@@ -77,6 +83,7 @@ type StatisticsResponse struct {
 	MatchRate         float64 `json:"match_rate"` // 匹配率(百分比)
 	PendingCount      int64   `json:"pending_count"`
 	UploadedCount     int64   `json:"uploaded_count"`
+	SubmittedCount    int64   `json:"submitted_count"` // 已提交待回执确认
 }
 
 // DailyStatistics 每日统计
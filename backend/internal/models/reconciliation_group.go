@@ -0,0 +1,117 @@
+package models
+
+import (
+	"time"
+)
+
+// ReconciliationGroup 对账组表
+// 一笔汇总款项对应对手方多笔明细(一对多), 或本方多笔明细对应对手方一笔汇总款项(多对一)时,
+// 双方以约定的组参考号为链上ID, 上传由明细承诺和合计金额计算的组承诺进行哈希碰撞对账
+type ReconciliationGroup struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	GroupRef          string     `json:"group_ref" gorm:"uniqueIndex:idx_institution_group_ref,priority:2;size:64;comment:组参考号(链上ID)"`
+	InstitutionID     string     `json:"institution_id" gorm:"uniqueIndex:idx_institution_group_ref,priority:1;size:64;comment:机构ID"`
+	Counterparty      string     `json:"counterparty" gorm:"index;size:64;comment:对手机构ID"`
+	GroupType         string     `json:"group_type" gorm:"size:16;comment:分组类型"`
+	ParentBizID       string     `json:"parent_biz_id,omitempty" gorm:"index;size:64;comment:本方汇总交易业务流水号"`
+	Currency          string     `json:"currency" gorm:"size:3;default:CNY;comment:币种(ISO 4217)"`
+	TotalCipher       string     `json:"-" gorm:"size:256;comment:合计金额密文"`
+	ItemCount         int        `json:"item_count" gorm:"comment:明细笔数"`
+	Commitment        string     `json:"commitment" gorm:"size:64;comment:组承诺(上链哈希)"`
+	TxHash            string     `json:"tx_hash,omitempty" gorm:"size:128;comment:上链交易哈希"`
+	BlockHeight       int64      `json:"block_height" gorm:"default:0;comment:上链区块高度"`
+	ChainCounterparty string     `json:"chain_counterparty,omitempty" gorm:"size:42;comment:链上对手方地址"`
+	MatchedAt         *time.Time `json:"matched_at,omitempty" gorm:"comment:对账时间"`
+	Status            int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ReconciliationGroup) TableName() string {
+	return "reconciliation_groups"
+}
+
+// ReconciliationGroupItem 对账组明细表
+type ReconciliationGroupItem struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	GroupID      uint   `json:"group_id" gorm:"index;comment:对账组ID"`
+	Seq          int    `json:"seq" gorm:"comment:序号"`
+	ChildRef     string `json:"child_ref" gorm:"size:64;comment:明细参考号"`
+	BizID        string `json:"biz_id,omitempty" gorm:"index;size:64;comment:关联的本方交易业务流水号"`
+	AmountCipher string `json:"-" gorm:"size:256;comment:明细金额密文"`
+	ItemHash     string `json:"item_hash" gorm:"size:64;comment:明细承诺"`
+}
+
+// TableName 指定表名
+func (ReconciliationGroupItem) TableName() string {
+	return "reconciliation_group_items"
+}
+
+// GroupType 分组类型常量
+const (
+	GroupTypeOneToMany = "one_to_many" // 本方一笔汇总交易对应多笔明细
+	GroupTypeManyToOne = "many_to_one" // 本方多笔明细交易对应对手方一笔汇总
+)
+
+// GroupStatus 对账组状态常量(与交易状态取值一致)
+const (
+	GroupStatusPending   int8 = 0 // 待上链
+	GroupStatusUploaded  int8 = 1 // 已上链
+	GroupStatusMatched   int8 = 2 // 对账成功
+	GroupStatusMismatch  int8 = 3 // 对账失败
+	GroupStatusSubmitted int8 = 4 // 上链中, 组承诺已提交等待回执
+)
+
+// GetStatusText 获取状态文本
+func (g *ReconciliationGroup) GetStatusText() string {
+	switch g.Status {
+	case GroupStatusPending:
+		return "待上链"
+	case GroupStatusUploaded:
+		return "已上链"
+	case GroupStatusMatched:
+		return "对账成功"
+	case GroupStatusMismatch:
+		return "对账失败"
+	case GroupStatusSubmitted:
+		return "上链中"
+	default:
+		return "未知"
+	}
+}
+
+// GroupItemRequest 对账组明细请求
+// 关联本方交易时填写 biz_id, 金额取自交易; 仅有对手方明细时填写 child_ref 和 amount
+type GroupItemRequest struct {
+	ChildRef string `json:"child_ref"`
+	BizID    string `json:"biz_id"`
+	Amount   string `json:"amount"`
+}
+
+// CreateGroupRequest 创建对账组请求
+type CreateGroupRequest struct {
+	GroupRef     string             `json:"group_ref" binding:"required"`
+	Counterparty string             `json:"counterparty"`
+	ParentBizID  string             `json:"parent_biz_id"`                       // 本方汇总交易, 为空表示多对一
+	Currency     string             `json:"currency"`                            // 币种(ISO 4217), 默认取关联交易币种, 均未关联时为CNY
+	GroupKey     string             `json:"group_key" binding:"required,min=16"` // 双方线下约定的组密钥, 用于计算承诺, 不落库
+	Items        []GroupItemRequest `json:"items" binding:"required,min=1"`
+}
+
+// GroupItemResponse 对账组明细响应
+type GroupItemResponse struct {
+	Seq      int    `json:"seq"`
+	ChildRef string `json:"child_ref"`
+	BizID    string `json:"biz_id,omitempty"`
+	Amount   string `json:"amount"`
+	ItemHash string `json:"item_hash"`
+}
+
+// GroupResponse 对账组响应
+type GroupResponse struct {
+	*ReconciliationGroup
+	StatusText  string               `json:"status_text"`
+	TotalAmount string               `json:"total_amount,omitempty"`
+	Items       []*GroupItemResponse `json:"items,omitempty"` // 明细拆分(详情返回)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// 统计周期常量
+const (
+	StatisticsPeriodDay     = "day"
+	StatisticsPeriodWeek    = "week" // 自然周(周一起)
+	StatisticsPeriodMonth   = "month"
+	StatisticsPeriodQuarter = "quarter"
+	StatisticsPeriodCustom  = "custom" // 自定义起止日期, 上期为紧邻的等长区间
+)
+
+// StatisticsPeriod 统计区间 [Start, End)
+type StatisticsPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CounterpartyMetrics 机构与对手方之间的对账指标(按交易创建时间归入统计区间)
+type CounterpartyMetrics struct {
+	Total           int64   `json:"total"`
+	Pending         int64   `json:"pending"`           // 待上链(含已提交待确认)
+	Uploaded        int64   `json:"uploaded"`          // 已上链(含已完成对账)
+	Matched         int64   `json:"matched"`           // 对账成功
+	Mismatched      int64   `json:"mismatched"`        // 对账失败
+	MatchRate       float64 `json:"match_rate"`        // 匹配率(百分比), 与概览统计口径一致
+	AvgMatchSeconds float64 `json:"avg_match_seconds"` // 平均对账耗时(秒): 本方上链至对账成功
+}
+
+// CounterpartyChange 环比变化, 变化率字段在上期为0时为空
+type CounterpartyChange struct {
+	Total           *float64 `json:"total"`             // 变化率(百分比)
+	Uploaded        *float64 `json:"uploaded"`          // 变化率(百分比)
+	Matched         *float64 `json:"matched"`           // 变化率(百分比)
+	Mismatched      *float64 `json:"mismatched"`        // 变化率(百分比)
+	Pending         *float64 `json:"pending"`           // 变化率(百分比)
+	MatchRate       float64  `json:"match_rate"`        // 匹配率变化(百分点)
+	AvgMatchSeconds *float64 `json:"avg_match_seconds"` // 变化率(百分比)
+}
+
+// CounterpartyMatrixCell 对手方矩阵单元格
+type CounterpartyMatrixCell struct {
+	InstitutionID string              `json:"institution_id"`
+	Counterparty  string              `json:"counterparty"` // 已登记机构取机构ID, 否则为付款方/收款方名称
+	Current       CounterpartyMetrics `json:"current"`
+	Previous      CounterpartyMetrics `json:"previous"`
+	Change        CounterpartyChange  `json:"change"`
+}
+
+// CounterpartyMatrix 对手方对账矩阵
+type CounterpartyMatrix struct {
+	Period         string                    `json:"period"`
+	Current        StatisticsPeriod          `json:"current"`
+	Previous       StatisticsPeriod          `json:"previous"`
+	Institutions   []string                  `json:"institutions"`   // 矩阵行
+	Counterparties []string                  `json:"counterparties"` // 矩阵列
+	Cells          []*CounterpartyMatrixCell `json:"cells"`          // 仅列出有交易的组合
+}
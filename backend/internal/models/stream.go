@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// StreamEvent 仪表板实时推送事件
+type StreamEvent struct {
+	Type          string      `json:"type"`
+	InstitutionID string      `json:"institution_id,omitempty"` // 为空表示推送给所有机构
+	Time          time.Time   `json:"time"`
+	Data          interface{} `json:"data"`
+}
+
+// StreamEvent 事件类型常量
+const (
+	StreamEventBlock      = "block"      // 新区块高度
+	StreamEventStatus     = "status"     // 交易状态变化
+	StreamEventStatistics = "statistics" // 统计数据增量
+)
+
+// BlockEventData 区块高度事件数据
+type BlockEventData struct {
+	BlockHeight int64 `json:"block_height"`
+}
+
+// StatusEventData 交易状态变化事件数据
+type StatusEventData struct {
+	BizID          string `json:"biz_id"`
+	PreviousStatus int8   `json:"previous_status"`
+	Status         int8   `json:"status"`
+	StatusText     string `json:"status_text"`
+}
+
+// StatisticsDelta 统计数据增量, 与 StatisticsResponse 中的计数一一对应
+type StatisticsDelta struct {
+	PendingCount   int64 `json:"pending_count"`
+	UploadedCount  int64 `json:"uploaded_count"`
+	MatchedCount   int64 `json:"matched_count"`
+	MismatchCount  int64 `json:"mismatch_count"`
+	SubmittedCount int64 `json:"submitted_count"`
+}
+
+// Add 按交易状态累加计数
+func (d *StatisticsDelta) Add(status int8, n int64) {
+	switch status {
+	case TxStatusPending:
+		d.PendingCount += n
+	case TxStatusUploaded:
+		d.UploadedCount += n
+	case TxStatusMatched:
+		d.MatchedCount += n
+	case TxStatusMismatch:
+		d.MismatchCount += n
+	case TxStatusSubmitted:
+		d.SubmittedCount += n
+	}
+}
@@ -18,16 +18,20 @@ type PageResponse struct {
 type Transaction struct {
 	ID             uint      `json:"id" gorm:"primaryKey"`
 	BizID          string    `json:"biz_id" gorm:"uniqueIndex;size:64;comment:业务流水号"`
-	InstitutionID  string    `json:"institution_id" gorm:"index;size:64;comment:机构ID"`
+	InstitutionID  string    `json:"institution_id" gorm:"index;index:idx_institution_amount,priority:1;index:idx_institution_created,priority:1;size:64;comment:机构ID"`
 	AmountCipher   string    `json:"amount_cipher" gorm:"size:256;comment:金额密文"`
-	AmountHash     string    `json:"amount_hash" gorm:"size:64;comment:金额哈希"`
+	Currency       string    `json:"currency" gorm:"size:3;index;default:CNY;comment:币种(ISO 4217)"`
+	AmountHash     string    `json:"-" gorm:"index:idx_institution_amount,priority:2;size:64;comment:金额盲索引(HMAC-SHA256)"` // 不暴露给前端
 	DataHash       string    `json:"data_hash" gorm:"index;size:64;comment:数据哈希"`
 	Salt           string    `json:"-" gorm:"size:64;comment:随机盐"` // 不暴露给前端
 	Receiver       string    `json:"receiver" gorm:"size:128;comment:收款方"`
 	Sender         string    `json:"sender" gorm:"size:128;comment:付款方"`
 	TxType         int8      `json:"tx_type" gorm:"default:1;comment:交易类型"`
+	TxDate         *time.Time `json:"tx_date,omitempty" gorm:"type:date;index;comment:交易日期"`
+	BatchID        *uint     `json:"batch_id,omitempty" gorm:"index;comment:导入批次ID"`
+	GroupID        *uint     `json:"group_id,omitempty" gorm:"index;comment:对账组ID"`
 	Status         int8      `json:"status" gorm:"index;default:0;comment:状态"`
-	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_institution_created,priority:2"` // 与主键组成列表游标分页的排序键
 	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// 关联
@@ -46,6 +50,7 @@ const (
 	TxStatusUploaded   int8 = 1 // 已上链
 	TxStatusMatched    int8 = 2 // 对账成功
 	TxStatusMismatch   int8 = 3 // 对账失败
+	TxStatusSubmitted  int8 = 4 // 已提交待确认, 仅本地使用, 回执确认后转为已上链, 过期后退回待上链
 )
 
 // BeforeCreate 创建前钩子
@@ -59,14 +64,48 @@ type CreateTransactionRequest struct {
 	BizID         string `json:"biz_id" binding:"required"`
 	InstitutionID string `json:"institution_id" binding:"required"`
 	Amount        string `json:"amount" binding:"required"` // 明文金额,后端加密
+	Currency      string `json:"currency"`                   // 币种(ISO 4217, 默认CNY)
 	Receiver      string `json:"receiver" binding:"required"`
 	Sender        string `json:"sender" binding:"required"`
 	TxType        int8   `json:"tx_type"`
+	TxDate        string `json:"tx_date"` // 交易日期(YYYY-MM-DD, 可选)
 }
 
 // UploadChainRequest 上链请求
 type UploadChainRequest struct {
 	BizIDs []string `json:"biz_ids" binding:"required"`
+	DryRun bool     `json:"dry_run"` // 仅预检, 不上链
+}
+
+// TransactionListQuery 交易列表查询条件, 指针字段为 nil 表示不过滤
+type TransactionListQuery struct {
+	InstitutionID string
+	Status        *int8
+	TxType        *int8
+	DateFrom      *time.Time // 交易日期起(含)
+	DateTo        *time.Time // 交易日期止(含)
+	Sender        string
+	Receiver      string
+	Counterparty  string // 付款方或收款方
+	BizIDPrefix   string
+	TxHash        string // 链上交易哈希
+	BlockFrom     *int64 // 上链区块高度起(含)
+	BlockTo       *int64 // 上链区块高度止(含)
+	SortBy        string // 排序字段, 见 TransactionSortFields
+	Desc          bool
+	Cursor        string // 上一页返回的 next_cursor, 为空查询第一页
+	Size          int
+}
+
+// TransactionSortFields 交易列表可排序字段
+var TransactionSortFields = []string{"created_at", "updated_at", "tx_date", "biz_id", "status", "block_height", "id"}
+
+// CursorPageResponse 游标分页响应
+type CursorPageResponse struct {
+	Data       interface{} `json:"data"`
+	Size       int         `json:"size"`
+	HasMore    bool        `json:"has_more"`
+	NextCursor string      `json:"next_cursor,omitempty"` // 作为 cursor 参数查询下一页
 }
 
 // TransactionResponse 交易响应
@@ -74,9 +113,13 @@ type TransactionResponse struct {
 	ID            uint      `json:"id"`
 	BizID         string    `json:"biz_id"`
 	InstitutionID string    `json:"institution_id"`
+	Currency      string    `json:"currency"`
 	Receiver      string    `json:"receiver"`
 	Sender        string    `json:"sender"`
 	TxType        int8      `json:"tx_type"`
+	TxDate        *time.Time `json:"tx_date,omitempty"`
+	BatchID       *uint     `json:"batch_id,omitempty"`
+	GroupID       *uint     `json:"group_id,omitempty"`
 	Status        int8      `json:"status"`
 	StatusText    string    `json:"status_text"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -95,6 +138,8 @@ func (t *Transaction) GetStatusText() string {
 		return "对账成功"
 	case TxStatusMismatch:
 		return "对账失败"
+	case TxStatusSubmitted:
+		return "已提交"
 	default:
 		return "未知"
 	}
@@ -106,9 +151,13 @@ func (t *Transaction) ToResponse() *TransactionResponse {
 		ID:            t.ID,
 		BizID:         t.BizID,
 		InstitutionID: t.InstitutionID,
+		Currency:      t.Currency,
 		Receiver:      t.Receiver,
 		Sender:        t.Sender,
 		TxType:        t.TxType,
+		TxDate:        t.TxDate,
+		BatchID:       t.BatchID,
+		GroupID:       t.GroupID,
 		Status:        t.Status,
 		StatusText:    t.GetStatusText(),
 		CreatedAt:     t.CreatedAt,
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// WebhookSubscription Webhook订阅表
+// 机构订阅对账状态变化事件, 事件以 HMAC-SHA256 签名的 JSON 推送到订阅地址
+type WebhookSubscription struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	InstitutionID string    `json:"institution_id" gorm:"index;size:64;comment:机构ID"`
+	URL           string    `json:"url" gorm:"size:512;comment:推送地址"`
+	EventTypes    string    `json:"-" gorm:"size:255;comment:订阅事件类型(逗号分隔)"`
+	SecretCipher  string    `json:"-" gorm:"size:512;comment:签名密钥密文"`
+	Description   string    `json:"description" gorm:"size:255;comment:说明"`
+	Enabled       bool      `json:"enabled" gorm:"default:true;comment:是否启用"`
+	CreatedBy     string    `json:"created_by" gorm:"size:64;comment:创建人"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Events 订阅的事件类型列表
+func (w *WebhookSubscription) Events() []string {
+	if w.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(w.EventTypes, ",")
+}
+
+// Subscribes 是否订阅了指定事件
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, e := range w.Events() {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook 事件类型常量
+const (
+	WebhookEventMatched  = "reconciliation.matched"  // 对账成功
+	WebhookEventMismatch = "reconciliation.mismatch" // 对账失败
+	WebhookEventTest     = "webhook.test"            // 测试推送
+)
+
+// WebhookEventTypes 可订阅的事件类型
+var WebhookEventTypes = []string{WebhookEventMatched, WebhookEventMismatch}
+
+// WebhookDelivery Webhook推送记录表
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"index;comment:订阅ID"`
+	InstitutionID  string     `json:"institution_id" gorm:"index;size:64;comment:机构ID"`
+	EventID        string     `json:"event_id" gorm:"index;size:64;comment:事件ID"`
+	EventType      string     `json:"event_type" gorm:"size:64;comment:事件类型"`
+	BizID          string     `json:"biz_id,omitempty" gorm:"index;size:64;comment:业务流水号"`
+	Payload        string     `json:"payload" gorm:"type:text;comment:推送内容"`
+	Status         int8       `json:"status" gorm:"index;default:0;comment:状态"`
+	Attempts       int        `json:"attempts" gorm:"default:0;comment:已尝试次数"`
+	ResponseCode   int        `json:"response_code" gorm:"comment:最近一次响应码"`
+	LastError      string     `json:"last_error,omitempty" gorm:"size:512;comment:最近一次错误"`
+	NextRetryAt    *time.Time `json:"next_retry_at,omitempty" gorm:"index;comment:下次重试时间"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" gorm:"comment:推送成功时间"`
+	ReplayOf       *uint      `json:"replay_of,omitempty" gorm:"comment:重放的原推送记录ID"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookDeliveryStatus 推送状态常量
+const (
+	WebhookDeliveryPending    int8 = 0 // 待推送(含等待重试)
+	WebhookDeliveryDelivering int8 = 1 // 推送中
+	WebhookDeliverySucceeded  int8 = 2 // 推送成功
+	WebhookDeliveryFailed     int8 = 3 // 重试耗尽, 推送失败
+)
+
+// GetStatusText 获取状态文本
+func (d *WebhookDelivery) GetStatusText() string {
+	switch d.Status {
+	case WebhookDeliveryPending:
+		return "待推送"
+	case WebhookDeliveryDelivering:
+		return "推送中"
+	case WebhookDeliverySucceeded:
+		return "推送成功"
+	case WebhookDeliveryFailed:
+		return "推送失败"
+	default:
+		return "未知"
+	}
+}
+
+// WebhookEvent 推送给订阅方的事件内容
+type WebhookEvent struct {
+	ID            string      `json:"id"`
+	Type          string      `json:"type"`
+	InstitutionID string      `json:"institution_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Data          interface{} `json:"data"`
+}
+
+// ReconciliationEventData 对账状态变化事件数据(不含金额明文)
+type ReconciliationEventData struct {
+	BizID          string     `json:"biz_id"`
+	Status         int8       `json:"status"`
+	StatusText     string     `json:"status_text"`
+	PreviousStatus int8       `json:"previous_status"`
+	Currency       string     `json:"currency"`
+	TxDate         *time.Time `json:"tx_date,omitempty"`
+	DataHash       string     `json:"data_hash"`
+}
+
+// CreateWebhookRequest 创建订阅请求
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=512"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=128"` // 为空时自动生成
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// UpdateWebhookRequest 更新订阅请求, 未提供的字段保持不变
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url" binding:"omitempty,url,max=512"`
+	EventTypes  []string `json:"event_types"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// WebhookResponse 订阅响应, 签名密钥仅在创建时返回
+type WebhookResponse struct {
+	*WebhookSubscription
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"`
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAgingAlertNotFound 告警不存在
+	ErrAgingAlertNotFound = errors.New("aging alert not found")
+	// ErrAgingAlertResolved 告警已解除
+	ErrAgingAlertResolved = errors.New("aging alert already resolved")
+)
+
+// AgingOptions 账龄参数
+type AgingOptions struct {
+	SLADays        int   // 上链后超过该天数视为逾期
+	Buckets        []int // 账龄分段边界(天), 升序
+	AlertThreshold int   // 单个对手方逾期笔数告警阈值
+}
+
+// withDefaults 补齐未配置的账龄参数
+func (o AgingOptions) withDefaults() AgingOptions {
+	if o.SLADays <= 0 {
+		o.SLADays = 3
+	}
+	if len(o.Buckets) == 0 {
+		o.Buckets = []int{1, 3, 7, 14, 30}
+	}
+	o.Buckets = append([]int(nil), o.Buckets...)
+	sort.Ints(o.Buckets)
+	if o.AlertThreshold <= 0 {
+		o.AlertThreshold = 10
+	}
+	return o
+}
+
+// bucketLabels 账龄分段名称, 如 <1天, 1-3天, ..., >=30天
+func (o AgingOptions) bucketLabels() []string {
+	labels := make([]string, 0, len(o.Buckets)+1)
+	for i, b := range o.Buckets {
+		if i == 0 {
+			labels = append(labels, fmt.Sprintf("<%d天", b))
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%d-%d天", o.Buckets[i-1], b))
+	}
+	return append(labels, fmt.Sprintf(">=%d天", o.Buckets[len(o.Buckets)-1]))
+}
+
+// bucketIndex 账龄所在分段
+func (o AgingOptions) bucketIndex(ageDays int) int {
+	for i, b := range o.Buckets {
+		if ageDays < b {
+			return i
+		}
+	}
+	return len(o.Buckets)
+}
+
+// AgingService 单边上链账龄服务
+type AgingService struct {
+	db      *gorm.DB
+	options AgingOptions
+	logger  *zap.Logger
+}
+
+// NewAgingService 创建账龄服务
+func NewAgingService(db *gorm.DB, options AgingOptions, logger *zap.Logger) *AgingService {
+	return &AgingService{
+		db:      db,
+		options: options.withDefaults(),
+		logger:  logger,
+	}
+}
+
+// agingRecord 单边上链记录
+type agingRecord struct {
+	BizID      string
+	Sender     string
+	Receiver   string
+	UploadedAt time.Time
+}
+
+// ownNames 本机构的标识(机构ID及登记的机构名称), 用于从付款方/收款方中识别对手方
+func (s *AgingService) ownNames(institutionID string) (map[string]bool, error) {
+	names := map[string]bool{utils.NormalizeParty(institutionID): true}
+
+	var institution models.Institution
+	err := s.db.Where("institution_id = ?", institutionID).First(&institution).Error
+	switch {
+	case err == nil:
+		names[utils.NormalizeParty(institution.Name)] = true
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to get institution: %w", err)
+	}
+	return names, nil
+}
+
+// counterpartyOf 识别对手方: 付款方为本机构时取收款方, 否则取付款方
+func counterpartyOf(rec *agingRecord, own map[string]bool) string {
+	if own[utils.NormalizeParty(rec.Sender)] {
+		return strings.TrimSpace(rec.Receiver)
+	}
+	return strings.TrimSpace(rec.Sender)
+}
+
+// ageDays 上链至 asOf 的整天数
+func ageDays(uploadedAt, asOf time.Time) int {
+	if asOf.Before(uploadedAt) {
+		return 0
+	}
+	return int(asOf.Sub(uploadedAt).Hours() / 24)
+}
+
+// items 加载机构已上链、对手方未上链且未经容差匹配确认的记录
+// 上链时间取链上回执的写入时间, 缺少回执时取交易更新时间
+func (s *AgingService) items(institutionID, counterparty string, asOf time.Time) ([]*models.AgingItem, error) {
+	own, err := s.ownNames(institutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	adjusted := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.MatchAdjustment{})
+	rows, err := s.db.Table("transactions t").
+		Joins("LEFT JOIN chain_receipts cr ON cr.biz_id = t.biz_id").
+		Select("t.biz_id, t.sender, t.receiver, COALESCE(cr.created_at, t.updated_at) AS uploaded_at").
+		Where("t.institution_id = ? AND t.status = ?", institutionID, models.TxStatusUploaded).
+		Where("t.biz_id NOT IN (?)", adjusted.Select("biz_id")).
+		Where("t.biz_id NOT IN (?)", adjusted.Select("counterparty_biz_id")).
+		Order("t.id").
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uploaded transactions: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*models.AgingItem, 0)
+	for rows.Next() {
+		var rec agingRecord
+		if err := s.db.ScanRows(rows, &rec); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		cp := counterpartyOf(&rec, own)
+		if counterparty != "" && !strings.EqualFold(cp, counterparty) {
+			continue
+		}
+		age := ageDays(rec.UploadedAt, asOf)
+		items = append(items, &models.AgingItem{
+			BizID:        rec.BizID,
+			Counterparty: cp,
+			UploadedAt:   rec.UploadedAt,
+			AgeDays:      age,
+			Overdue:      age >= s.options.SLADays,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transactions: %w", err)
+	}
+	return items, nil
+}
+
+// Report 按对手方统计单边上链记录的账龄分布
+func (s *AgingService) Report(institutionID, counterparty string, asOf time.Time) (*models.AgingReport, error) {
+	items, err := s.items(institutionID, counterparty, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := s.options.bucketLabels()
+	report := &models.AgingReport{
+		AsOf:           asOf,
+		SLADays:        s.options.SLADays,
+		AlertThreshold: s.options.AlertThreshold,
+		Rows:           make([]*models.AgingRow, 0),
+	}
+	byParty := make(map[string]*models.AgingRow)
+	for _, item := range items {
+		row, ok := byParty[item.Counterparty]
+		if !ok {
+			row = &models.AgingRow{Counterparty: item.Counterparty, Buckets: make([]*models.AgingBucket, len(labels))}
+			for i, label := range labels {
+				row.Buckets[i] = &models.AgingBucket{Label: label}
+			}
+			byParty[item.Counterparty] = row
+			report.Rows = append(report.Rows, row)
+		}
+
+		row.Total++
+		row.Buckets[s.options.bucketIndex(item.AgeDays)].Count++
+		if item.AgeDays > row.OldestDays {
+			row.OldestDays = item.AgeDays
+		}
+		if item.Overdue {
+			row.Overdue++
+		}
+	}
+
+	for _, row := range report.Rows {
+		row.Alert = row.Overdue >= int64(s.options.AlertThreshold)
+		report.Total += row.Total
+		report.Overdue += row.Overdue
+	}
+	// 逾期笔数多的对手方排在前面
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Overdue != report.Rows[j].Overdue {
+			return report.Rows[i].Overdue > report.Rows[j].Overdue
+		}
+		return report.Rows[i].Counterparty < report.Rows[j].Counterparty
+	})
+	return report, nil
+}
+
+// ListItems 查询单边上链明细, 按账龄倒序; overdueOnly 为 true 时仅返回逾期记录
+func (s *AgingService) ListItems(institutionID, counterparty string, overdueOnly bool, asOf time.Time, page, size int) (*models.PageResponse, error) {
+	items, err := s.items(institutionID, counterparty, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	if overdueOnly {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.Overdue {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].UploadedAt.Before(items[j].UploadedAt) })
+
+	total := len(items)
+	start := (page - 1) * size
+	if start > total {
+		start = total
+	}
+	end := start + size
+	if end > total {
+		end = total
+	}
+
+	return &models.PageResponse{
+		Total: int64(total),
+		Page:  page,
+		Size:  size,
+		Data:  items[start:end],
+	}, nil
+}
+
+// CheckAlerts 检查各机构的逾期情况并维护告警
+// 对手方逾期笔数达到阈值时新建告警, 已有告警则刷新笔数; 回落到阈值以下时解除告警
+// 返回本次新建的告警
+func (s *AgingService) CheckAlerts(asOf time.Time) ([]*models.AgingAlert, error) {
+	var institutions []string
+	if err := s.db.Model(&models.Transaction{}).
+		Where("status = ?", models.TxStatusUploaded).
+		Distinct().
+		Pluck("institution_id", &institutions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list institutions: %w", err)
+	}
+
+	// 已有告警但已无单边记录的机构也需要解除告警
+	var alerted []string
+	if err := s.db.Model(&models.AgingAlert{}).
+		Where("status <> ?", models.AgingAlertStatusResolved).
+		Distinct().
+		Pluck("institution_id", &alerted).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	seen := make(map[string]bool, len(institutions))
+	for _, inst := range institutions {
+		seen[inst] = true
+	}
+	for _, inst := range alerted {
+		if !seen[inst] {
+			institutions = append(institutions, inst)
+		}
+	}
+
+	raised := make([]*models.AgingAlert, 0)
+	for _, institutionID := range institutions {
+		created, err := s.checkInstitution(institutionID, asOf)
+		if err != nil {
+			return raised, err
+		}
+		raised = append(raised, created...)
+	}
+	return raised, nil
+}
+
+// checkInstitution 维护单个机构的告警
+func (s *AgingService) checkInstitution(institutionID string, asOf time.Time) ([]*models.AgingAlert, error) {
+	report, err := s.Report(institutionID, "", asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []models.AgingAlert
+	if err := s.db.Where("institution_id = ? AND status <> ?", institutionID, models.AgingAlertStatusResolved).
+		Find(&active).Error; err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+	activeByParty := make(map[string]*models.AgingAlert, len(active))
+	for i := range active {
+		activeByParty[active[i].Counterparty] = &active[i]
+	}
+
+	raised := make([]*models.AgingAlert, 0)
+	for _, row := range report.Rows {
+		alert, exists := activeByParty[row.Counterparty]
+		if !row.Alert {
+			continue
+		}
+		delete(activeByParty, row.Counterparty)
+
+		if exists {
+			if err := s.db.Model(alert).Updates(map[string]interface{}{
+				"overdue_count": row.Overdue,
+				"oldest_days":   row.OldestDays,
+			}).Error; err != nil {
+				return nil, fmt.Errorf("failed to update alert: %w", err)
+			}
+			continue
+		}
+
+		alert = &models.AgingAlert{
+			InstitutionID: institutionID,
+			Counterparty:  row.Counterparty,
+			OverdueCount:  row.Overdue,
+			Threshold:     s.options.AlertThreshold,
+			OldestDays:    row.OldestDays,
+			Status:        models.AgingAlertStatusOpen,
+		}
+		if err := s.db.Create(alert).Error; err != nil {
+			return nil, fmt.Errorf("failed to create alert: %w", err)
+		}
+		raised = append(raised, alert)
+
+		s.logger.Warn("one-sided uploads overdue",
+			zap.String("institution", institutionID),
+			zap.String("counterparty", row.Counterparty),
+			zap.Int64("overdue", row.Overdue),
+			zap.Int("threshold", s.options.AlertThreshold),
+			zap.Int("oldest_days", row.OldestDays))
+	}
+
+	// 剩余的告警: 逾期笔数已回落到阈值以下
+	for _, alert := range activeByParty {
+		now := time.Now()
+		if err := s.db.Model(alert).Updates(map[string]interface{}{
+			"status":      models.AgingAlertStatusResolved,
+			"resolved_at": &now,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to resolve alert: %w", err)
+		}
+		s.logger.Info("aging alert resolved",
+			zap.String("institution", institutionID),
+			zap.String("counterparty", alert.Counterparty))
+	}
+
+	return raised, nil
+}
+
+// RunAlertChecks 按固定间隔执行告警检查, 直到 ctx 取消
+func (s *AgingService) RunAlertChecks(ctx context.Context, interval time.Duration) {
+	s.logger.Info("aging alert checker started", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("aging alert checker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.CheckAlerts(time.Now()); err != nil {
+				s.logger.Error("aging alert check failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ListAlerts 查询告警
+func (s *AgingService) ListAlerts(institutionID string, status *int8, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.AgingAlert{}).Where("institution_id = ?", institutionID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count alerts: %w", err)
+	}
+
+	var alerts []models.AgingAlert
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  alerts,
+	}, nil
+}
+
+// AcknowledgeAlert 确认告警, 逾期仍在时保持已确认状态直到解除
+func (s *AgingService) AcknowledgeAlert(institutionID string, id uint, operator string) (*models.AgingAlert, error) {
+	var alert models.AgingAlert
+	if err := s.db.Where("institution_id = ? AND id = ?", institutionID, id).First(&alert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgingAlertNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+	if alert.Status == models.AgingAlertStatusResolved {
+		return nil, ErrAgingAlertResolved
+	}
+
+	now := time.Now()
+	alert.Status = models.AgingAlertStatusAcknowledged
+	alert.AcknowledgedBy = operator
+	alert.AcknowledgedAt = &now
+	if err := s.db.Model(&alert).Updates(map[string]interface{}{
+		"status":          alert.Status,
+		"acknowledged_by": operator,
+		"acknowledged_at": &now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	return &alert, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultImportChunkSize = 1000 // 每次 INSERT 的行数
+	bizIDQueryChunkSize    = 5000 // 查重时每条 IN 查询的流水号数量, 避免超出 MySQL 占位符上限
+)
+
+// SetImportConcurrency 设置导入的并行度和分块大小, 非正数表示使用默认值
+func (s *TransactionService) SetImportConcurrency(workers, chunkSize int) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultImportChunkSize
+	}
+	s.importWorkers = workers
+	s.importChunkSize = chunkSize
+}
+
+// preparedRow 预处理后的导入行
+type preparedRow struct {
+	row utils.ExcelRow
+	tx  *models.Transaction
+	err error
+}
+
+// prepareTransactions 并行生成盐、数据哈希、金额密文和盲索引
+// 结果顺序与输入行一致, 单行失败记录在 err 中, 不影响其他行
+func (s *TransactionService) prepareTransactions(rows []utils.ExcelRow, institutionID string, batchID *uint) []preparedRow {
+	results := make([]preparedRow, len(rows))
+	instKey := utils.DeriveInstitutionKey(s.blindIndexKey, institutionID)
+
+	workers := s.importWorkers
+	if workers > len(rows) {
+		workers = len(rows)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				tx, err := s.buildTransaction(rows[i], institutionID, instKey, batchID)
+				results[i] = preparedRow{row: rows[i], tx: tx, err: err}
+			}
+		}()
+	}
+	for i := range rows {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// buildTransaction 由导入行构建交易记录(不写库)
+func (s *TransactionService) buildTransaction(row utils.ExcelRow, institutionID string, instKey []byte, batchID *uint) (*models.Transaction, error) {
+	var txDate *time.Time
+	if row.TxDate != "" {
+		d, err := time.ParseInLocation("2006-01-02", row.TxDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("交易日期格式错误, 应为 YYYY-MM-DD")
+		}
+		txDate = &d
+	}
+
+	salt, err := utils.GenerateRandomSalt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	amountCipher, err := utils.EncryptAmount(s.encryptionKey, row.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt amount: %w", err)
+	}
+
+	return &models.Transaction{
+		BizID:         row.BizID,
+		InstitutionID: institutionID,
+		AmountCipher:  amountCipher,
+		Currency:      row.Currency,
+		AmountHash:    utils.AmountBlindIndex(instKey, row.Amount),
+		DataHash:      utils.CalculateDataHash(row.BizID, row.Amount, row.Currency, salt),
+		Salt:          salt,
+		Receiver:      row.Receiver,
+		Sender:        row.Sender,
+		TxType:        row.TxType,
+		TxDate:        txDate,
+		BatchID:       batchID,
+		Status:        models.TxStatusPending,
+	}, nil
+}
+
+// bulkInsertTransactions 在一个数据库事务中分块写入交易, 任一块失败则整体回滚
+func (s *TransactionService) bulkInsertTransactions(txs []*models.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(txs, s.importChunkSize).Error; err != nil {
+			return fmt.Errorf("failed to insert transactions: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// benchRows 生成基准测试使用的导入行
+func benchRows(n int) []utils.ExcelRow {
+	rows := make([]utils.ExcelRow, n)
+	for i := range rows {
+		rows[i] = utils.ExcelRow{
+			RowNum:   i + 2,
+			BizID:    fmt.Sprintf("BENCH%08d", i),
+			Amount:   fmt.Sprintf("%d.%02d", 1000+i, i%100),
+			Sender:   "付款方",
+			Receiver: "收款方",
+			TxType:   1,
+			TxDate:   "2024-01-02",
+			Currency: "CNY",
+		}
+	}
+	return rows
+}
+
+// BenchmarkPrepareTransactions 导入预处理(盐、哈希、金额密文、盲索引)的吞吐量, 不依赖数据库
+// 完整导入(含查重和写库)的基准见 cmd/importbench
+func BenchmarkPrepareTransactions(b *testing.B) {
+	rows := benchRows(10000)
+
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			s := NewTransactionService(nil, nil, zap.NewNop(),
+				"0123456789abcdef0123456789abcdef", "bench-blind-index-key")
+			s.SetImportConcurrency(workers, 0)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, p := range s.prepareTransactions(rows, "BENCH", nil) {
+					if p.err != nil {
+						b.Fatalf("row %d: %v", p.row.RowNum, p.err)
+					}
+				}
+			}
+			b.ReportMetric(float64(len(rows)*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
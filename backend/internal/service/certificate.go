@@ -0,0 +1,475 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCertificateNotFound 对账证明不存在
+	ErrCertificateNotFound = errors.New("certificate not found")
+	// ErrNoMatchedTransactions 期间内无对账成功的交易
+	ErrNoMatchedTransactions = errors.New("no matched transactions in period")
+	// ErrSigningKeyMissing 未配置机构签名私钥
+	ErrSigningKeyMissing = errors.New("signing key not configured")
+)
+
+// 证明文档格式常量
+const (
+	CertificateFormatHTML = "html"
+	CertificateFormatPDF  = "pdf"
+)
+
+// CertificateService 对账证明服务
+type CertificateService struct {
+	db         *gorm.DB
+	blockchain *blockchain.Client
+	signingKey *ecdsa.PrivateKey
+	logger     *zap.Logger
+}
+
+// NewCertificateService 创建对账证明服务
+// signingKey 为机构的 secp256k1 私钥(hex), 为空或非法时只能查询和校验, 不能出具证明
+func NewCertificateService(db *gorm.DB, bc *blockchain.Client, signingKey string, logger *zap.Logger) *CertificateService {
+	s := &CertificateService{
+		db:         db,
+		blockchain: bc,
+		logger:     logger,
+	}
+
+	if signingKey != "" {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(signingKey, "0x"))
+		if err != nil {
+			logger.Warn("invalid certificate signing key", zap.Error(err))
+		} else {
+			s.signingKey = key
+			logger.Info("certificate signing key loaded",
+				zap.String("signer", crypto.PubkeyToAddress(key.PublicKey).Hex()))
+		}
+	}
+
+	return s
+}
+
+// CreateCertificate 出具对账证明
+// 汇总期间内与对手机构对账成功的交易, 计算规范化内容摘要并签名, 随后将摘要锚定上链
+// 锚定失败时证明仍会保存(状态为锚定失败), 可通过 AnchorCertificate 重试
+func (s *CertificateService) CreateCertificate(ctx context.Context, institutionID string, req *models.CreateCertificateRequest) (*models.CertificateResponse, error) {
+	if s.signingKey == nil {
+		return nil, ErrSigningKeyMissing
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", req.PeriodStart, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period_start: %w", err)
+	}
+	end, err := time.ParseInLocation("2006-01-02", req.PeriodEnd, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period_end: %w", err)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("period_end is before period_start")
+	}
+
+	items, err := s.matchedItems(institutionID, req.Counterparty, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNoMatchedTransactions
+	}
+
+	// 证明编号加锚定前缀作为链上ID, 需不超过32字节
+	randomID, err := utils.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+	certNo := "CERT" + time.Now().Format("20060102") + randomID[:12]
+
+	payload := &models.CertificatePayload{
+		CertNo:        certNo,
+		InstitutionID: institutionID,
+		Counterparty:  req.Counterparty,
+		PeriodStart:   start.Format("2006-01-02"),
+		PeriodEnd:     end.Format("2006-01-02"),
+		IssuedAt:      time.Now().Format(time.RFC3339),
+		Items:         items,
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode certificate payload: %w", err)
+	}
+
+	digest := sha256.Sum256(payloadJSON)
+	signature, err := crypto.Sign(digest[:], s.signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	cert := &models.ReconciliationCertificate{
+		CertNo:        certNo,
+		InstitutionID: institutionID,
+		Counterparty:  req.Counterparty,
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		ItemCount:     len(items),
+		Payload:       string(payloadJSON),
+		Digest:        hex.EncodeToString(digest[:]),
+		Signature:     hex.EncodeToString(signature),
+		Signer:        crypto.PubkeyToAddress(s.signingKey.PublicKey).Hex(),
+		Status:        models.CertificateStatusSigned,
+	}
+	if err := s.db.Create(cert).Error; err != nil {
+		return nil, fmt.Errorf("failed to save certificate: %w", err)
+	}
+
+	if err := s.anchor(ctx, cert); err != nil {
+		s.logger.Warn("failed to anchor certificate",
+			zap.String("cert_no", certNo),
+			zap.Error(err))
+	}
+
+	s.logger.Info("reconciliation certificate issued",
+		zap.String("cert_no", certNo),
+		zap.String("counterparty", req.Counterparty),
+		zap.Int("items", len(items)))
+
+	return s.toResponse(cert)
+}
+
+// matchedItems 查询期间内与对手机构对账成功的交易(按对账时间, 含首尾日期)
+func (s *CertificateService) matchedItems(institutionID, counterparty string, start, end time.Time) ([]models.CertificateItem, error) {
+	var records []struct {
+		BizID         string
+		TxHash        *string
+		ReceiptHeight *int64
+		MatchHeight   *int64
+		MatchedAt     *time.Time
+	}
+
+	err := s.db.Table("transactions t").
+		Select("t.biz_id, cr.tx_hash, cr.block_height AS receipt_height, r.block_height AS match_height, r.matched_at").
+		Joins("JOIN reconciliations r ON r.biz_id = t.biz_id").
+		Joins("LEFT JOIN chain_receipts cr ON cr.biz_id = t.biz_id").
+		Where("t.institution_id = ? AND t.status = ?", institutionID, models.TxStatusMatched).
+		Where("r.status = ?", models.ReconciliationStatusMatched).
+		Where("(r.party_a = ? OR r.party_b = ?)", counterparty, counterparty).
+		Where("r.matched_at >= ? AND r.matched_at < ?", start, end.AddDate(0, 0, 1)).
+		Order("t.biz_id").
+		Scan(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matched transactions: %w", err)
+	}
+
+	items := make([]models.CertificateItem, 0, len(records))
+	for _, r := range records {
+		item := models.CertificateItem{BizID: r.BizID}
+		if r.TxHash != nil {
+			item.TxHash = *r.TxHash
+		}
+		// 优先使用对账成功时的区块高度
+		if r.MatchHeight != nil {
+			item.BlockHeight = *r.MatchHeight
+		} else if r.ReceiptHeight != nil {
+			item.BlockHeight = *r.ReceiptHeight
+		}
+		if r.MatchedAt != nil {
+			item.MatchedAt = r.MatchedAt.Format(time.RFC3339)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// anchor 将证明摘要锚定上链并更新状态
+// 链上ID为锚定前缀加证明编号, 不占用业务流水号; 回执执行失败视为锚定失败, 区块高度取自回执
+func (s *CertificateService) anchor(ctx context.Context, cert *models.ReconciliationCertificate) error {
+	receipt, err := s.blockchain.UploadTransaction(ctx, blockchain.AnchorID(cert.CertNo), cert.Digest)
+	if err != nil {
+		s.db.Model(cert).Update("status", models.CertificateStatusAnchorFailed)
+		cert.Status = models.CertificateStatusAnchorFailed
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"anchor_tx_hash":      receipt.TxHash,
+		"anchor_block_height": receipt.BlockNumber,
+		"anchored_at":         now,
+		"status":              models.CertificateStatusAnchored,
+	}
+	if err := s.db.Model(cert).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update certificate: %w", err)
+	}
+
+	cert.AnchorTxHash = receipt.TxHash
+	cert.AnchorBlockHeight = receipt.BlockNumber
+	cert.AnchoredAt = &now
+	cert.Status = models.CertificateStatusAnchored
+	return nil
+}
+
+// AnchorCertificate 重新锚定未成功上链的证明
+func (s *CertificateService) AnchorCertificate(ctx context.Context, institutionID, certNo string) (*models.CertificateResponse, error) {
+	cert, err := s.getCertificate(institutionID, certNo)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Status == models.CertificateStatusAnchored {
+		return s.toResponse(cert)
+	}
+
+	if err := s.anchor(ctx, cert); err != nil {
+		return nil, fmt.Errorf("failed to anchor certificate: %w", err)
+	}
+	return s.toResponse(cert)
+}
+
+// getCertificate 查询证明, institutionID 为空时不限机构
+func (s *CertificateService) getCertificate(institutionID, certNo string) (*models.ReconciliationCertificate, error) {
+	var cert models.ReconciliationCertificate
+	query := s.db.Where("cert_no = ?", certNo)
+	if institutionID != "" {
+		query = query.Where("institution_id = ?", institutionID)
+	}
+	if err := query.First(&cert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// GetCertificate 查询证明详情(含交易明细)
+func (s *CertificateService) GetCertificate(institutionID, certNo string) (*models.CertificateResponse, error) {
+	cert, err := s.getCertificate(institutionID, certNo)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(cert)
+}
+
+// ListCertificates 查询机构出具的证明列表
+func (s *CertificateService) ListCertificates(institutionID, counterparty string, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.ReconciliationCertificate{}).Where("institution_id = ?", institutionID)
+	if counterparty != "" {
+		query = query.Where("counterparty = ?", counterparty)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count certificates: %w", err)
+	}
+
+	var certs []models.ReconciliationCertificate
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list certificates: %w", err)
+	}
+
+	list := make([]*models.CertificateResponse, 0, len(certs))
+	for i := range certs {
+		list = append(list, &models.CertificateResponse{
+			ReconciliationCertificate: &certs[i],
+			StatusText:                certs[i].GetStatusText(),
+		})
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  list,
+	}, nil
+}
+
+// toResponse 转换为响应格式并解析证明内容
+func (s *CertificateService) toResponse(cert *models.ReconciliationCertificate) (*models.CertificateResponse, error) {
+	var payload models.CertificatePayload
+	if err := json.Unmarshal([]byte(cert.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode certificate payload: %w", err)
+	}
+
+	return &models.CertificateResponse{
+		ReconciliationCertificate: cert,
+		StatusText:                cert.GetStatusText(),
+		Payload:                   &payload,
+	}, nil
+}
+
+// VerifyCertificate 校验对账证明
+// 依次校验: 存储内容摘要未被篡改、签名可恢复且为出具机构登记地址、链上锚定摘要一致;
+// digest 为调用方持有的证明文档上的摘要, 为空时不比对
+func (s *CertificateService) VerifyCertificate(ctx context.Context, certNo, digest string) (*models.CertificateVerifyResult, error) {
+	cert, err := s.getCertificate("", certNo)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CertificateVerifyResult{
+		CertNo:        cert.CertNo,
+		Digest:        cert.Digest,
+		DigestMatched: true,
+	}
+
+	computed := sha256.Sum256([]byte(cert.Payload))
+	result.DigestValid = hex.EncodeToString(computed[:]) == cert.Digest
+
+	if digest != "" {
+		result.DigestMatched = strings.EqualFold(strings.TrimPrefix(digest, "0x"), cert.Digest)
+	}
+
+	// 从签名恢复公钥, 与记录的签名地址及机构登记地址比对
+	if sig, err := hex.DecodeString(cert.Signature); err == nil {
+		if pub, err := crypto.SigToPub(computed[:], sig); err == nil {
+			result.Signer = crypto.PubkeyToAddress(*pub).Hex()
+			result.SignatureValid = strings.EqualFold(result.Signer, cert.Signer)
+		}
+	}
+	if result.SignatureValid {
+		var institution models.Institution
+		if err := s.db.Where("institution_id = ?", cert.InstitutionID).First(&institution).Error; err == nil {
+			result.SignerRegistered = common.HexToAddress(institution.Address) == common.HexToAddress(result.Signer)
+		}
+	}
+
+	// 链上锚定记录
+	info, err := s.blockchain.GetTransaction(ctx, blockchain.AnchorID(cert.CertNo))
+	if err != nil {
+		result.ChainError = err.Error()
+	} else {
+		result.ChainAnchored = hex.EncodeToString(info.DataHash[:]) == cert.Digest
+		result.ChainUploader = info.Uploader
+		if info.Timestamp != nil {
+			result.ChainTimestamp = info.Timestamp.Int64()
+		}
+	}
+
+	result.Valid = result.DigestValid && result.DigestMatched && result.SignatureValid &&
+		result.SignerRegistered && result.ChainAnchored
+	return result, nil
+}
+
+// RenderCertificate 渲染证明文档(html/pdf)
+func (s *CertificateService) RenderCertificate(institutionID, certNo, format string) ([]byte, error) {
+	resp, err := s.GetCertificate(institutionID, certNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == CertificateFormatPDF {
+		return renderCertificatePDF(resp), nil
+	}
+
+	var buf bytes.Buffer
+	if err := certificateTemplate.Execute(&buf, resp); err != nil {
+		return nil, fmt.Errorf("failed to render certificate: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderCertificatePDF 生成PDF版证明(内置字体仅支持ASCII, 使用英文标签)
+func renderCertificatePDF(resp *models.CertificateResponse) []byte {
+	p := resp.Payload
+	pdf := utils.NewTextPDF()
+
+	pdf.AddLine("RECONCILIATION CERTIFICATE", 16)
+	pdf.AddBlank(10)
+	pdf.AddLine("Certificate No : "+p.CertNo, 10)
+	pdf.AddLine("Issuer         : "+p.InstitutionID, 10)
+	pdf.AddLine("Counterparty   : "+p.Counterparty, 10)
+	pdf.AddLine("Period         : "+p.PeriodStart+" ~ "+p.PeriodEnd, 10)
+	pdf.AddLine("Issued At      : "+p.IssuedAt, 10)
+	pdf.AddLine(fmt.Sprintf("Transactions   : %d", len(p.Items)), 10)
+	pdf.AddBlank(10)
+
+	pdf.AddLine(fmt.Sprintf("%-4s %-32s %-10s %s", "#", "Biz ID", "Block", "Matched At"), 9)
+	for i, item := range p.Items {
+		pdf.AddLine(fmt.Sprintf("%-4d %-32s %-10d %s", i+1, item.BizID, item.BlockHeight, item.MatchedAt), 9)
+		if item.TxHash != "" {
+			pdf.AddLine("     tx: "+item.TxHash, 8)
+		}
+	}
+	pdf.AddBlank(10)
+
+	pdf.AddLine("Digest (SHA-256 of canonical payload):", 10)
+	pdf.AddLine(resp.Digest, 9)
+	pdf.AddLine("Signature (secp256k1):", 10)
+	pdf.AddLine(resp.Signature, 9)
+	pdf.AddLine("Signer: "+resp.Signer, 10)
+	pdf.AddBlank(10)
+
+	if resp.Status == models.CertificateStatusAnchored {
+		pdf.AddLine("On-chain anchor tx: "+resp.AnchorTxHash, 9)
+		pdf.AddLine(fmt.Sprintf("On-chain anchor block: %d", resp.AnchorBlockHeight), 9)
+	} else {
+		pdf.AddLine("On-chain anchor: pending", 9)
+	}
+	pdf.AddLine("Verify: GET /api/v1/certificates/"+p.CertNo+"/verify?digest=<digest>", 9)
+
+	return pdf.Bytes()
+}
+
+// certificateTemplate HTML版证明模板
+var certificateTemplate = template.Must(template.New("certificate").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="UTF-8">
+<title>对账证明 {{.CertNo}}</title>
+<style>
+body { font-family: "PingFang SC", "Microsoft YaHei", sans-serif; margin: 40px; color: #222; }
+h1 { text-align: center; }
+table { border-collapse: collapse; width: 100%; margin: 16px 0; }
+th, td { border: 1px solid #999; padding: 6px 8px; font-size: 13px; }
+th { background: #f0f0f0; }
+.mono { font-family: Consolas, monospace; word-break: break-all; }
+.meta td:first-child { width: 160px; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>对账证明</h1>
+<table class="meta">
+<tr><td>证明编号</td><td>{{.Payload.CertNo}}</td></tr>
+<tr><td>出具机构</td><td>{{.Payload.InstitutionID}}</td></tr>
+<tr><td>对手机构</td><td>{{.Payload.Counterparty}}</td></tr>
+<tr><td>对账期间</td><td>{{.Payload.PeriodStart}} 至 {{.Payload.PeriodEnd}}</td></tr>
+<tr><td>出具时间</td><td>{{.Payload.IssuedAt}}</td></tr>
+<tr><td>交易笔数</td><td>{{len .Payload.Items}}</td></tr>
+</table>
+<p>兹证明下列交易已与对手机构通过链上哈希碰撞完成对账, 双方数据一致。</p>
+<table>
+<tr><th>序号</th><th>业务流水号</th><th>交易哈希</th><th>区块高度</th><th>对账时间</th></tr>
+{{range $i, $item := .Payload.Items}}<tr><td>{{inc $i}}</td><td>{{$item.BizID}}</td><td class="mono">{{$item.TxHash}}</td><td>{{$item.BlockHeight}}</td><td>{{$item.MatchedAt}}</td></tr>
+{{end}}</table>
+<table class="meta">
+<tr><td>证明摘要(SHA-256)</td><td class="mono">{{.Digest}}</td></tr>
+<tr><td>机构签名</td><td class="mono">{{.Signature}}</td></tr>
+<tr><td>签名地址</td><td class="mono">{{.Signer}}</td></tr>
+<tr><td>锚定状态</td><td>{{.StatusText}}</td></tr>
+{{if .AnchorTxHash}}<tr><td>锚定交易哈希</td><td class="mono">{{.AnchorTxHash}}</td></tr>
+<tr><td>锚定区块高度</td><td>{{.AnchorBlockHeight}}</td></tr>{{end}}
+</table>
+<p>校验方式: GET /api/v1/certificates/{{.CertNo}}/verify?digest=证明摘要</p>
+</body>
+</html>
+`))
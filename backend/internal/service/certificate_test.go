@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+func TestCreateCertificateAfterChainMatch(t *testing.T) {
+	db := newTestDB(t, &models.Institution{}, &models.Transaction{}, &models.ChainReceipt{},
+		&models.Reconciliation{}, &models.ReconciliationCertificate{})
+
+	const (
+		addrA = "0x1111111111111111111111111111111111111111"
+		addrB = "0x2222222222222222222222222222222222222222"
+	)
+	for _, inst := range []models.Institution{
+		{InstitutionID: "INST001", Name: "机构A", Address: addrA},
+		{InstitutionID: "INST002", Name: "机构B", Address: addrB},
+	} {
+		if err := db.Create(&inst).Error; err != nil {
+			t.Fatalf("create institution: %v", err)
+		}
+	}
+
+	// 本方已上链, 等待对手方
+	tx := models.Transaction{BizID: "BIZ001", InstitutionID: "INST001", Status: models.TxStatusUploaded}
+	if err := db.Create(&tx).Error; err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if err := db.Create(&models.ChainReceipt{BizID: "BIZ001", TxHash: "0xabc", BlockHeight: 100}).Error; err != nil {
+		t.Fatalf("create receipt: %v", err)
+	}
+
+	// 对手方上链后链上对账成功, 同步到本地
+	logger := zap.NewNop()
+	txService := NewTransactionService(db, nil, logger, "0123456789abcdef0123456789abcdef", "test-blind-index-key")
+	changed, err := txService.ApplyChainStatus(&tx, &blockchain.TransactionInfo{
+		Uploader:     addrB,
+		Counterparty: addrA,
+		Status:       uint8(models.TxStatusMatched),
+		MatchHeight:  big.NewInt(120),
+	})
+	if err != nil || !changed {
+		t.Fatalf("ApplyChainStatus() = %v, %v, want true, nil", changed, err)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	// 未连接节点, 锚定失败但证明仍会保存
+	certService := NewCertificateService(db, &blockchain.Client{}, hex.EncodeToString(crypto.FromECDSA(key)), logger)
+
+	today := time.Now().Format("2006-01-02")
+	cert, err := certService.CreateCertificate(context.Background(), "INST001", &models.CreateCertificateRequest{
+		Counterparty: "INST002",
+		PeriodStart:  today,
+		PeriodEnd:    today,
+	})
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+
+	if cert.ItemCount != 1 || len(cert.Payload.Items) != 1 {
+		t.Fatalf("certificate items = %d, want 1", cert.ItemCount)
+	}
+	item := cert.Payload.Items[0]
+	if item.BizID != "BIZ001" || item.TxHash != "0xabc" || item.BlockHeight != 120 {
+		t.Errorf("certificate item = %+v, want BIZ001 / 0xabc / 120", item)
+	}
+	if cert.Status != models.CertificateStatusAnchorFailed {
+		t.Errorf("certificate status = %d, want anchor failed", cert.Status)
+	}
+}
@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrConsistencyCheckRunning 已有一致性检查在进行中
+	ErrConsistencyCheckRunning = errors.New("consistency check already running")
+	// ErrConsistencyRunNotFound 检查记录不存在
+	ErrConsistencyRunNotFound = errors.New("consistency run not found")
+	// ErrInvalidBizIDRange 业务流水号范围无效
+	ErrInvalidBizIDRange = errors.New("invalid biz id range")
+)
+
+// ConsistencyOptions 一致性检查参数
+type ConsistencyOptions struct {
+	AutoHeal  bool // 定时检查是否按链上状态修复本地状态
+	BatchSize int  // 每批读取的交易数
+}
+
+// withDefaults 补齐未配置的检查参数
+func (o ConsistencyOptions) withDefaults() ConsistencyOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 200
+	}
+	return o
+}
+
+// ConsistencyService 数据库与链上状态一致性检查服务
+// 逐笔读取合约记录, 比对数据哈希与状态并记录偏差; 自动修复只以链上状态为准更新本地状态, 不会改动链上数据
+type ConsistencyService struct {
+	db         *gorm.DB
+	blockchain *blockchain.Client
+	txService  *TransactionService
+	options    ConsistencyOptions
+	logger     *zap.Logger
+
+	running atomic.Bool // 同一时间只允许一次检查, 避免重复修复和重复通知
+}
+
+// NewConsistencyService 创建一致性检查服务
+func NewConsistencyService(db *gorm.DB, bc *blockchain.Client, txService *TransactionService, options ConsistencyOptions, logger *zap.Logger) *ConsistencyService {
+	return &ConsistencyService{
+		db:         db,
+		blockchain: bc,
+		txService:  txService,
+		options:    options.withDefaults(),
+		logger:     logger,
+	}
+}
+
+// MarkInterruptedRuns 将服务重启前未结束的检查标记为异常中止, 需在启动检查前调用
+func (s *ConsistencyService) MarkInterruptedRuns() error {
+	now := time.Now()
+	return s.db.Model(&models.ConsistencyRun{}).
+		Where("status = ?", models.ConsistencyRunStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      models.ConsistencyRunStatusFailed,
+			"last_error":  "服务重启, 检查中断",
+			"finished_at": &now,
+		}).Error
+}
+
+// StartCheck 手动发起一致性检查, 检查在后台执行, 返回新建的检查记录
+func (s *ConsistencyService) StartCheck(req *models.StartConsistencyCheckRequest, operator string) (*models.ConsistencyRun, error) {
+	from := strings.TrimSpace(req.BizIDFrom)
+	to := strings.TrimSpace(req.BizIDTo)
+	if from != "" && to != "" && from > to {
+		return nil, ErrInvalidBizIDRange
+	}
+	autoHeal := s.options.AutoHeal
+	if req.AutoHeal != nil {
+		autoHeal = *req.AutoHeal
+	}
+
+	run, err := s.begin(models.ConsistencyTriggerManual, from, to, autoHeal, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer s.running.Store(false)
+		s.execute(context.Background(), run)
+	}()
+	return run, nil
+}
+
+// RunScheduledChecks 按固定间隔执行全量一致性检查, 直到 ctx 取消
+func (s *ConsistencyService) RunScheduledChecks(ctx context.Context, interval time.Duration) {
+	s.logger.Info("consistency checker started", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("consistency checker stopped")
+			return
+		case <-ticker.C:
+			run, err := s.begin(models.ConsistencyTriggerScheduled, "", "", s.options.AutoHeal, "")
+			if errors.Is(err, ErrConsistencyCheckRunning) {
+				s.logger.Info("consistency check skipped, previous run still in progress")
+				continue
+			}
+			if err != nil {
+				s.logger.Error("failed to start consistency check", zap.Error(err))
+				continue
+			}
+			s.execute(ctx, run)
+			s.running.Store(false)
+		}
+	}
+}
+
+// begin 占用检查标记并创建检查记录
+func (s *ConsistencyService) begin(trigger, from, to string, autoHeal bool, operator string) (*models.ConsistencyRun, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, ErrConsistencyCheckRunning
+	}
+
+	run := &models.ConsistencyRun{
+		Trigger:   trigger,
+		BizIDFrom: from,
+		BizIDTo:   to,
+		AutoHeal:  autoHeal,
+		Status:    models.ConsistencyRunStatusRunning,
+		StartedBy: operator,
+		StartedAt: time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		s.running.Store(false)
+		return nil, fmt.Errorf("failed to create consistency run: %w", err)
+	}
+	return run, nil
+}
+
+// execute 分批检查范围内的交易, 每批结束后更新进度
+func (s *ConsistencyService) execute(ctx context.Context, run *models.ConsistencyRun) {
+	s.logger.Info("consistency check started",
+		zap.Uint("run_id", run.ID),
+		zap.String("trigger", run.Trigger),
+		zap.String("biz_id_from", run.BizIDFrom),
+		zap.String("biz_id_to", run.BizIDTo),
+		zap.Bool("auto_heal", run.AutoHeal))
+
+	account := s.blockchain.GetAccountAddress().Hex()
+	var lastID uint
+	var failure error
+	for failure == nil {
+		if err := ctx.Err(); err != nil {
+			failure = err
+			break
+		}
+
+		txs, err := s.nextBatch(run, lastID)
+		if err != nil {
+			failure = err
+			break
+		}
+		if len(txs) == 0 {
+			break
+		}
+		lastID = txs[len(txs)-1].ID
+
+		for i := range txs {
+			if ctx.Err() != nil {
+				break
+			}
+			s.checkTransaction(ctx, run, &txs[i], account)
+		}
+		if err := s.saveProgress(run); err != nil {
+			failure = err
+		}
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = models.ConsistencyRunStatusCompleted
+	if failure != nil {
+		run.Status = models.ConsistencyRunStatusFailed
+		run.LastError = truncate(failure.Error(), 512)
+	}
+	if err := s.db.Model(run).Updates(map[string]interface{}{
+		"status":      run.Status,
+		"checked":     run.Checked,
+		"drifted":     run.Drifted,
+		"healed":      run.Healed,
+		"errors":      run.Errors,
+		"last_error":  run.LastError,
+		"finished_at": run.FinishedAt,
+	}).Error; err != nil {
+		s.logger.Error("failed to finish consistency run", zap.Uint("run_id", run.ID), zap.Error(err))
+	}
+
+	s.logger.Info("consistency check finished",
+		zap.Uint("run_id", run.ID),
+		zap.Int8("status", run.Status),
+		zap.Int64("checked", run.Checked),
+		zap.Int64("drifted", run.Drifted),
+		zap.Int64("healed", run.Healed),
+		zap.Int64("errors", run.Errors))
+}
+
+// nextBatch 读取 lastID 之后的一批待检查交易
+// 检查已上链的交易, 以及本地仍为待上链但已有成功回执的交易(上链后状态更新失败);
+// 对账组内的交易以组承诺上链, 按所属对账组的链上记录比对; 已提交待确认的交易由回执追踪处理, 不参与检查;
+// 链上锚定记录(如对账证明)不是业务交易, 经容差匹配确认的交易本地状态与链上状态本就不同, 均不参与检查
+func (s *ConsistencyService) nextBatch(run *models.ConsistencyRun, lastID uint) ([]models.Transaction, error) {
+	query := s.db.Model(&models.Transaction{}).
+		Select("transactions.*").
+		Joins("LEFT JOIN chain_receipts cr ON cr.biz_id = transactions.biz_id").
+		Where("(transactions.status IN ? OR (transactions.status = ? AND cr.status = ?))",
+			[]int8{models.TxStatusUploaded, models.TxStatusMatched, models.TxStatusMismatch},
+			models.TxStatusPending, models.ChainReceiptStatusSuccess).
+		Where("transactions.biz_id NOT LIKE ?", blockchain.AnchorIDPrefix+"%").
+		Where("transactions.biz_id NOT IN (?)", s.db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.Reconciliation{}).Select("biz_id").
+			Where("status = ?", models.ReconciliationStatusAdjusted)).
+		Where("transactions.id > ?", lastID)
+	if run.BizIDFrom != "" {
+		query = query.Where("transactions.biz_id >= ?", run.BizIDFrom)
+	}
+	if run.BizIDTo != "" {
+		query = query.Where("transactions.biz_id <= ?", run.BizIDTo)
+	}
+
+	var txs []models.Transaction
+	if err := query.Order("transactions.id").Limit(s.options.BatchSize).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return txs, nil
+}
+
+// checkTransaction 比对单笔交易与链上记录
+// 链上只保存首个上链方的数据哈希, 因此仅在本方为首个上链方或对账已成功时比对哈希
+// 对账组内的交易以组参考号查询链上记录, 与组承诺比对
+func (s *ConsistencyService) checkTransaction(ctx context.Context, run *models.ConsistencyRun, tx *models.Transaction, account string) {
+	run.Checked++
+
+	chainID, localHash := tx.BizID, tx.DataHash
+	var group *models.ReconciliationGroup
+	if tx.GroupID != nil {
+		group = &models.ReconciliationGroup{}
+		if err := s.db.First(group, *tx.GroupID).Error; err != nil {
+			s.recordError(run, tx, fmt.Errorf("failed to load reconciliation group: %w", err))
+			return
+		}
+		chainID, localHash = group.GroupRef, group.Commitment
+	}
+
+	exists, err := s.blockchain.TxExists(ctx, chainID)
+	if err != nil {
+		s.recordError(run, tx, err)
+		return
+	}
+	if !exists {
+		run.Drifted++
+		s.saveDrift(&models.ConsistencyDrift{
+			RunID:         run.ID,
+			BizID:         tx.BizID,
+			InstitutionID: tx.InstitutionID,
+			DriftType:     models.DriftMissingOnChain,
+			LocalStatus:   tx.Status,
+			LocalHash:     localHash,
+			Detail:        "本地记录已上链, 合约中无该业务流水号",
+		})
+		return
+	}
+
+	info, err := s.blockchain.GetTransaction(ctx, chainID)
+	if err != nil {
+		s.recordError(run, tx, err)
+		return
+	}
+	chainStatus := int8(info.Status)
+	chainHash := hex.EncodeToString(info.DataHash[:])
+
+	drifted := false
+	hashMismatch := false
+	if strings.EqualFold(info.Uploader, account) || chainStatus == models.TxStatusMatched {
+		if strings.ToLower(strings.TrimPrefix(localHash, "0x")) != chainHash {
+			drifted, hashMismatch = true, true
+			s.saveDrift(&models.ConsistencyDrift{
+				RunID:         run.ID,
+				BizID:         tx.BizID,
+				InstitutionID: tx.InstitutionID,
+				DriftType:     models.DriftHashMismatch,
+				LocalStatus:   tx.Status,
+				ChainStatus:   &chainStatus,
+				LocalHash:     localHash,
+				ChainHash:     chainHash,
+				Detail:        "本地数据哈希与链上记录不一致, 需人工核查",
+			})
+		}
+	}
+
+	if chainStatus != tx.Status {
+		drifted = true
+		drift := &models.ConsistencyDrift{
+			RunID:         run.ID,
+			BizID:         tx.BizID,
+			InstitutionID: tx.InstitutionID,
+			DriftType:     models.DriftStatusMismatch,
+			LocalStatus:   tx.Status,
+			ChainStatus:   &chainStatus,
+		}
+		switch {
+		case !run.AutoHeal:
+		case hashMismatch:
+			// 数据哈希不一致时链上状态不代表本地记录, 不自动修复
+			drift.Detail = "数据哈希不一致, 未自动修复"
+		case group != nil:
+			// 组内交易状态随对账组同步, 不单独修复
+			drift.Detail = "对账组交易, 请同步对账组 " + group.GroupRef + " 的状态"
+		case chainStatus < models.TxStatusUploaded || chainStatus > models.TxStatusMismatch:
+			drift.Detail = "链上状态无对应的本地状态, 未自动修复"
+		default:
+			healed, err := s.txService.ApplyChainStatus(tx, info)
+			switch {
+			case err != nil:
+				drift.Detail = truncate("自动修复失败: "+err.Error(), 255)
+			case healed:
+				drift.Healed = true
+				run.Healed++
+			default:
+				drift.Detail = "本地状态已被并发更新, 未自动修复"
+			}
+		}
+		s.saveDrift(drift)
+	}
+
+	if drifted {
+		run.Drifted++
+	}
+}
+
+// recordError 记录链上查询失败, 单笔失败不中断检查
+func (s *ConsistencyService) recordError(run *models.ConsistencyRun, tx *models.Transaction, err error) {
+	run.Errors++
+	run.LastError = truncate(fmt.Sprintf("%s: %v", tx.BizID, err), 512)
+	s.logger.Warn("failed to query chain for consistency check",
+		zap.Uint("run_id", run.ID),
+		zap.String("biz_id", tx.BizID),
+		zap.Error(err))
+}
+
+// saveDrift 保存偏差明细
+func (s *ConsistencyService) saveDrift(drift *models.ConsistencyDrift) {
+	if err := s.db.Create(drift).Error; err != nil {
+		s.logger.Error("failed to save consistency drift",
+			zap.Uint("run_id", drift.RunID),
+			zap.String("biz_id", drift.BizID),
+			zap.Error(err))
+	}
+}
+
+// saveProgress 保存检查进度
+func (s *ConsistencyService) saveProgress(run *models.ConsistencyRun) error {
+	if err := s.db.Model(run).Updates(map[string]interface{}{
+		"checked":    run.Checked,
+		"drifted":    run.Drifted,
+		"healed":     run.Healed,
+		"errors":     run.Errors,
+		"last_error": run.LastError,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save consistency progress: %w", err)
+	}
+	return nil
+}
+
+// ListRuns 查询检查记录
+func (s *ConsistencyService) ListRuns(page, size int) (*models.PageResponse, error) {
+	var total int64
+	if err := s.db.Model(&models.ConsistencyRun{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count consistency runs: %w", err)
+	}
+
+	var runs []models.ConsistencyRun
+	if err := s.db.Order("id DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list consistency runs: %w", err)
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  runs,
+	}, nil
+}
+
+// GetRun 查询检查记录详情
+func (s *ConsistencyService) GetRun(id uint) (*models.ConsistencyRun, error) {
+	var run models.ConsistencyRun
+	if err := s.db.First(&run, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConsistencyRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get consistency run: %w", err)
+	}
+	return &run, nil
+}
+
+// ListDrifts 查询检查记录的偏差明细, driftType 为空时不限类型
+func (s *ConsistencyService) ListDrifts(runID uint, driftType string, page, size int) (*models.PageResponse, error) {
+	if _, err := s.GetRun(runID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Model(&models.ConsistencyDrift{}).Where("run_id = ?", runID)
+	if driftType != "" {
+		query = query.Where("drift_type = ?", driftType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count consistency drifts: %w", err)
+	}
+
+	var drifts []models.ConsistencyDrift
+	if err := query.Order("id").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&drifts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list consistency drifts: %w", err)
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  drifts,
+	}, nil
+}
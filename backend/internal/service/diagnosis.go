@@ -0,0 +1,421 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDiagnosisTxNotFound 待诊断交易不存在
+	ErrDiagnosisTxNotFound = errors.New("transaction not found")
+	// ErrDiagnosisNotMismatch 交易未被判定为对账失败
+	ErrDiagnosisNotMismatch = errors.New("transaction is not in mismatch status")
+	// ErrDiagnosisNotFound 诊断记录不存在
+	ErrDiagnosisNotFound = errors.New("diagnosis not found")
+	// ErrDiagnosisPacketInvalid 交换包格式错误
+	ErrDiagnosisPacketInvalid = errors.New("invalid diagnosis packet")
+	// ErrDiagnosisKeyMismatch 交换包与本方诊断不属于同一会话
+	ErrDiagnosisKeyMismatch = errors.New("diagnosis key does not match")
+)
+
+// diagnosisPacketVersion 交换包格式版本
+const diagnosisPacketVersion = 2
+
+// DiagnosisService 对账失败字段诊断服务
+type DiagnosisService struct {
+	db            *gorm.DB
+	encryptionKey string
+	logger        *zap.Logger
+}
+
+// NewDiagnosisService 创建字段诊断服务
+func NewDiagnosisService(db *gorm.DB, encryptionKey string, logger *zap.Logger) *DiagnosisService {
+	return &DiagnosisService{
+		db:            db,
+		encryptionKey: encryptionKey,
+		logger:        logger,
+	}
+}
+
+// loadMismatch 加载本机构对账失败的交易
+func (s *DiagnosisService) loadMismatch(institutionID, bizID string) (*models.Transaction, error) {
+	var tx models.Transaction
+	if err := s.db.Where("institution_id = ? AND biz_id = ?", institutionID, bizID).First(&tx).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDiagnosisTxNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if tx.Status != models.TxStatusMismatch {
+		return nil, fmt.Errorf("%w: status is %s", ErrDiagnosisNotMismatch, tx.GetStatusText())
+	}
+	return &tx, nil
+}
+
+// blindFields 以本方私钥计算交易的字段盲化值
+func (s *DiagnosisService) blindFields(key []byte, tx *models.Transaction) (*models.FieldCommitments, error) {
+	amount, err := utils.DecryptAmount(s.encryptionKey, tx.AmountCipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt amount: %w", err)
+	}
+
+	blinded := &models.FieldCommitments{}
+	fields := []struct {
+		field, value string
+		out          *string
+	}{
+		{models.DiagnosisFieldAmount, utils.NormalizeAmount(amount), &blinded.Amount},
+		{models.DiagnosisFieldSender, utils.NormalizeParty(tx.Sender), &blinded.Sender},
+		{models.DiagnosisFieldReceiver, utils.NormalizeParty(tx.Receiver), &blinded.Receiver},
+		{models.DiagnosisFieldTxType, strconv.Itoa(int(tx.TxType)), &blinded.TxType},
+		{models.DiagnosisFieldCurrency, utils.NormalizeCurrency(tx.Currency), &blinded.Currency},
+	}
+	for _, f := range fields {
+		if *f.out, err = utils.BlindField(key, tx.BizID, f.field, f.value); err != nil {
+			return nil, err
+		}
+	}
+	return blinded, nil
+}
+
+// reblindFields 以本方私钥对对手方的字段盲化值做二次盲化, 对手方未提供的字段保持为空
+func (s *DiagnosisService) reblindFields(key []byte, remote *models.FieldCommitments) (*models.FieldCommitments, error) {
+	reblinded := &models.FieldCommitments{}
+	pairs := []struct {
+		in  string
+		out *string
+	}{
+		{remote.Amount, &reblinded.Amount},
+		{remote.Sender, &reblinded.Sender},
+		{remote.Receiver, &reblinded.Receiver},
+		{remote.TxType, &reblinded.TxType},
+		{remote.Currency, &reblinded.Currency},
+	}
+	for _, p := range pairs {
+		if p.in == "" {
+			continue
+		}
+		value, err := utils.ReblindField(key, p.in)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDiagnosisPacketInvalid, err)
+		}
+		*p.out = value
+	}
+	return reblinded, nil
+}
+
+// diagnosisKey 解密本方诊断私钥
+func (s *DiagnosisService) diagnosisKey(d *models.MismatchDiagnosis) ([]byte, error) {
+	keyHex, err := utils.DecryptAmount(s.encryptionKey, d.KeyCipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt diagnosis key: %w", err)
+	}
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode diagnosis key: %w", err)
+	}
+	return key, nil
+}
+
+// newKey 生成一次性诊断私钥, 返回私钥及其密文
+func (s *DiagnosisService) newKey() ([]byte, string, error) {
+	keyHex, err := utils.GenerateDiagnosisKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key, _ := hex.DecodeString(keyHex)
+
+	keyCipher, err := utils.EncryptAmount(s.encryptionKey, keyHex)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encrypt diagnosis key: %w", err)
+	}
+	return key, keyCipher, nil
+}
+
+// getDiagnosis 查询本机构的诊断记录
+func (s *DiagnosisService) getDiagnosis(institutionID, bizID string) (*models.MismatchDiagnosis, error) {
+	var diagnosis models.MismatchDiagnosis
+	if err := s.db.Where("institution_id = ? AND biz_id = ?", institutionID, bizID).First(&diagnosis).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDiagnosisNotFound
+		}
+		return nil, fmt.Errorf("failed to get diagnosis: %w", err)
+	}
+	return &diagnosis, nil
+}
+
+// StartDiagnosis 发起字段诊断
+// 生成一次性私钥并计算本方字段盲化值, 返回的交换包需发送给对手方; 已发起时返回原诊断
+func (s *DiagnosisService) StartDiagnosis(institutionID string, req *models.StartDiagnosisRequest) (*models.DiagnosisResponse, error) {
+	tx, err := s.loadMismatch(institutionID, req.BizID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getDiagnosis(institutionID, req.BizID)
+	if err == nil {
+		return s.toResponse(existing), nil
+	}
+	if !errors.Is(err, ErrDiagnosisNotFound) {
+		return nil, err
+	}
+
+	key, keyCipher, err := s.newKey()
+	if err != nil {
+		return nil, err
+	}
+	local, err := s.blindFields(key, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis := &models.MismatchDiagnosis{
+		BizID:         tx.BizID,
+		InstitutionID: institutionID,
+		Counterparty:  req.Counterparty,
+		Role:          models.DiagnosisRoleInitiator,
+		KeyCipher:     keyCipher,
+		KeyID:         utils.DiagnosisKeyID(key),
+		Status:        models.DiagnosisStatusAwaiting,
+	}
+	setLocal(diagnosis, local)
+	if err := s.db.Create(diagnosis).Error; err != nil {
+		return nil, fmt.Errorf("failed to create diagnosis: %w", err)
+	}
+
+	s.logger.Info("mismatch diagnosis started",
+		zap.String("biz_id", tx.BizID),
+		zap.String("key_id", diagnosis.KeyID),
+	)
+
+	return s.toResponse(diagnosis), nil
+}
+
+// ExchangePacket 导入对手方交换包
+// 本方尚未发起诊断时作为响应方: 计算本方盲化值及对发起方盲化值的二次盲化, 等待发起方回传;
+// 交换包携带本方盲化值的二次盲化时完成比对
+func (s *DiagnosisService) ExchangePacket(institutionID string, packet *models.DiagnosisPacket) (*models.DiagnosisResponse, error) {
+	if packet.Version != diagnosisPacketVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrDiagnosisPacketInvalid, packet.Version)
+	}
+	if packet.InstitutionID == institutionID {
+		return nil, fmt.Errorf("%w: packet was issued by this institution", ErrDiagnosisPacketInvalid)
+	}
+
+	tx, err := s.loadMismatch(institutionID, packet.BizID)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis, err := s.getDiagnosis(institutionID, packet.BizID)
+	switch {
+	case errors.Is(err, ErrDiagnosisNotFound):
+		diagnosis, err = s.respond(institutionID, tx, packet)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := s.complete(diagnosis, packet); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := s.toResponse(diagnosis)
+	s.logger.Info("mismatch diagnosis packet imported",
+		zap.String("biz_id", diagnosis.BizID),
+		zap.String("counterparty", diagnosis.Counterparty),
+		zap.String("role", diagnosis.Role),
+		zap.Int8("status", diagnosis.Status),
+		zap.Strings("diff_fields", resp.DiffFields),
+	)
+	return resp, nil
+}
+
+// respond 作为响应方创建诊断记录
+func (s *DiagnosisService) respond(institutionID string, tx *models.Transaction, packet *models.DiagnosisPacket) (*models.MismatchDiagnosis, error) {
+	if packet.Blinded != nil {
+		return nil, fmt.Errorf("%w: no diagnosis started for this packet", ErrDiagnosisPacketInvalid)
+	}
+
+	key, keyCipher, err := s.newKey()
+	if err != nil {
+		return nil, err
+	}
+	local, err := s.blindFields(key, tx)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.reblindFields(key, &packet.Commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis := &models.MismatchDiagnosis{
+		BizID:         tx.BizID,
+		InstitutionID: institutionID,
+		Counterparty:  packet.InstitutionID,
+		Role:          models.DiagnosisRoleResponder,
+		KeyCipher:     keyCipher,
+		KeyID:         packet.KeyID,
+		Status:        models.DiagnosisStatusAwaiting,
+	}
+	setLocal(diagnosis, local)
+	setRemote(diagnosis, remote)
+	if err := s.db.Create(diagnosis).Error; err != nil {
+		return nil, fmt.Errorf("failed to create diagnosis: %w", err)
+	}
+	return diagnosis, nil
+}
+
+// complete 记录对手方回传的本方二次盲化值并完成比对
+// 发起方此时才收到响应方的盲化值, 需先计算其二次盲化
+func (s *DiagnosisService) complete(d *models.MismatchDiagnosis, packet *models.DiagnosisPacket) error {
+	if packet.KeyID != d.KeyID {
+		return ErrDiagnosisKeyMismatch
+	}
+	if d.Counterparty != "" && d.Counterparty != packet.InstitutionID {
+		return fmt.Errorf("%w: expected packet from %s", ErrDiagnosisPacketInvalid, d.Counterparty)
+	}
+	if packet.Blinded == nil {
+		return fmt.Errorf("%w: packet carries no blinded values of this institution", ErrDiagnosisPacketInvalid)
+	}
+
+	if d.Role == models.DiagnosisRoleInitiator {
+		key, err := s.diagnosisKey(d)
+		if err != nil {
+			return err
+		}
+		remote, err := s.reblindFields(key, &packet.Commitments)
+		if err != nil {
+			return err
+		}
+		setRemote(d, remote)
+	}
+
+	now := time.Now()
+	d.Counterparty = packet.InstitutionID
+	d.ReturnedAmountHash = packet.Blinded.Amount
+	d.ReturnedSenderHash = packet.Blinded.Sender
+	d.ReturnedReceiverHash = packet.Blinded.Receiver
+	d.ReturnedTypeHash = packet.Blinded.TxType
+	d.ReturnedCurrencyHash = packet.Blinded.Currency
+	d.Status = models.DiagnosisStatusCompleted
+	d.CompletedAt = &now
+	updates := map[string]interface{}{
+		"counterparty":           d.Counterparty,
+		"remote_amount_hash":     d.RemoteAmountHash,
+		"remote_sender_hash":     d.RemoteSenderHash,
+		"remote_receiver_hash":   d.RemoteReceiverHash,
+		"remote_type_hash":       d.RemoteTypeHash,
+		"remote_currency_hash":   d.RemoteCurrencyHash,
+		"returned_amount_hash":   d.ReturnedAmountHash,
+		"returned_sender_hash":   d.ReturnedSenderHash,
+		"returned_receiver_hash": d.ReturnedReceiverHash,
+		"returned_type_hash":     d.ReturnedTypeHash,
+		"returned_currency_hash": d.ReturnedCurrencyHash,
+		"status":                 d.Status,
+		"completed_at":           d.CompletedAt,
+	}
+	if err := s.db.Model(d).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update diagnosis: %w", err)
+	}
+	return nil
+}
+
+// setLocal 记录本方字段盲化值
+func setLocal(d *models.MismatchDiagnosis, c *models.FieldCommitments) {
+	d.LocalAmountHash = c.Amount
+	d.LocalSenderHash = c.Sender
+	d.LocalReceiverHash = c.Receiver
+	d.LocalTypeHash = c.TxType
+	d.LocalCurrencyHash = c.Currency
+}
+
+// setRemote 记录本方对对手方盲化值的二次盲化
+func setRemote(d *models.MismatchDiagnosis, c *models.FieldCommitments) {
+	d.RemoteAmountHash = c.Amount
+	d.RemoteSenderHash = c.Sender
+	d.RemoteReceiverHash = c.Receiver
+	d.RemoteTypeHash = c.TxType
+	d.RemoteCurrencyHash = c.Currency
+}
+
+// GetDiagnosis 查询诊断结果
+func (s *DiagnosisService) GetDiagnosis(institutionID, bizID string) (*models.DiagnosisResponse, error) {
+	diagnosis, err := s.getDiagnosis(institutionID, bizID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(diagnosis), nil
+}
+
+// toResponse 构造诊断响应
+// 交换包携带本方盲化值, 已计算对手方二次盲化值时一并携带; 完成比对后列出各字段是否一致
+func (s *DiagnosisService) toResponse(d *models.MismatchDiagnosis) *models.DiagnosisResponse {
+	packet := &models.DiagnosisPacket{
+		Version:       diagnosisPacketVersion,
+		BizID:         d.BizID,
+		InstitutionID: d.InstitutionID,
+		KeyID:         d.KeyID,
+		Commitments: models.FieldCommitments{
+			Amount:   d.LocalAmountHash,
+			Sender:   d.LocalSenderHash,
+			Receiver: d.LocalReceiverHash,
+			TxType:   d.LocalTypeHash,
+			Currency: d.LocalCurrencyHash,
+		},
+	}
+	if d.RemoteAmountHash != "" {
+		packet.Blinded = &models.FieldCommitments{
+			Amount:   d.RemoteAmountHash,
+			Sender:   d.RemoteSenderHash,
+			Receiver: d.RemoteReceiverHash,
+			TxType:   d.RemoteTypeHash,
+			Currency: d.RemoteCurrencyHash,
+		}
+	}
+
+	resp := &models.DiagnosisResponse{
+		MismatchDiagnosis: d,
+		StatusText:        d.GetStatusText(),
+		Packet:            packet,
+	}
+	if d.Status != models.DiagnosisStatusCompleted {
+		return resp
+	}
+
+	// 双方字段取值相同时, 本方计算的对手方二次盲化值与对手方回传的本方二次盲化值相同
+	pairs := []struct {
+		field         string
+		local, remote string
+	}{
+		{models.DiagnosisFieldAmount, d.ReturnedAmountHash, d.RemoteAmountHash},
+		{models.DiagnosisFieldSender, d.ReturnedSenderHash, d.RemoteSenderHash},
+		{models.DiagnosisFieldReceiver, d.ReturnedReceiverHash, d.RemoteReceiverHash},
+		{models.DiagnosisFieldTxType, d.ReturnedTypeHash, d.RemoteTypeHash},
+		{models.DiagnosisFieldCurrency, d.ReturnedCurrencyHash, d.RemoteCurrencyHash},
+	}
+	resp.DiffFields = make([]string, 0)
+	for _, p := range pairs {
+		if p.local == "" || p.remote == "" {
+			continue // 一方未提供该字段
+		}
+		matched := p.local == p.remote
+		resp.Fields = append(resp.Fields, models.FieldDiff{Field: p.field, Matched: matched})
+		if !matched {
+			resp.DiffFields = append(resp.DiffFields, p.field)
+		}
+	}
+	return resp
+}
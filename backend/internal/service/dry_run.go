@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// 预检本地结果
+const (
+	DryRunInvalid       = "invalid"        // 行校验未通过
+	DryRunNew           = "new"            // 将新建交易
+	DryRunDuplicate     = "duplicate"      // 业务流水号已存在
+	DryRunReady         = "ready"          // 可以上链
+	DryRunNotFound      = "not_found"      // 交易不存在
+	DryRunInvalidStatus = "invalid_status" // 交易状态不是待上链
+)
+
+// 预检链上结果
+const (
+	ChainOutcomeNotUploaded          = "counterparty_not_uploaded" // 对手方尚未上链, 上链后等待对手方
+	ChainOutcomeCounterpartyUploaded = "counterparty_uploaded"     // 对手方已上链, 上链时比对哈希
+	ChainOutcomeWouldMatch           = "would_match"               // 与对手方哈希一致, 上链后对账成功
+	ChainOutcomeWouldMismatch        = "would_mismatch"            // 与对手方哈希不一致, 上链后对账失败
+	ChainOutcomeAlreadyUploaded      = "already_uploaded"          // 本机构已上链, 合约将拒绝重复上传
+	ChainOutcomeAlreadyReconciled    = "already_reconciled"        // 链上已完成对账
+	ChainOutcomeUnknown              = "unknown"                   // 链上预检不可用
+)
+
+// chainStatusUploaded 合约中单方已上链的状态值
+const chainStatusUploaded = 1
+
+const (
+	dryRunChainWorkers = 8    // 链上预检的并发查询数
+	maxDryRunChainRows = 1000 // 单次预检最多查询链上记录的行数, 超出部分链上结果为 unknown
+)
+
+// DryRunRow 单行预检结果
+type DryRunRow struct {
+	Row           int    `json:"row,omitempty"` // 文件行号, 上链预检时为空
+	BizID         string `json:"biz_id"`
+	Outcome       string `json:"outcome"`
+	ChainOutcome  string `json:"chain_outcome,omitempty"`
+	DataHash      string `json:"data_hash,omitempty"`
+	ChainUploader string `json:"chain_uploader,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// DryRunResult 预检结果, 不写入任何数据
+type DryRunResult struct {
+	DryRun         bool                `json:"dry_run"`
+	Total          int                 `json:"total"`
+	Summary        map[string]int      `json:"summary"`       // 按本地结果统计
+	ChainSummary   map[string]int      `json:"chain_summary"` // 按链上结果统计
+	Rows           []DryRunRow         `json:"rows"`
+	Errors         []utils.RowError    `json:"errors,omitempty"`          // 行级错误明细(导入预检)
+	DuplicateBatch *models.ImportBatch `json:"duplicate_batch,omitempty"` // 相同文件已导入的批次
+	ChainError     string              `json:"chain_error,omitempty"`     // 链上预检失败原因
+	ChainTruncated bool                `json:"chain_truncated,omitempty"` // 行数超过上限, 超出部分未查询链上记录
+}
+
+func newDryRunResult() *DryRunResult {
+	return &DryRunResult{
+		DryRun:       true,
+		Summary:      make(map[string]int),
+		ChainSummary: make(map[string]int),
+	}
+}
+
+// add 追加一行结果并计入统计
+func (r *DryRunResult) add(row DryRunRow) {
+	r.Rows = append(r.Rows, row)
+	r.Summary[row.Outcome]++
+	if row.ChainOutcome != "" {
+		r.ChainSummary[row.ChainOutcome]++
+	}
+}
+
+// PreviewImport 导入预检
+// 执行解析、行校验、文件及业务流水号查重、哈希计算和链上存在性检查, 返回每行的预测结果.
+// 数据哈希含随机盐, 正式导入时会重新生成, 因此此处的哈希仅供预览, 与对手方的比对在上链时完成.
+func (s *TransactionService) PreviewImport(ctx context.Context, filePath, institutionID string, opts *utils.ParseOptions) (*DryRunResult, error) {
+	result := newDryRunResult()
+
+	// 1. 相同文件是否已导入
+	digest, _, err := utils.CalculateFileDigest(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if result.DuplicateBatch, err = s.findImportedBatch(institutionID, digest); err != nil {
+		return nil, err
+	}
+
+	// 2. 行级校验
+	parsed, err := utils.ValidateImportFile(filePath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse excel: %w", err)
+	}
+	result.Total = parsed.TotalRows
+	result.Errors = parsed.Errors
+
+	reasons := make(map[int][]string)
+	for _, e := range parsed.Errors {
+		reasons[e.Row] = append(reasons[e.Row], e.Reason)
+	}
+	for _, row := range parsed.InvalidRows {
+		result.add(DryRunRow{
+			Row:     row.RowNum,
+			BizID:   row.BizID,
+			Outcome: DryRunInvalid,
+			Reason:  strings.Join(reasons[row.RowNum], "; "),
+		})
+	}
+
+	// 3. 库中业务流水号查重
+	validRows, dupRows, dupErrs, err := s.excludeExistingBizIDs(parsed)
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = append(result.Errors, dupErrs...)
+	for _, row := range dupRows {
+		result.add(DryRunRow{
+			Row:     row.RowNum,
+			BizID:   row.BizID,
+			Outcome: DryRunDuplicate,
+			Reason:  "业务流水号已存在",
+		})
+	}
+
+	// 4. 计算预览哈希并检查链上记录
+	items := make([]DryRunRow, len(validRows))
+	checks := make([]chainCheck, len(validRows))
+	for i, row := range validRows {
+		salt, err := utils.GenerateRandomSalt()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+
+		items[i] = DryRunRow{
+			Row:      row.RowNum,
+			BizID:    row.BizID,
+			Outcome:  DryRunNew,
+			DataHash: utils.CalculateDataHash(row.BizID, row.Amount, row.Currency, salt),
+		}
+		checks[i] = chainCheck{item: &items[i]}
+	}
+	s.previewChainRows(ctx, result, checks)
+	for _, item := range items {
+		result.add(item)
+	}
+
+	sortDryRunRows(result.Rows)
+
+	s.logger.Info("import dry run completed",
+		zap.String("institution", institutionID),
+		zap.Int("total", result.Total),
+		zap.Int("new", result.Summary[DryRunNew]))
+
+	return result, nil
+}
+
+// PreviewUploadToChain 上链预检
+// 检查交易是否存在及状态, 并查询链上记录, 预测上链后的对账结果
+// institutionID 不为空时仅预检该机构的交易, 其他机构的交易视为不存在
+func (s *TransactionService) PreviewUploadToChain(ctx context.Context, institutionID string, bizIds []string) (*DryRunResult, error) {
+	result := newDryRunResult()
+	result.Total = len(bizIds)
+
+	query := s.db.Where("biz_id IN ?", bizIds)
+	if institutionID != "" {
+		query = query.Where("institution_id = ?", institutionID)
+	}
+	var txs []models.Transaction
+	if err := query.Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	txMap := make(map[string]*models.Transaction, len(txs))
+	for i := range txs {
+		txMap[txs[i].BizID] = &txs[i]
+	}
+
+	items := make([]DryRunRow, len(bizIds))
+	var checks []chainCheck
+	for i, bizId := range bizIds {
+		tx, ok := txMap[bizId]
+		switch {
+		case !ok:
+			items[i] = DryRunRow{BizID: bizId, Outcome: DryRunNotFound, Reason: "交易不存在"}
+		case tx.GroupID != nil:
+			items[i] = DryRunRow{
+				BizID:   bizId,
+				Outcome: DryRunInvalidStatus,
+				Reason:  "交易已归入对账组, 需通过对账组上链",
+			}
+		case tx.Status != models.TxStatusPending:
+			items[i] = DryRunRow{
+				BizID:    bizId,
+				Outcome:  DryRunInvalidStatus,
+				DataHash: tx.DataHash,
+				Reason:   "交易状态为" + tx.GetStatusText(),
+			}
+		default:
+			items[i] = DryRunRow{BizID: bizId, Outcome: DryRunReady, DataHash: tx.DataHash}
+			checks = append(checks, chainCheck{item: &items[i], dataHash: tx.DataHash})
+		}
+	}
+
+	s.previewChainRows(ctx, result, checks)
+	for _, item := range items {
+		result.add(item)
+	}
+
+	return result, nil
+}
+
+// chainCheck 待查询链上记录的预检行
+type chainCheck struct {
+	item     *DryRunRow
+	dataHash string // 为空时不比对哈希(导入预检的哈希尚未确定)
+}
+
+// previewChainRows 并发查询链上记录并填写预测结果, 最多查询 maxDryRunChainRows 行
+// 任一查询失败后不再发起新的查询, 未查询的行链上结果为 unknown
+func (s *TransactionService) previewChainRows(ctx context.Context, result *DryRunResult, checks []chainCheck) {
+	if len(checks) > maxDryRunChainRows {
+		result.ChainTruncated = true
+		for _, check := range checks[maxDryRunChainRows:] {
+			check.item.ChainOutcome = ChainOutcomeUnknown
+		}
+		checks = checks[:maxDryRunChainRows]
+	}
+	if len(checks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := dryRunChainWorkers
+	if workers > len(checks) {
+		workers = len(checks)
+	}
+
+	self := s.blockchain.GetAccountAddress().Hex()
+	var failOnce sync.Once
+	jobs := make(chan chainCheck)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for check := range jobs {
+				if ctx.Err() != nil {
+					check.item.ChainOutcome = ChainOutcomeUnknown
+					continue
+				}
+				if err := s.previewChain(ctx, check.item, self, check.dataHash); err != nil {
+					check.item.ChainOutcome = ChainOutcomeUnknown
+					failOnce.Do(func() {
+						s.logger.Warn("chain dry run check failed", zap.String("biz_id", check.item.BizID), zap.Error(err))
+						result.ChainError = err.Error()
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+	for _, check := range checks {
+		jobs <- check
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// previewChain 查询单行的链上记录并填写链上预测结果
+// dataHash 为空时不比对哈希(导入预检的哈希尚未确定)
+func (s *TransactionService) previewChain(ctx context.Context, item *DryRunRow, self, dataHash string) error {
+	exists, err := s.blockchain.TxExists(ctx, item.BizID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		item.ChainOutcome = ChainOutcomeNotUploaded
+		return nil
+	}
+
+	info, err := s.blockchain.GetTransaction(ctx, item.BizID)
+	if err != nil {
+		return err
+	}
+	item.ChainUploader = info.Uploader
+
+	switch {
+	case strings.EqualFold(info.Uploader, self):
+		item.ChainOutcome = ChainOutcomeAlreadyUploaded
+	case info.Status != chainStatusUploaded:
+		item.ChainOutcome = ChainOutcomeAlreadyReconciled
+	case dataHash == "":
+		item.ChainOutcome = ChainOutcomeCounterpartyUploaded
+	case strings.EqualFold(hex.EncodeToString(info.DataHash[:]), strings.TrimPrefix(dataHash, "0x")):
+		item.ChainOutcome = ChainOutcomeWouldMatch
+	default:
+		item.ChainOutcome = ChainOutcomeWouldMismatch
+	}
+	return nil
+}
+
+// sortDryRunRows 按文件行号排序
+func sortDryRunRows(rows []DryRunRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Row < rows[j].Row
+	})
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrFXRateNotFound 汇率记录不存在
+	ErrFXRateNotFound = errors.New("fx rate not found")
+	// ErrFXRateUnavailable 缺少可用于折算的汇率
+	ErrFXRateUnavailable = errors.New("no fx rate available for conversion")
+	// ErrFXRateInvalid 汇率参数错误
+	ErrFXRateInvalid = errors.New("invalid fx rate")
+)
+
+// MaxAmountRangeDays 金额统计单次查询的最大日期跨度(天)
+const MaxAmountRangeDays = 366
+
+// FXOptions 汇率折算参数
+type FXOptions struct {
+	ReportingCurrency string // 默认报告币种
+	PivotCurrency     string // 交叉汇率的中间币种
+	MaxRateAgeDays    int    // 汇率最长有效天数, 0 表示不限
+}
+
+// FXImportResult 汇率文件导入结果
+type FXImportResult struct {
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []utils.RowError `json:"errors"`
+}
+
+// FXService 汇率服务
+type FXService struct {
+	db            *gorm.DB
+	encryptionKey string
+	options       FXOptions
+	logger        *zap.Logger
+}
+
+// NewFXService 创建汇率服务
+func NewFXService(db *gorm.DB, encryptionKey string, options FXOptions, logger *zap.Logger) *FXService {
+	if options.ReportingCurrency == "" {
+		options.ReportingCurrency = utils.DefaultCurrency
+	}
+	if options.PivotCurrency == "" {
+		options.PivotCurrency = utils.DefaultCurrency
+	}
+	options.ReportingCurrency = utils.NormalizeCurrency(options.ReportingCurrency)
+	options.PivotCurrency = utils.NormalizeCurrency(options.PivotCurrency)
+
+	return &FXService{
+		db:            db,
+		encryptionKey: encryptionKey,
+		options:       options,
+		logger:        logger,
+	}
+}
+
+// upsertRates 写入汇率, 同一币种对同一日期的汇率以最后一次写入为准
+func (s *FXService) upsertRates(db *gorm.DB, rates []models.FXRate) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).Create(&rates).Error
+}
+
+// ImportRates 导入汇率文件(.xlsx 或 CSV)
+// 校验通过的行全部写入, 错误行在结果中列出; 行内未提供来源时使用 source
+func (s *FXService) ImportRates(filePath, source string) (*FXImportResult, error) {
+	parsed, err := utils.ParseFXRateFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FXImportResult{
+		Total:  parsed.TotalRows,
+		Errors: parsed.Errors,
+	}
+	if result.Errors == nil {
+		result.Errors = []utils.RowError{}
+	}
+
+	rates := make([]models.FXRate, 0, len(parsed.ValidRows))
+	for _, row := range parsed.ValidRows {
+		rateDate, _ := time.ParseInLocation("2006-01-02", row.RateDate, time.Local)
+		rowSource := row.Source
+		if rowSource == "" {
+			rowSource = source
+		}
+		rates = append(rates, models.FXRate{
+			BaseCurrency:  row.BaseCurrency,
+			QuoteCurrency: row.QuoteCurrency,
+			RateDate:      rateDate,
+			Rate:          row.Rate,
+			Source:        rowSource,
+		})
+	}
+
+	if len(rates) > 0 {
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			return s.upsertRates(tx, rates)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to save fx rates: %w", err)
+		}
+	}
+
+	result.Imported = len(rates)
+	result.Failed = result.Total - result.Imported
+
+	s.logger.Info("fx rates imported",
+		zap.Int("imported", result.Imported),
+		zap.Int("failed", result.Failed))
+
+	return result, nil
+}
+
+// CreateRate 录入单条汇率
+func (s *FXService) CreateRate(req *models.CreateFXRateRequest) (*models.FXRate, error) {
+	base, quote := utils.NormalizeCurrency(req.BaseCurrency), utils.NormalizeCurrency(req.QuoteCurrency)
+	if !utils.IsValidCurrency(base) || !utils.IsValidCurrency(quote) {
+		return nil, fmt.Errorf("%w: 币种须为ISO 4217货币代码", ErrFXRateInvalid)
+	}
+	if base == quote {
+		return nil, fmt.Errorf("%w: 报价币种不能与基准币种相同", ErrFXRateInvalid)
+	}
+	rateDate, err := time.ParseInLocation("2006-01-02", req.RateDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 日期格式错误, 应为 YYYY-MM-DD", ErrFXRateInvalid)
+	}
+	rate, err := utils.ParseAmount(req.Rate)
+	if err != nil || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: 汇率必须为大于0的数字", ErrFXRateInvalid)
+	}
+
+	record := models.FXRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		RateDate:      rateDate,
+		Rate:          utils.FormatAmount(rate),
+		Source:        req.Source,
+	}
+	if err := s.upsertRates(s.db, []models.FXRate{record}); err != nil {
+		return nil, fmt.Errorf("failed to save fx rate: %w", err)
+	}
+
+	if err := s.db.Where("base_currency = ? AND quote_currency = ? AND rate_date = ?", base, quote, req.RateDate).
+		First(&record).Error; err != nil {
+		return nil, fmt.Errorf("failed to get fx rate: %w", err)
+	}
+	return &record, nil
+}
+
+// ListRates 查询汇率, 按日期倒序
+func (s *FXService) ListRates(baseCurrency, quoteCurrency string, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.FXRate{})
+	if baseCurrency != "" {
+		query = query.Where("base_currency = ?", utils.NormalizeCurrency(baseCurrency))
+	}
+	if quoteCurrency != "" {
+		query = query.Where("quote_currency = ?", utils.NormalizeCurrency(quoteCurrency))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count fx rates: %w", err)
+	}
+
+	var rates []models.FXRate
+	if err := query.Order("rate_date DESC, base_currency, quote_currency").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list fx rates: %w", err)
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  rates,
+	}, nil
+}
+
+// DeleteRate 删除汇率
+func (s *FXService) DeleteRate(id uint) error {
+	result := s.db.Delete(&models.FXRate{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete fx rate: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrFXRateNotFound
+	}
+	return nil
+}
+
+// findRate 查找 asOf 当日及之前最近一次的直接报价, 超过有效天数视为缺失
+func (s *FXService) findRate(base, quote string, asOf time.Time) (*models.FXRate, error) {
+	query := s.db.Where("base_currency = ? AND quote_currency = ? AND rate_date <= ?", base, quote, asOf.Format("2006-01-02"))
+	if s.options.MaxRateAgeDays > 0 {
+		query = query.Where("rate_date >= ?", asOf.AddDate(0, 0, -s.options.MaxRateAgeDays).Format("2006-01-02"))
+	}
+
+	var rate models.FXRate
+	if err := query.Order("rate_date DESC").First(&rate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get fx rate: %w", err)
+	}
+	return &rate, nil
+}
+
+// pairRate 查找直接报价, 没有时使用反向报价的倒数
+func (s *FXService) pairRate(from, to string, asOf time.Time) (*big.Rat, *time.Time, error) {
+	for _, inverse := range []bool{false, true} {
+		base, quote := from, to
+		if inverse {
+			base, quote = to, from
+		}
+
+		record, err := s.findRate(base, quote, asOf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if record == nil {
+			continue
+		}
+
+		rate, err := utils.ParseAmount(record.Rate)
+		if err != nil || rate.Sign() <= 0 {
+			return nil, nil, fmt.Errorf("invalid stored fx rate %s/%s: %q", base, quote, record.Rate)
+		}
+		if inverse {
+			rate.Inv(rate)
+		}
+		return rate, &record.RateDate, nil
+	}
+	return nil, nil, nil
+}
+
+// Rate 返回 1 单位 from 币种折算为 to 币种的汇率及所用汇率日期
+// 依次尝试直接报价、反向报价、经中间币种的交叉汇率
+func (s *FXService) Rate(from, to string, asOf time.Time) (*big.Rat, *time.Time, error) {
+	from, to = utils.NormalizeCurrency(from), utils.NormalizeCurrency(to)
+	if from == to {
+		return big.NewRat(1, 1), nil, nil
+	}
+
+	rate, rateDate, err := s.pairRate(from, to, asOf)
+	if err != nil || rate != nil {
+		return rate, rateDate, err
+	}
+
+	pivot := s.options.PivotCurrency
+	if pivot == from || pivot == to {
+		return nil, nil, ErrFXRateUnavailable
+	}
+	fromPivot, fromDate, err := s.pairRate(from, pivot, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	pivotTo, toDate, err := s.pairRate(pivot, to, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fromPivot == nil || pivotTo == nil {
+		return nil, nil, ErrFXRateUnavailable
+	}
+
+	if toDate.Before(*fromDate) {
+		fromDate = toDate
+	}
+	return new(big.Rat).Mul(fromPivot, pivotTo), fromDate, nil
+}
+
+// ReportingCurrency 返回默认报告币种
+func (s *FXService) ReportingCurrency() string {
+	return s.options.ReportingCurrency
+}
+
+// AmountStatistics 按币种汇总机构在 [start, end] 日期内创建的交易金额, 并以 asOf 日的参考汇率折算为报告币种
+// 金额为密文存储, 需逐行解密求和, 因此日期范围不超过 MaxAmountRangeDays 天; 缺少汇率的币种单独列出, 不计入折算合计
+func (s *FXService) AmountStatistics(institutionID, reportingCurrency string, start, end, asOf time.Time) (*models.AmountStatistics, error) {
+	if reportingCurrency == "" {
+		reportingCurrency = s.options.ReportingCurrency
+	}
+	reportingCurrency = utils.NormalizeCurrency(reportingCurrency)
+	if !utils.IsValidCurrency(reportingCurrency) {
+		return nil, fmt.Errorf("%w: 报告币种须为ISO 4217货币代码", ErrFXRateInvalid)
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: 截止日期不能早于起始日期", ErrFXRateInvalid)
+	}
+	if end.Sub(start) >= time.Duration(MaxAmountRangeDays)*24*time.Hour {
+		return nil, fmt.Errorf("%w: 日期范围不能超过%d天", ErrFXRateInvalid, MaxAmountRangeDays)
+	}
+
+	rows, err := s.db.Model(&models.Transaction{}).
+		Select("currency, amount_cipher").
+		Where("institution_id = ? AND created_at >= ? AND created_at < ?", institutionID, start, end.AddDate(0, 0, 1)).
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]*models.CurrencyTotal)
+	sums := make(map[string]*big.Rat)
+	for rows.Next() {
+		var rec struct {
+			Currency     string
+			AmountCipher string
+		}
+		if err := s.db.ScanRows(rows, &rec); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		plain, err := utils.DecryptAmount(s.encryptionKey, rec.AmountCipher)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt amount: %w", err)
+		}
+		amount, err := utils.ParseAmount(plain)
+		if err != nil {
+			return nil, err
+		}
+
+		currency := rec.Currency
+		if currency == "" {
+			currency = utils.DefaultCurrency
+		}
+		if _, ok := totals[currency]; !ok {
+			totals[currency] = &models.CurrencyTotal{Currency: currency}
+			sums[currency] = new(big.Rat)
+		}
+		totals[currency].Count++
+		sums[currency].Add(sums[currency], amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transactions: %w", err)
+	}
+
+	stats := &models.AmountStatistics{
+		ReportingCurrency: reportingCurrency,
+		StartDate:         start.Format("2006-01-02"),
+		EndDate:           end.Format("2006-01-02"),
+		AsOf:              asOf.Format("2006-01-02"),
+		Totals:            make([]*models.CurrencyTotal, 0, len(totals)),
+	}
+	grand := new(big.Rat)
+	for currency, total := range totals {
+		total.Amount = utils.FormatAmount(sums[currency])
+
+		rate, rateDate, err := s.Rate(currency, reportingCurrency, asOf)
+		switch {
+		case errors.Is(err, ErrFXRateUnavailable):
+			stats.MissingRates = append(stats.MissingRates, currency)
+		case err != nil:
+			return nil, err
+		default:
+			converted := new(big.Rat).Mul(sums[currency], rate)
+			total.Rate = utils.FormatAmount(rate)
+			total.RateDate = rateDate
+			total.ConvertedAmount = converted.FloatString(2)
+			grand.Add(grand, converted)
+		}
+		stats.Totals = append(stats.Totals, total)
+	}
+	sort.Slice(stats.Totals, func(i, j int) bool { return stats.Totals[i].Currency < stats.Totals[j].Currency })
+	sort.Strings(stats.MissingRates)
+	stats.TotalAmount = grand.FloatString(2)
+
+	return stats, nil
+}
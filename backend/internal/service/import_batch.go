@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"bc-reconciliation-backend/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrImportBatchNotFound 导入批次不存在
+	ErrImportBatchNotFound = errors.New("import batch not found")
+	// ErrBatchNotRollbackable 批次不可回滚
+	ErrBatchNotRollbackable = errors.New("import batch cannot be rolled back")
+)
+
+// DuplicateImportError 同一文件已导入
+type DuplicateImportError struct {
+	Batch *models.ImportBatch
+}
+
+func (e *DuplicateImportError) Error() string {
+	return fmt.Sprintf("file already imported in batch %s", e.Batch.BatchNo)
+}
+
+// ImportBatchService 导入批次服务
+type ImportBatchService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewImportBatchService 创建导入批次服务
+func NewImportBatchService(db *gorm.DB, logger *zap.Logger) *ImportBatchService {
+	return &ImportBatchService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// getBatch 查询机构的导入批次
+func (s *ImportBatchService) getBatch(db *gorm.DB, institutionID, batchNo string) (*models.ImportBatch, error) {
+	var batch models.ImportBatch
+	if err := db.Where("institution_id = ? AND batch_no = ?", institutionID, batchNo).First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get import batch: %w", err)
+	}
+	return &batch, nil
+}
+
+// ListBatches 分页查询导入批次
+func (s *ImportBatchService) ListBatches(institutionID string, status *int8, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.ImportBatch{}).Where("institution_id = ?", institutionID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count import batches: %w", err)
+	}
+
+	var batches []models.ImportBatch
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list import batches: %w", err)
+	}
+
+	list := make([]*models.ImportBatchResponse, 0, len(batches))
+	for i := range batches {
+		list = append(list, &models.ImportBatchResponse{
+			ImportBatch:  &batches[i],
+			StatusText:   batches[i].GetStatusText(),
+			Rollbackable: batches[i].Status == models.ImportBatchStatusCompleted,
+		})
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  list,
+	}, nil
+}
+
+// GetBatch 查询导入批次详情, 附带批次内交易的状态分布
+func (s *ImportBatchService) GetBatch(institutionID, batchNo string) (*models.ImportBatchResponse, error) {
+	batch, err := s.getBatch(s.db, institutionID, batchNo)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		Status int8
+		Count  int64
+	}
+	if err := s.db.Model(&models.Transaction{}).
+		Select("status, COUNT(*) AS count").
+		Where("batch_id = ?", batch.ID).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count batch transactions: %w", err)
+	}
+
+	resp := &models.ImportBatchResponse{
+		ImportBatch:  batch,
+		StatusText:   batch.GetStatusText(),
+		StatusCounts: make(map[int8]int64, len(counts)),
+	}
+	nonPending := int64(0)
+	for _, c := range counts {
+		resp.StatusCounts[c.Status] = c.Count
+		if c.Status != models.TxStatusPending {
+			nonPending += c.Count
+		}
+	}
+	resp.Rollbackable = batch.Status == models.ImportBatchStatusCompleted && nonPending == 0
+
+	return resp, nil
+}
+
+// ListBatchTransactions 分页查询批次内的交易
+func (s *ImportBatchService) ListBatchTransactions(institutionID, batchNo string, page, size int) (*models.PageResponse, error) {
+	batch, err := s.getBatch(s.db, institutionID, batchNo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Model(&models.Transaction{}).Where("batch_id = ?", batch.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count batch transactions: %w", err)
+	}
+
+	var txs []models.Transaction
+	if err := query.Order("id").Offset((page - 1) * size).Limit(size).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list batch transactions: %w", err)
+	}
+
+	responses := make([]*models.TransactionResponse, len(txs))
+	for i, tx := range txs {
+		responses[i] = tx.ToResponse()
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  responses,
+	}, nil
+}
+
+// RollbackBatch 回滚导入批次
+// 仅当批次内所有交易仍为待上链状态且未归入对账组时允许回滚; 在事务中锁定批次交易后删除, 避免与上链、归组并发
+func (s *ImportBatchService) RollbackBatch(institutionID, batchNo string) (*models.ImportBatchResponse, error) {
+	var batch *models.ImportBatch
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		batch, err = s.getBatch(tx.Clauses(clause.Locking{Strength: "UPDATE"}), institutionID, batchNo)
+		if err != nil {
+			return err
+		}
+		if batch.Status != models.ImportBatchStatusCompleted {
+			return fmt.Errorf("%w: batch status is %s", ErrBatchNotRollbackable, batch.GetStatusText())
+		}
+
+		var rows []struct {
+			Status  int8
+			GroupID *uint
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.Transaction{}).
+			Select("status", "group_id").
+			Where("batch_id = ?", batch.ID).
+			Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to lock batch transactions: %w", err)
+		}
+
+		nonPending, grouped := 0, 0
+		for _, row := range rows {
+			if row.Status != models.TxStatusPending {
+				nonPending++
+			}
+			if row.GroupID != nil {
+				grouped++
+			}
+		}
+		if nonPending > 0 {
+			return fmt.Errorf("%w: %d transactions already uploaded to chain", ErrBatchNotRollbackable, nonPending)
+		}
+		// 归组交易的明细承诺已计入组承诺, 删除后对账组无法上链, 需先删除对账组
+		if grouped > 0 {
+			return fmt.Errorf("%w: %d transactions belong to reconciliation groups", ErrBatchNotRollbackable, grouped)
+		}
+
+		res := tx.Where("batch_id = ? AND status = ? AND group_id IS NULL", batch.ID, models.TxStatusPending).Delete(&models.Transaction{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete batch transactions: %w", res.Error)
+		}
+
+		batch.Status = models.ImportBatchStatusRolledBack
+		batch.RollbackRows = int(res.RowsAffected)
+		return tx.Model(batch).Updates(map[string]interface{}{
+			"status":        batch.Status,
+			"rollback_rows": batch.RollbackRows,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("import batch rolled back",
+		zap.String("batch_no", batchNo),
+		zap.Int("rows", batch.RollbackRows))
+
+	return &models.ImportBatchResponse{
+		ImportBatch: batch,
+		StatusText:  batch.GetStatusText(),
+	}, nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidImportProfile 导入方案定义不合法
+	ErrInvalidImportProfile = errors.New("invalid import profile")
+	// ErrImportProfileNotFound 导入方案不存在
+	ErrImportProfileNotFound = errors.New("import profile not found")
+)
+
+// ImportProfileService 导入配置方案服务
+type ImportProfileService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewImportProfileService 创建导入配置方案服务
+func NewImportProfileService(db *gorm.DB, logger *zap.Logger) *ImportProfileService {
+	return &ImportProfileService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateProfile 创建导入方案
+func (s *ImportProfileService) CreateProfile(institutionID string, req *models.ImportProfileRequest) (*models.ImportProfile, error) {
+	profile := &models.ImportProfile{
+		InstitutionID: institutionID,
+		Status:        models.ImportProfileStatusEnabled,
+	}
+	applyProfileRequest(profile, req)
+
+	if err := validateProfile(profile); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	s.db.Model(&models.ImportProfile{}).
+		Where("institution_id = ? AND name = ?", institutionID, profile.Name).
+		Count(&count)
+	if count > 0 {
+		return nil, fmt.Errorf("%w: name %s already exists", ErrInvalidImportProfile, profile.Name)
+	}
+
+	if err := s.db.Create(profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to create import profile: %w", err)
+	}
+
+	s.logger.Info("import profile created",
+		zap.String("institution", institutionID),
+		zap.String("name", profile.Name))
+
+	return profile, nil
+}
+
+// UpdateProfile 更新导入方案
+func (s *ImportProfileService) UpdateProfile(institutionID string, id uint, req *models.ImportProfileRequest) (*models.ImportProfile, error) {
+	profile, err := s.GetProfile(institutionID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyProfileRequest(profile, req)
+	if err := validateProfile(profile); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to update import profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// DeleteProfile 删除导入方案
+func (s *ImportProfileService) DeleteProfile(institutionID string, id uint) error {
+	result := s.db.Where("id = ? AND institution_id = ?", id, institutionID).Delete(&models.ImportProfile{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete import profile: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrImportProfileNotFound
+	}
+	return nil
+}
+
+// GetProfile 查询导入方案
+func (s *ImportProfileService) GetProfile(institutionID string, id uint) (*models.ImportProfile, error) {
+	var profile models.ImportProfile
+	if err := s.db.Where("id = ? AND institution_id = ?", id, institutionID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get import profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// ListProfiles 查询机构的导入方案列表
+func (s *ImportProfileService) ListProfiles(institutionID string) ([]models.ImportProfile, error) {
+	var profiles []models.ImportProfile
+	if err := s.db.Where("institution_id = ?", institutionID).Order("name").Find(&profiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list import profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// ResolveParseOptions 按方案名称解析导入选项, 名称为空时返回默认配置
+func (s *ImportProfileService) ResolveParseOptions(institutionID, name string) (*utils.ParseOptions, error) {
+	if name == "" {
+		return utils.DefaultParseOptions(), nil
+	}
+
+	var profile models.ImportProfile
+	if err := s.db.Where("institution_id = ? AND name = ? AND status = ?",
+		institutionID, name, models.ImportProfileStatusEnabled).
+		First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get import profile: %w", err)
+	}
+
+	return ToParseOptions(&profile), nil
+}
+
+// ToParseOptions 将导入方案转换为解析选项
+func ToParseOptions(profile *models.ImportProfile) *utils.ParseOptions {
+	opts := &utils.ParseOptions{
+		FileFormat:         profile.FileFormat,
+		Encoding:           profile.Encoding,
+		Delimiter:          profile.Delimiter,
+		Quote:              profile.QuoteChar,
+		FixedUnit:          profile.FixedUnit,
+		SkipLines:          profile.SkipLines,
+		SheetName:          profile.SheetName,
+		HeaderRow:          profile.HeaderRow,
+		Columns:            make(map[string]utils.ColumnSpec, len(profile.Columns)),
+		DateFormat:         profile.DateFormat,
+		DecimalSeparator:   profile.DecimalSeparator,
+		ThousandsSeparator: profile.ThousandsSeparator,
+		Defaults:           map[string]string(profile.Defaults),
+	}
+
+	for field, col := range profile.Columns {
+		opts.Columns[field] = utils.ColumnSpec{
+			Aliases:  col.Aliases,
+			Position: col.Position,
+		}
+	}
+
+	for _, col := range profile.FixedColumns {
+		opts.FixedColumns = append(opts.FixedColumns, utils.FixedWidthColumn{
+			Name:  col.Name,
+			Start: col.Start,
+			Width: col.Width,
+		})
+	}
+
+	return opts
+}
+
+// applyProfileRequest 将请求内容写入方案
+func applyProfileRequest(profile *models.ImportProfile, req *models.ImportProfileRequest) {
+	profile.Name = req.Name
+	profile.Description = req.Description
+	profile.FileFormat = req.FileFormat
+	profile.Encoding = req.Encoding
+	profile.Delimiter = req.Delimiter
+	profile.QuoteChar = req.QuoteChar
+	profile.FixedColumns = req.FixedColumns
+	profile.FixedUnit = req.FixedUnit
+	profile.SkipLines = req.SkipLines
+	profile.SheetName = req.SheetName
+	profile.HeaderRow = req.HeaderRow
+	profile.Columns = req.Columns
+	profile.DateFormat = req.DateFormat
+	profile.DecimalSeparator = req.DecimalSeparator
+	profile.ThousandsSeparator = req.ThousandsSeparator
+	profile.Defaults = req.Defaults
+
+	if profile.HeaderRow < 1 {
+		profile.HeaderRow = 1
+	}
+	if profile.DecimalSeparator == "" {
+		profile.DecimalSeparator = "."
+	}
+	if req.Status != nil {
+		profile.Status = *req.Status
+	}
+}
+
+// validateProfile 校验方案定义
+func validateProfile(profile *models.ImportProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidImportProfile)
+	}
+
+	for field, col := range profile.Columns {
+		if !utils.IsImportField(field) {
+			return fmt.Errorf("%w: unknown field %s", ErrInvalidImportProfile, field)
+		}
+		if col.Position < 0 {
+			return fmt.Errorf("%w: invalid position of field %s", ErrInvalidImportProfile, field)
+		}
+		if col.Position == 0 && len(col.Aliases) == 0 {
+			return fmt.Errorf("%w: field %s needs aliases or position", ErrInvalidImportProfile, field)
+		}
+	}
+
+	for field := range profile.Defaults {
+		if !utils.IsImportField(field) {
+			return fmt.Errorf("%w: unknown default field %s", ErrInvalidImportProfile, field)
+		}
+	}
+
+	// 对账单格式的字段由报文结构决定, 无需列映射
+	for _, field := range []string{utils.FieldBizID, utils.FieldAmount, utils.FieldSender, utils.FieldReceiver} {
+		if utils.IsStatementFormat(profile.FileFormat) {
+			break
+		}
+		_, mapped := profile.Columns[field]
+		_, defaulted := profile.Defaults[field]
+		if !mapped && !defaulted {
+			return fmt.Errorf("%w: required field %s is not mapped", ErrInvalidImportProfile, field)
+		}
+	}
+
+	switch profile.FileFormat {
+	case "", utils.FileFormatExcel, utils.FileFormatCSV, utils.FileFormatMT940, utils.FileFormatCamt053:
+	case utils.FileFormatFixedWidth:
+		if len(profile.FixedColumns) == 0 {
+			return fmt.Errorf("%w: fixed_width format needs fixed_columns", ErrInvalidImportProfile)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported file format %s", ErrInvalidImportProfile, profile.FileFormat)
+	}
+
+	switch profile.Encoding {
+	case "", utils.EncodingAuto, utils.EncodingUTF8, utils.EncodingGBK, utils.EncodingGB18030:
+	default:
+		return fmt.Errorf("%w: unsupported encoding %s", ErrInvalidImportProfile, profile.Encoding)
+	}
+
+	for _, col := range profile.FixedColumns {
+		if col.Name == "" || col.Start < 1 || col.Width < 1 {
+			return fmt.Errorf("%w: invalid fixed column %q", ErrInvalidImportProfile, col.Name)
+		}
+	}
+
+	if profile.SkipLines < 0 {
+		return fmt.Errorf("%w: skip_lines must not be negative", ErrInvalidImportProfile)
+	}
+
+	if profile.DecimalSeparator == profile.ThousandsSeparator {
+		return fmt.Errorf("%w: decimal and thousands separators must differ", ErrInvalidImportProfile)
+	}
+
+	return nil
+}
@@ -0,0 +1,573 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrMatchSourceNotFound 待匹配记录不存在
+	ErrMatchSourceNotFound = errors.New("transaction not found")
+	// ErrMatchNotEligible 记录不需要容差匹配(已对账成功、待上链或已确认配对)
+	ErrMatchNotEligible = errors.New("transaction is not eligible for tolerance matching")
+	// ErrMatchCounterpartyRequired 未指定对手机构
+	ErrMatchCounterpartyRequired = errors.New("counterparty is required")
+	// ErrMatchOutOfTolerance 配对超出容差
+	ErrMatchOutOfTolerance = errors.New("pairing is out of tolerance")
+	// ErrAdjustmentNotFound 调整记录不存在
+	ErrAdjustmentNotFound = errors.New("match adjustment not found")
+	// ErrAdjustmentNotProposed 调整记录已确认
+	ErrAdjustmentNotProposed = errors.New("match adjustment is already confirmed")
+)
+
+// 匹配得分权重, 合计100
+const (
+	scoreWeightAmount  = 50.0
+	scoreWeightDate    = 25.0
+	scoreWeightParties = 25.0
+)
+
+// unresolvedStatuses 需要容差匹配的交易状态: 单边上链未对账、对账失败
+var unresolvedStatuses = []int8{models.TxStatusUploaded, models.TxStatusMismatch}
+
+// MatchTolerance 容差参数
+type MatchTolerance struct {
+	AmountTolerance     string  // 金额绝对容差
+	AmountToleranceRate float64 // 金额相对容差
+	DateWindowDays      int     // 交易日期窗口(天)
+	RequireSameParties  bool    // 是否要求付款方/收款方一致
+	MinScore            float64 // 候选最低得分
+	MaxCandidates       int     // 每条记录返回的候选数量
+	PoolLimit           int     // 单次加载的对手方记录上限
+}
+
+// withDefaults 补齐未配置的容差参数
+func (t MatchTolerance) withDefaults() MatchTolerance {
+	if t.AmountTolerance == "" {
+		t.AmountTolerance = "0.05"
+	}
+	if t.DateWindowDays <= 0 {
+		t.DateWindowDays = 3
+	}
+	if t.MaxCandidates <= 0 {
+		t.MaxCandidates = 5
+	}
+	if t.PoolLimit <= 0 {
+		t.PoolLimit = 10000
+	}
+	return t
+}
+
+// MatchingService 容差匹配服务
+type MatchingService struct {
+	db            *gorm.DB
+	txService     *TransactionService // 确认或撤销配对时通过交易服务通知交易状态变化
+	encryptionKey string
+	tolerance     MatchTolerance
+	logger        *zap.Logger
+}
+
+// NewMatchingService 创建容差匹配服务
+func NewMatchingService(db *gorm.DB, txService *TransactionService, encryptionKey string, tolerance MatchTolerance, logger *zap.Logger) *MatchingService {
+	return &MatchingService{
+		db:            db,
+		txService:     txService,
+		encryptionKey: encryptionKey,
+		tolerance:     tolerance.withDefaults(),
+		logger:        logger,
+	}
+}
+
+// Tolerance 返回默认容差参数(副本), 调用方可按请求覆盖
+func (s *MatchingService) Tolerance() MatchTolerance {
+	return s.tolerance
+}
+
+// matchRecord 解密后的待匹配记录
+type matchRecord struct {
+	tx     models.Transaction
+	amount *big.Rat
+}
+
+// notAdjusted 排除已确认配对的记录
+func notAdjusted(db *gorm.DB) *gorm.DB {
+	return db.Where("biz_id NOT IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.MatchAdjustment{}).Select("biz_id")).
+		Where("biz_id NOT IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.MatchAdjustment{}).Select("counterparty_biz_id"))
+}
+
+// decryptRecords 解密金额
+func (s *MatchingService) decryptRecords(txs []models.Transaction) ([]matchRecord, error) {
+	records := make([]matchRecord, 0, len(txs))
+	for _, tx := range txs {
+		plain, err := utils.DecryptAmount(s.encryptionKey, tx.AmountCipher)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt amount of %s: %w", tx.BizID, err)
+		}
+		amount, err := utils.ParseAmount(plain)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, matchRecord{tx: tx, amount: amount})
+	}
+	return records, nil
+}
+
+// loadSource 加载本方待匹配记录
+func (s *MatchingService) loadSource(db *gorm.DB, institutionID, bizID string) (*matchRecord, error) {
+	var tx models.Transaction
+	if err := db.Where("institution_id = ? AND biz_id = ?", institutionID, bizID).First(&tx).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if tx.Status != models.TxStatusUploaded && tx.Status != models.TxStatusMismatch {
+		return nil, fmt.Errorf("%w: status is %s", ErrMatchNotEligible, tx.GetStatusText())
+	}
+	if tx.GroupID != nil {
+		// 组内交易状态随对账组同步, 不能逐笔配对
+		return nil, fmt.Errorf("%w: transaction belongs to a reconciliation group", ErrMatchNotEligible)
+	}
+
+	var adjusted int64
+	if err := db.Model(&models.MatchAdjustment{}).
+		Where("biz_id = ? OR counterparty_biz_id = ?", bizID, bizID).
+		Count(&adjusted).Error; err != nil {
+		return nil, fmt.Errorf("failed to check adjustment: %w", err)
+	}
+	if adjusted > 0 {
+		return nil, fmt.Errorf("%w: already paired", ErrMatchNotEligible)
+	}
+
+	records, err := s.decryptRecords([]models.Transaction{tx})
+	if err != nil {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+// loadPool 加载指定对手机构未解决的记录
+// 候选会返回对手方的业务流水号和金额差额, 必须指定对手机构, 不在所有机构的记录中查找
+func (s *MatchingService) loadPool(institutionID, counterparty string, tol MatchTolerance) ([]matchRecord, error) {
+	if counterparty == "" || counterparty == institutionID {
+		return nil, ErrMatchCounterpartyRequired
+	}
+	query := s.db.Model(&models.Transaction{}).
+		Where("institution_id = ? AND status IN ? AND group_id IS NULL", counterparty, unresolvedStatuses)
+
+	var txs []models.Transaction
+	if err := notAdjusted(query).Order("id DESC").Limit(tol.PoolLimit).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load counterparty records: %w", err)
+	}
+	if len(txs) == tol.PoolLimit {
+		s.logger.Warn("matching pool truncated", zap.Int("limit", tol.PoolLimit))
+	}
+	return s.decryptRecords(txs)
+}
+
+// score 计算候选配对得分, 超出容差时返回 false
+func score(src, cand *matchRecord, tol MatchTolerance) (*models.MatchCandidate, bool) {
+	// 币种不同的记录金额不可比, 不作为候选
+	if src.tx.Currency != cand.tx.Currency {
+		return nil, false
+	}
+
+	// 1. 金额: 允许差额取绝对容差与相对容差的较大值
+	delta := new(big.Rat).Sub(src.amount, cand.amount)
+	absDelta := new(big.Rat).Abs(delta)
+
+	allowed, err := utils.ParseAmount(tol.AmountTolerance)
+	if err != nil {
+		allowed = new(big.Rat)
+	}
+	if tol.AmountToleranceRate > 0 {
+		rate := new(big.Rat).SetFloat64(tol.AmountToleranceRate)
+		relative := new(big.Rat).Mul(new(big.Rat).Abs(src.amount), rate)
+		if relative.Cmp(allowed) > 0 {
+			allowed = relative
+		}
+	}
+	if absDelta.Cmp(allowed) > 0 {
+		return nil, false
+	}
+
+	total := scoreWeightAmount
+	if allowed.Sign() > 0 {
+		ratio, _ := new(big.Rat).Quo(absDelta, allowed).Float64()
+		total = scoreWeightAmount * (1 - ratio)
+	}
+
+	// 2. 交易日期: 双方均有日期时须在窗口内, 缺失时计一半分
+	c := &models.MatchCandidate{
+		BizID:             src.tx.BizID,
+		CounterpartyBizID: cand.tx.BizID,
+		Counterparty:      cand.tx.InstitutionID,
+		AmountDelta:       utils.FormatAmount(delta),
+	}
+	if src.tx.TxDate != nil && cand.tx.TxDate != nil {
+		days := int(math.Round(math.Abs(src.tx.TxDate.Sub(*cand.tx.TxDate).Hours()) / 24))
+		if days > tol.DateWindowDays {
+			return nil, false
+		}
+		c.DateDeltaDays = &days
+		total += scoreWeightDate * (1 - float64(days)/float64(tol.DateWindowDays+1))
+	} else {
+		total += scoreWeightDate / 2
+	}
+
+	// 3. 付款方/收款方
+	c.SenderMatched = sameParty(src.tx.Sender, cand.tx.Sender)
+	c.ReceiverMatched = sameParty(src.tx.Receiver, cand.tx.Receiver)
+	if tol.RequireSameParties && !(c.SenderMatched && c.ReceiverMatched) {
+		return nil, false
+	}
+	if c.SenderMatched {
+		total += scoreWeightParties / 2
+	}
+	if c.ReceiverMatched {
+		total += scoreWeightParties / 2
+	}
+
+	c.Score = math.Round(total*10) / 10
+	return c, c.Score >= tol.MinScore
+}
+
+// sameParty 比较付款方/收款方名称(忽略大小写和首尾空白)
+func sameParty(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	return a != "" && strings.EqualFold(a, b)
+}
+
+// rankCandidates 按得分降序排序, 返回前 MaxCandidates 个
+func rankCandidates(src *matchRecord, pool []matchRecord, tol MatchTolerance) []*models.MatchCandidate {
+	candidates := make([]*models.MatchCandidate, 0)
+	for i := range pool {
+		if c, ok := score(src, &pool[i], tol); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > tol.MaxCandidates {
+		candidates = candidates[:tol.MaxCandidates]
+	}
+	return candidates
+}
+
+// FindCandidates 查询单条记录的候选配对
+func (s *MatchingService) FindCandidates(institutionID, bizID, counterparty string, tol MatchTolerance) ([]*models.MatchCandidate, error) {
+	tol = tol.withDefaults()
+
+	src, err := s.loadSource(s.db, institutionID, bizID)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := s.loadPool(institutionID, counterparty, tol)
+	if err != nil {
+		return nil, err
+	}
+	return rankCandidates(src, pool, tol), nil
+}
+
+// Suggest 为机构的对账失败及单边记录批量生成配对建议
+// 仅返回存在候选的记录, limit 为扫描的本方记录上限
+func (s *MatchingService) Suggest(institutionID, counterparty string, tol MatchTolerance, limit int) ([]*models.MatchSuggestion, error) {
+	tol = tol.withDefaults()
+
+	var txs []models.Transaction
+	query := s.db.Model(&models.Transaction{}).
+		Where("institution_id = ? AND status IN ?", institutionID, unresolvedStatuses)
+	if err := notAdjusted(query).Order("id DESC").Limit(limit).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load unresolved records: %w", err)
+	}
+	sources, err := s.decryptRecords(txs)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := s.loadPool(institutionID, counterparty, tol)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*models.MatchSuggestion, 0)
+	for i := range sources {
+		candidates := rankCandidates(&sources[i], pool, tol)
+		if len(candidates) == 0 {
+			continue
+		}
+		suggestions = append(suggestions, &models.MatchSuggestion{
+			BizID:      sources[i].tx.BizID,
+			Status:     sources[i].tx.Status,
+			StatusText: sources[i].tx.GetStatusText(),
+			Candidates: candidates,
+		})
+	}
+	return suggestions, nil
+}
+
+// AcceptMatch 提出配对
+// 重新按容差校验配对并记录差额和原因, 生成待对手方确认的调整记录; 对手方确认前双方交易状态不变
+func (s *MatchingService) AcceptMatch(institutionID, operator string, req *models.AcceptMatchRequest, tol MatchTolerance) (*models.MatchAdjustment, error) {
+	tol = tol.withDefaults()
+	// 人工确认不受最低得分限制, 但仍须在金额、日期和付款方/收款方容差内
+	tol.MinScore = 0
+
+	var adjustment *models.MatchAdjustment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		locked := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Session(&gorm.Session{})
+
+		src, err := s.loadSource(locked, institutionID, req.BizID)
+		if err != nil {
+			return err
+		}
+
+		var candTx models.Transaction
+		if err := locked.Where("biz_id = ? AND institution_id <> ?", req.CounterpartyBizID, institutionID).
+			First(&candTx).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: counterparty record %s", ErrMatchSourceNotFound, req.CounterpartyBizID)
+			}
+			return fmt.Errorf("failed to get counterparty record: %w", err)
+		}
+		cand, err := s.loadSource(locked, candTx.InstitutionID, candTx.BizID)
+		if err != nil {
+			return err
+		}
+
+		c, ok := score(src, cand, tol)
+		if !ok {
+			return ErrMatchOutOfTolerance
+		}
+		dateDelta := 0
+		if c.DateDeltaDays != nil {
+			dateDelta = *c.DateDeltaDays
+		}
+
+		adjustment = &models.MatchAdjustment{
+			InstitutionID:     institutionID,
+			BizID:             req.BizID,
+			Counterparty:      candTx.InstitutionID,
+			CounterpartyBizID: req.CounterpartyBizID,
+			AmountDelta:       c.AmountDelta,
+			DateDeltaDays:     dateDelta,
+			Score:             c.Score,
+			AdjustmentType:    req.AdjustmentType,
+			Reason:            req.Reason,
+			Operator:          operator,
+			Status:            models.AdjustmentStatusProposed,
+		}
+		if err := tx.Create(adjustment).Error; err != nil {
+			return fmt.Errorf("failed to create adjustment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("tolerance match proposed",
+		zap.String("biz_id", adjustment.BizID),
+		zap.String("counterparty_biz_id", adjustment.CounterpartyBizID),
+		zap.String("amount_delta", adjustment.AmountDelta),
+		zap.String("operator", operator))
+
+	return adjustment, nil
+}
+
+// ConfirmAdjustment 对手方确认配对
+// 双方交易仍未对账时, 将双方交易状态更新为对账成功, 对账记录标记为容差匹配; 记录确认前的状态供撤销时恢复
+func (s *MatchingService) ConfirmAdjustment(institutionID, operator string, id uint) (*models.MatchAdjustment, error) {
+	var adjustment models.MatchAdjustment
+	var changes []statusChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND counterparty = ?", id, institutionID).
+			First(&adjustment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAdjustmentNotFound
+			}
+			return fmt.Errorf("failed to get adjustment: %w", err)
+		}
+		if adjustment.Status != models.AdjustmentStatusProposed {
+			return ErrAdjustmentNotProposed
+		}
+
+		pairs := []struct {
+			institutionID, bizID string
+			previous             *int8
+		}{
+			{adjustment.InstitutionID, adjustment.BizID, &adjustment.LocalStatus},
+			{adjustment.Counterparty, adjustment.CounterpartyBizID, &adjustment.CounterpartyStatus},
+		}
+		for _, p := range pairs {
+			var record models.Transaction
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("institution_id = ? AND biz_id = ?", p.institutionID, p.bizID).
+				First(&record).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: %s", ErrMatchSourceNotFound, p.bizID)
+				}
+				return fmt.Errorf("failed to get transaction: %w", err)
+			}
+			if record.Status != models.TxStatusUploaded && record.Status != models.TxStatusMismatch {
+				return fmt.Errorf("%w: %s status is %s", ErrMatchNotEligible, p.bizID, record.GetStatusText())
+			}
+
+			*p.previous = record.Status
+			if err := tx.Model(&models.Transaction{}).Where("id = ?", record.ID).
+				Update("status", models.TxStatusMatched).Error; err != nil {
+				return fmt.Errorf("failed to update transaction status: %w", err)
+			}
+			changes = append(changes, newStatusChange(record, models.TxStatusMatched))
+		}
+
+		now := time.Now()
+		for _, bizID := range []string{adjustment.BizID, adjustment.CounterpartyBizID} {
+			rec := &models.Reconciliation{
+				BizID:        bizID,
+				PartyA:       adjustment.InstitutionID,
+				PartyB:       adjustment.Counterparty,
+				Status:       models.ReconciliationStatusAdjusted,
+				MatchedAt:    &now,
+				AdjustmentID: &adjustment.ID,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "biz_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"party_a", "party_b", "status", "matched_at", "adjustment_id"}),
+			}).Create(rec).Error; err != nil {
+				return fmt.Errorf("failed to update reconciliation: %w", err)
+			}
+		}
+
+		adjustment.Status = models.AdjustmentStatusConfirmed
+		adjustment.ConfirmedBy = operator
+		adjustment.ConfirmedAt = &now
+		return tx.Model(&adjustment).Updates(map[string]interface{}{
+			"status":              adjustment.Status,
+			"local_status":        adjustment.LocalStatus,
+			"counterparty_status": adjustment.CounterpartyStatus,
+			"confirmed_by":        adjustment.ConfirmedBy,
+			"confirmed_at":        adjustment.ConfirmedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.txService.notifyStatusChanges(changes)
+
+	s.logger.Info("tolerance match confirmed",
+		zap.Uint("id", adjustment.ID),
+		zap.String("biz_id", adjustment.BizID),
+		zap.String("counterparty_biz_id", adjustment.CounterpartyBizID),
+		zap.String("operator", operator))
+
+	return &adjustment, nil
+}
+
+// ListAdjustments 分页查询机构参与的调整记录
+func (s *MatchingService) ListAdjustments(institutionID string, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.MatchAdjustment{}).
+		Where("institution_id = ? OR counterparty = ?", institutionID, institutionID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count adjustments: %w", err)
+	}
+
+	var adjustments []models.MatchAdjustment
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&adjustments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list adjustments: %w", err)
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  adjustments,
+	}, nil
+}
+
+// RevokeAdjustment 撤销配对, 提出方撤回或对手方拒绝均可
+// 已确认的配对将双方交易恢复为确认前状态: 链上对账失败的对账记录恢复为对账失败, 单边记录删除对账记录
+func (s *MatchingService) RevokeAdjustment(institutionID string, id uint) error {
+	var changes []statusChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var adjustment models.MatchAdjustment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND (institution_id = ? OR counterparty = ?)", id, institutionID, institutionID).
+			First(&adjustment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAdjustmentNotFound
+			}
+			return fmt.Errorf("failed to get adjustment: %w", err)
+		}
+
+		if adjustment.Status == models.AdjustmentStatusConfirmed {
+			pairs := []struct {
+				bizID    string
+				previous int8
+			}{
+				{adjustment.BizID, adjustment.LocalStatus},
+				{adjustment.CounterpartyBizID, adjustment.CounterpartyStatus},
+			}
+			for _, p := range pairs {
+				var record models.Transaction
+				err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("biz_id = ? AND status = ?", p.bizID, models.TxStatusMatched).
+					First(&record).Error
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("failed to get transaction: %w", err)
+				}
+				if err == nil {
+					if err := tx.Model(&models.Transaction{}).Where("id = ?", record.ID).
+						Update("status", p.previous).Error; err != nil {
+						return fmt.Errorf("failed to restore transaction status: %w", err)
+					}
+					changes = append(changes, newStatusChange(record, p.previous))
+				}
+
+				rec := tx.Model(&models.Reconciliation{}).Where("biz_id = ? AND adjustment_id = ?", p.bizID, adjustment.ID)
+				if p.previous == models.TxStatusMismatch {
+					err = rec.Updates(map[string]interface{}{
+						"status":        models.ReconciliationStatusMismatch,
+						"adjustment_id": nil,
+					}).Error
+				} else {
+					err = rec.Delete(&models.Reconciliation{}).Error
+				}
+				if err != nil {
+					return fmt.Errorf("failed to restore reconciliation: %w", err)
+				}
+			}
+		}
+
+		if err := tx.Delete(&adjustment).Error; err != nil {
+			return fmt.Errorf("failed to delete adjustment: %w", err)
+		}
+
+		s.logger.Info("tolerance match revoked",
+			zap.Uint("id", id),
+			zap.String("biz_id", adjustment.BizID),
+			zap.String("by", institutionID))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.txService.notifyStatusChanges(changes)
+	return nil
+}
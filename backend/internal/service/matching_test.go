@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestFindCandidatesScopedToCounterparty(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	db := newTestDB(t, &models.Transaction{}, &models.MatchAdjustment{})
+
+	for _, tx := range []struct {
+		bizID, institution, amount string
+	}{
+		{"SRC001", "INST001", "100.00"},
+		{"CP001", "INST002", "100.02"},
+		{"OTHER001", "INST003", "100.01"},
+	} {
+		cipher, err := utils.EncryptAmount(key, tx.amount)
+		if err != nil {
+			t.Fatalf("encrypt amount: %v", err)
+		}
+		if err := db.Create(&models.Transaction{
+			BizID:         tx.bizID,
+			InstitutionID: tx.institution,
+			AmountCipher:  cipher,
+			Currency:      "CNY",
+			Status:        models.TxStatusUploaded,
+		}).Error; err != nil {
+			t.Fatalf("create transaction: %v", err)
+		}
+	}
+
+	s := NewMatchingService(db, nil, key, MatchTolerance{}, zap.NewNop())
+
+	for _, counterparty := range []string{"", "INST001"} {
+		if _, err := s.FindCandidates("INST001", "SRC001", counterparty, MatchTolerance{}); !errors.Is(err, ErrMatchCounterpartyRequired) {
+			t.Errorf("FindCandidates(counterparty=%q) error = %v, want %v", counterparty, err, ErrMatchCounterpartyRequired)
+		}
+	}
+
+	candidates, err := s.FindCandidates("INST001", "SRC001", "INST002", MatchTolerance{})
+	if err != nil {
+		t.Fatalf("FindCandidates() error = %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].CounterpartyBizID != "CP001" {
+		t.Errorf("FindCandidates() = %+v, want only CP001", candidates)
+	}
+}
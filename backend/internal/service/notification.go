@@ -0,0 +1,518 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotificationUserNotFound 用户不存在
+	ErrNotificationUserNotFound = errors.New("user not found")
+	// ErrNotificationNoEmail 用户未登记邮箱
+	ErrNotificationNoEmail = errors.New("user has no email address")
+)
+
+// digestListLimit 摘要中每类明细最多列出的条数
+const digestListLimit = 20
+
+// Mailer 邮件发送
+type Mailer interface {
+	Send(to []string, subject, body string) error
+}
+
+// NotificationOptions 邮件通知参数
+type NotificationOptions struct {
+	DefaultLanguage string // 用户未设置时的邮件语言
+	DigestHour      int    // 每日摘要发送时间(0-23点), 负数表示不发送
+}
+
+// NotificationService 邮件通知服务
+// 对账失败时向订阅用户即时发信, 每日汇总新增对账失败、逾期单边上链和上链失败记录
+type NotificationService struct {
+	db           *gorm.DB
+	mailer       Mailer
+	agingService *AgingService
+	options      NotificationOptions
+	logger       *zap.Logger
+}
+
+// NewNotificationService 创建邮件通知服务
+func NewNotificationService(db *gorm.DB, mailer Mailer, agingService *AgingService, options NotificationOptions, logger *zap.Logger) *NotificationService {
+	if options.DefaultLanguage != models.NotificationLanguageEN {
+		options.DefaultLanguage = models.NotificationLanguageZH
+	}
+	if options.DigestHour > 23 {
+		options.DigestHour = 8
+	}
+	return &NotificationService{
+		db:           db,
+		mailer:       mailer,
+		agingService: agingService,
+		options:      options,
+		logger:       logger,
+	}
+}
+
+// ========== 邮件模板 ==========
+
+// mailTemplate 邮件模板(主题和正文)
+type mailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// render 渲染邮件主题和正文
+func (t *mailTemplate) render(data interface{}) (string, string, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("failed to render mail subject: %w", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("failed to render mail body: %w", err)
+	}
+	return subject.String(), body.String(), nil
+}
+
+var mailFuncs = template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"date":     func(t time.Time) string { return t.Format("2006-01-02") },
+	"more":     func(total int64, shown int) int64 { return total - int64(shown) },
+}
+
+// newMailTemplate 解析邮件模板
+func newMailTemplate(name, subject, body string) *mailTemplate {
+	return &mailTemplate{
+		subject: template.Must(template.New(name + ".subject").Funcs(mailFuncs).Parse(subject)),
+		body:    template.Must(template.New(name + ".body").Funcs(mailFuncs).Parse(body)),
+	}
+}
+
+// 邮件模板, 按语言区分
+var (
+	mismatchTemplates = map[string]*mailTemplate{
+		models.NotificationLanguageZH: newMailTemplate("mismatch_zh",
+			"【对账失败】{{.Tx.BizID}}",
+			`{{.Username}}，您好：
+
+机构 {{.Tx.InstitutionID}} 的交易 {{.Tx.BizID}} 链上对账失败，双方提交的数据哈希不一致。
+
+币种：{{.Tx.Currency}}
+发现时间：{{datetime .Time}}
+
+可在系统中发起字段诊断，定位不一致的字段。
+
+—— 区块链对账系统
+`),
+		models.NotificationLanguageEN: newMailTemplate("mismatch_en",
+			"[Reconciliation mismatch] {{.Tx.BizID}}",
+			`Hello {{.Username}},
+
+Transaction {{.Tx.BizID}} of institution {{.Tx.InstitutionID}} failed on-chain reconciliation: the data hashes submitted by the two parties differ.
+
+Currency: {{.Tx.Currency}}
+Detected at: {{datetime .Time}}
+
+Start a field diagnosis in the system to find the fields that differ.
+
+-- Blockchain Reconciliation System
+`),
+	}
+
+	digestTemplates = map[string]*mailTemplate{
+		models.NotificationLanguageZH: newMailTemplate("digest_zh",
+			"【对账日报】{{.Digest.InstitutionID}} {{date .Digest.Until}}",
+			`{{.Username}}，您好：
+
+以下为 {{datetime .Digest.Since}} 至 {{datetime .Digest.Until}} 的对账摘要。
+
+一、新增对账失败：{{.Digest.MismatchCount}} 笔
+{{range .Digest.Mismatches}}  - {{.BizID}}（{{.Currency}}）{{datetime .UpdatedAt}}
+{{end}}{{if gt (more .Digest.MismatchCount (len .Digest.Mismatches)) 0}}  …… 其余 {{more .Digest.MismatchCount (len .Digest.Mismatches)}} 笔请在系统中查看
+{{end}}
+二、逾期单边上链（超过 {{.Digest.SLADays}} 天对手方未上链）：{{.Digest.OverdueCount}} 笔
+{{range .Digest.OverdueParties}}  - {{.Counterparty}}：逾期 {{.Overdue}} 笔，最长 {{.OldestDays}} 天
+{{end}}
+三、上链失败待重试：{{.Digest.FailedCount}} 笔
+{{range .Digest.FailedSubmissions}}  - {{.BizID}} {{datetime .FailedAt}}
+{{end}}{{if gt (more .Digest.FailedCount (len .Digest.FailedSubmissions)) 0}}  …… 其余 {{more .Digest.FailedCount (len .Digest.FailedSubmissions)}} 笔请在系统中查看
+{{end}}
+—— 区块链对账系统
+`),
+		models.NotificationLanguageEN: newMailTemplate("digest_en",
+			"[Reconciliation digest] {{.Digest.InstitutionID}} {{date .Digest.Until}}",
+			`Hello {{.Username}},
+
+Reconciliation digest from {{datetime .Digest.Since}} to {{datetime .Digest.Until}}.
+
+1. New mismatches: {{.Digest.MismatchCount}}
+{{range .Digest.Mismatches}}  - {{.BizID}} ({{.Currency}}) {{datetime .UpdatedAt}}
+{{end}}{{if gt (more .Digest.MismatchCount (len .Digest.Mismatches)) 0}}  ... and {{more .Digest.MismatchCount (len .Digest.Mismatches)}} more in the system
+{{end}}
+2. Overdue one-sided uploads (counterparty missing for {{.Digest.SLADays}}+ days): {{.Digest.OverdueCount}}
+{{range .Digest.OverdueParties}}  - {{.Counterparty}}: {{.Overdue}} overdue, oldest {{.OldestDays}} days
+{{end}}
+3. Failed chain submissions awaiting retry: {{.Digest.FailedCount}}
+{{range .Digest.FailedSubmissions}}  - {{.BizID}} {{datetime .FailedAt}}
+{{end}}{{if gt (more .Digest.FailedCount (len .Digest.FailedSubmissions)) 0}}  ... and {{more .Digest.FailedCount (len .Digest.FailedSubmissions)}} more in the system
+{{end}}
+-- Blockchain Reconciliation System
+`),
+	}
+
+	testTemplates = map[string]*mailTemplate{
+		models.NotificationLanguageZH: newMailTemplate("test_zh",
+			"【测试邮件】区块链对账系统",
+			`{{.Username}}，您好：
+
+这是一封测试邮件，收到即表示邮件通知配置正确。
+
+—— 区块链对账系统
+`),
+		models.NotificationLanguageEN: newMailTemplate("test_en",
+			"[Test] Blockchain Reconciliation System",
+			`Hello {{.Username}},
+
+This is a test message. Receiving it means mail notifications are configured correctly.
+
+-- Blockchain Reconciliation System
+`),
+	}
+)
+
+// ========== 订阅偏好 ==========
+
+// recipient 收件用户
+type recipient struct {
+	UserID        uint
+	Username      string
+	Email         string
+	InstitutionID string
+	Language      string
+	LastDigestAt  *time.Time
+}
+
+// language 收件语言, 未设置时使用默认语言
+func (s *NotificationService) language(lang string) string {
+	if lang == models.NotificationLanguageZH || lang == models.NotificationLanguageEN {
+		return lang
+	}
+	return s.options.DefaultLanguage
+}
+
+// getUser 按用户名查询用户
+func (s *NotificationService) getUser(username string) (*models.User, error) {
+	var user models.User
+	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// getPreference 查询用户的通知偏好, 未设置时返回默认偏好(不接收邮件)
+func (s *NotificationService) getPreference(user *models.User) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := s.db.Where("user_id = ?", user.ID).First(&pref).Error
+	switch {
+	case err == nil:
+		return &pref, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &models.NotificationPreference{UserID: user.ID, Language: s.options.DefaultLanguage}, nil
+	default:
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+}
+
+// GetPreference 查询当前用户的通知偏好
+func (s *NotificationService) GetPreference(username string) (*models.NotificationPreferenceResponse, error) {
+	user, err := s.getUser(username)
+	if err != nil {
+		return nil, err
+	}
+	pref, err := s.getPreference(user)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NotificationPreferenceResponse{Username: user.Username, Email: user.Email, NotificationPreference: pref}, nil
+}
+
+// UpdatePreference 更新当前用户的通知偏好
+func (s *NotificationService) UpdatePreference(username string, req *models.UpdateNotificationPreferenceRequest) (*models.NotificationPreferenceResponse, error) {
+	user, err := s.getUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" && ((req.ImmediateMismatch != nil && *req.ImmediateMismatch) || (req.DailyDigest != nil && *req.DailyDigest)) {
+		return nil, ErrNotificationNoEmail
+	}
+
+	pref, err := s.getPreference(user)
+	if err != nil {
+		return nil, err
+	}
+	if req.Language != "" {
+		pref.Language = req.Language
+	}
+	if req.ImmediateMismatch != nil {
+		pref.ImmediateMismatch = *req.ImmediateMismatch
+	}
+	if req.DailyDigest != nil {
+		pref.DailyDigest = *req.DailyDigest
+	}
+	if err := s.db.Save(pref).Error; err != nil {
+		return nil, fmt.Errorf("failed to save notification preference: %w", err)
+	}
+
+	return &models.NotificationPreferenceResponse{Username: user.Username, Email: user.Email, NotificationPreference: pref}, nil
+}
+
+// subscribers 查询订阅了指定通知的启用用户; institutionID 为空时不限机构
+func (s *NotificationService) subscribers(column, institutionID string) ([]recipient, error) {
+	query := s.db.Table("notification_preferences p").
+		Select("u.id AS user_id, u.username, u.email, u.institution_id, p.language, p.last_digest_at").
+		Joins("JOIN users u ON u.id = p.user_id").
+		Where("p."+column+" = ?", true).
+		Where("u.status = ? AND u.email <> ''", models.UserStatusEnabled)
+	if institutionID != "" {
+		query = query.Where("u.institution_id = ?", institutionID)
+	}
+
+	var recipients []recipient
+	if err := query.Order("u.id").Scan(&recipients).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notification subscribers: %w", err)
+	}
+	return recipients, nil
+}
+
+// send 按收件人语言渲染模板并发送
+func (s *NotificationService) send(templates map[string]*mailTemplate, to *recipient, data map[string]interface{}) error {
+	data["Username"] = to.Username
+	subject, body, err := templates[s.language(to.Language)].render(data)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send([]string{to.Email}, subject, body)
+}
+
+// ========== 即时通知 ==========
+
+// NotifyStatusChange 交易对账失败时向订阅了即时通知的用户发信, 发送在后台执行
+func (s *NotificationService) NotifyStatusChange(tx *models.Transaction, previous int8) {
+	if tx.Status != models.TxStatusMismatch {
+		return
+	}
+
+	recipients, err := s.subscribers("immediate_mismatch", tx.InstitutionID)
+	if err != nil {
+		s.logger.Error("failed to load mismatch subscribers", zap.String("biz_id", tx.BizID), zap.Error(err))
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	snapshot := *tx
+	now := time.Now()
+	go func() {
+		for i := range recipients {
+			data := map[string]interface{}{"Tx": &snapshot, "Time": now}
+			if err := s.send(mismatchTemplates, &recipients[i], data); err != nil {
+				s.logger.Warn("failed to send mismatch mail",
+					zap.String("biz_id", snapshot.BizID),
+					zap.String("user", recipients[i].Username),
+					zap.Error(err))
+			}
+		}
+	}()
+}
+
+// SendTestMail 向当前用户发送测试邮件
+func (s *NotificationService) SendTestMail(username string) error {
+	user, err := s.getUser(username)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return ErrNotificationNoEmail
+	}
+	pref, err := s.getPreference(user)
+	if err != nil {
+		return err
+	}
+
+	to := &recipient{UserID: user.ID, Username: user.Username, Email: user.Email, Language: pref.Language}
+	return s.send(testTemplates, to, map[string]interface{}{})
+}
+
+// ========== 每日摘要 ==========
+
+// BuildDigest 汇总机构在 [since, until) 内新增的对账失败、上链失败记录及当前逾期的单边上链记录
+func (s *NotificationService) BuildDigest(institutionID string, since, until time.Time) (*models.Digest, error) {
+	digest := &models.Digest{
+		InstitutionID:     institutionID,
+		Since:             since,
+		Until:             until,
+		Mismatches:        make([]*models.DigestMismatch, 0),
+		OverdueParties:    make([]*models.AgingRow, 0),
+		FailedSubmissions: make([]*models.DigestFailedSubmission, 0),
+	}
+
+	mismatches := s.db.Model(&models.Transaction{}).
+		Where("institution_id = ? AND status = ?", institutionID, models.TxStatusMismatch).
+		Where("updated_at >= ? AND updated_at < ?", since, until)
+	if err := mismatches.Session(&gorm.Session{}).Count(&digest.MismatchCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count mismatches: %w", err)
+	}
+	if err := mismatches.Session(&gorm.Session{}).
+		Select("biz_id, currency, updated_at").
+		Order("updated_at DESC").
+		Limit(digestListLimit).
+		Scan(&digest.Mismatches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list mismatches: %w", err)
+	}
+
+	// 上链失败且交易仍待上链(重新上链成功后回执会被覆盖为成功)
+	failed := s.db.Table("chain_receipts cr").
+		Joins("JOIN transactions t ON t.biz_id = cr.biz_id").
+		Where("t.institution_id = ? AND t.status = ?", institutionID, models.TxStatusPending).
+		Where("cr.status = ? AND cr.created_at >= ? AND cr.created_at < ?", models.ChainReceiptStatusFailed, since, until)
+	if err := failed.Session(&gorm.Session{}).Count(&digest.FailedCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed submissions: %w", err)
+	}
+	if err := failed.Session(&gorm.Session{}).
+		Select("cr.biz_id, cr.created_at AS failed_at").
+		Order("cr.created_at DESC").
+		Limit(digestListLimit).
+		Scan(&digest.FailedSubmissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list failed submissions: %w", err)
+	}
+
+	if s.agingService != nil {
+		report, err := s.agingService.Report(institutionID, "", until)
+		if err != nil {
+			return nil, err
+		}
+		digest.SLADays = report.SLADays
+		digest.OverdueCount = report.Overdue
+		for _, row := range report.Rows {
+			if row.Overdue > 0 && len(digest.OverdueParties) < digestListLimit {
+				digest.OverdueParties = append(digest.OverdueParties, row)
+			}
+		}
+	}
+
+	return digest, nil
+}
+
+// digestSince 摘要起始时间: 上次摘要截止时间, 首次发送时取前24小时
+func digestSince(last *time.Time, now time.Time) time.Time {
+	if last != nil && last.Before(now) {
+		return *last
+	}
+	return now.Add(-24 * time.Hour)
+}
+
+// SendDigests 向订阅了每日摘要的用户发送摘要, 返回发送的封数
+// 没有需要关注的内容时不发信, 但仍推进摘要窗口
+func (s *NotificationService) SendDigests(now time.Time) (int, error) {
+	recipients, err := s.subscribers("daily_digest", "")
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range recipients {
+		to := &recipients[i]
+		digest, err := s.BuildDigest(to.InstitutionID, digestSince(to.LastDigestAt, now), now)
+		if err != nil {
+			return sent, err
+		}
+
+		if !digest.Empty() {
+			if err := s.send(digestTemplates, to, map[string]interface{}{"Digest": digest}); err != nil {
+				// 发送失败时不推进窗口, 下次摘要会包含本次内容
+				s.logger.Warn("failed to send digest mail", zap.String("user", to.Username), zap.Error(err))
+				continue
+			}
+			sent++
+		}
+
+		if err := s.db.Model(&models.NotificationPreference{}).
+			Where("user_id = ?", to.UserID).
+			Update("last_digest_at", now).Error; err != nil {
+			return sent, fmt.Errorf("failed to update digest window: %w", err)
+		}
+	}
+
+	s.logger.Info("daily digests sent", zap.Int("recipients", len(recipients)), zap.Int("sent", sent))
+	return sent, nil
+}
+
+// SendDigestNow 立即向当前用户发送摘要(即使没有需要关注的内容), 不推进摘要窗口
+func (s *NotificationService) SendDigestNow(username string) (*models.Digest, error) {
+	user, err := s.getUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, ErrNotificationNoEmail
+	}
+	pref, err := s.getPreference(user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	digest, err := s.BuildDigest(user.InstitutionID, digestSince(pref.LastDigestAt, now), now)
+	if err != nil {
+		return nil, err
+	}
+
+	to := &recipient{UserID: user.ID, Username: user.Username, Email: user.Email, InstitutionID: user.InstitutionID, Language: pref.Language}
+	if err := s.send(digestTemplates, to, map[string]interface{}{"Digest": digest}); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// nextDigestTime 下一次摘要发送时间
+func nextDigestTime(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// RunDailyDigest 每天在配置的整点发送摘要, 直到 ctx 取消
+func (s *NotificationService) RunDailyDigest(ctx context.Context) {
+	if s.options.DigestHour < 0 {
+		return
+	}
+	s.logger.Info("daily digest scheduler started", zap.Int("hour", s.options.DigestHour))
+
+	for {
+		timer := time.NewTimer(time.Until(nextDigestTime(time.Now(), s.options.DigestHour)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("daily digest scheduler stopped")
+			return
+		case <-timer.C:
+			if _, err := s.SendDigests(time.Now()); err != nil {
+				s.logger.Error("failed to send daily digests", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReceiptTracker 上链回执追踪
+// 轮询已提交交易的回执: 执行成功转为已上链; 执行失败或超过区块限制仍未打包时退回待上链, 由调用方重新上传
+type ReceiptTracker struct {
+	db         *gorm.DB
+	blockchain *blockchain.Client
+	txService  *TransactionService
+	logger     *zap.Logger
+}
+
+// NewReceiptTracker 创建回执追踪
+func NewReceiptTracker(db *gorm.DB, bc *blockchain.Client, txService *TransactionService, logger *zap.Logger) *ReceiptTracker {
+	return &ReceiptTracker{
+		db:         db,
+		blockchain: bc,
+		txService:  txService,
+		logger:     logger,
+	}
+}
+
+// Run 按固定间隔追踪回执, 直到 ctx 取消
+func (t *ReceiptTracker) Run(ctx context.Context, interval time.Duration) {
+	t.logger.Info("receipt tracker started", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.logger.Info("receipt tracker stopped")
+			return
+		case <-ticker.C:
+			if _, err := t.TrackSubmitted(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				t.logger.Error("receipt tracking failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// TrackSubmitted 检查所有已提交交易的回执, 返回状态发生变化的笔数
+func (t *ReceiptTracker) TrackSubmitted(ctx context.Context) (int, error) {
+	var count int64
+	if err := t.db.Model(&models.Transaction{}).
+		Where("status = ? AND group_id IS NULL", models.TxStatusSubmitted).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	// 先读取区块高度再查询回执: 高度已超过区块限制且之后仍查不到回执, 交易不会再被打包
+	blockNumber, err := t.blockchain.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	var txs []models.Transaction
+	err = t.db.Where("status = ? AND group_id IS NULL", models.TxStatusSubmitted).
+		FindInBatches(&txs, 100, func(_ *gorm.DB, _ int) error {
+			for i := range txs {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				ok, err := t.track(ctx, &txs[i], blockNumber)
+				if err != nil {
+					t.logger.Warn("failed to track receipt", zap.String("biz_id", txs[i].BizID), zap.Error(err))
+					continue
+				}
+				if ok {
+					changed++
+				}
+			}
+			return nil
+		}).Error
+	return changed, err
+}
+
+// track 检查单笔已提交交易的回执, 返回是否已确认或退回
+func (t *ReceiptTracker) track(ctx context.Context, tx *models.Transaction, blockNumber int64) (bool, error) {
+	var receipt models.ChainReceipt
+	err := t.db.Where("biz_id = ?", tx.BizID).First(&receipt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && receipt.TxHash == "") {
+		// 没有交易哈希无从追踪, 直接退回
+		t.txService.ResetSubmission(tx, models.ChainReceiptStatusFailed)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// 回执已由其他流程(如链索引重建)写入
+	if receipt.Status == models.ChainReceiptStatusSuccess {
+		return true, t.txService.ConfirmSubmission(ctx, tx, &receipt)
+	}
+
+	info, err := t.blockchain.GetTransactionReceipt(ctx, receipt.TxHash)
+	if err != nil {
+		return false, err
+	}
+	if info == nil {
+		if receipt.BlockLimit > 0 && blockNumber >= receipt.BlockLimit {
+			t.logger.Warn("submitted transaction expired",
+				zap.String("biz_id", tx.BizID),
+				zap.String("tx_hash", receipt.TxHash),
+				zap.Int64("block_limit", receipt.BlockLimit),
+				zap.Int64("block_number", blockNumber))
+			t.txService.ResetSubmission(tx, models.ChainReceiptStatusFailed)
+			return true, nil
+		}
+		return false, nil
+	}
+
+	if !info.Success {
+		t.logger.Warn("submitted transaction failed on chain",
+			zap.String("biz_id", tx.BizID),
+			zap.String("tx_hash", receipt.TxHash),
+			zap.String("reason", info.Message))
+		t.txService.ResetSubmission(tx, models.ChainReceiptStatusFailed)
+		return true, nil
+	}
+
+	receipt.BlockHeight = info.BlockNumber
+	receipt.BlockHash = info.BlockHash
+	receipt.GasUsed = info.GasUsed
+	receipt.Status = models.ChainReceiptStatusSuccess
+	receipt.CreatedAt = time.Now()
+	return true, t.txService.ConfirmSubmission(ctx, tx, &receipt)
+}
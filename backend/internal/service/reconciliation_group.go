@@ -0,0 +1,660 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/blockchain"
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrGroupNotFound 对账组不存在
+	ErrGroupNotFound = errors.New("reconciliation group not found")
+	// ErrGroupExists 对账组参考号已存在
+	ErrGroupExists = errors.New("reconciliation group already exists")
+	// ErrGroupNotPending 对账组已上链
+	ErrGroupNotPending = errors.New("reconciliation group already uploaded")
+	// ErrGroupInvalid 对账组内容不合法
+	ErrGroupInvalid = errors.New("invalid reconciliation group")
+)
+
+// GroupService 对账组服务
+type GroupService struct {
+	db            *gorm.DB
+	blockchain    *blockchain.Client
+	txService     *TransactionService // 组内交易状态变化通过交易服务通知订阅方
+	encryptionKey string
+	logger        *zap.Logger
+}
+
+// NewGroupService 创建对账组服务
+func NewGroupService(db *gorm.DB, bc *blockchain.Client, txService *TransactionService, encryptionKey string, logger *zap.Logger) *GroupService {
+	return &GroupService{
+		db:            db,
+		blockchain:    bc,
+		txService:     txService,
+		encryptionKey: encryptionKey,
+		logger:        logger,
+	}
+}
+
+// groupInvalid 构造对账组校验错误
+func groupInvalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrGroupInvalid, fmt.Sprintf(format, args...))
+}
+
+// CreateGroup 创建对账组
+// 明细关联本方交易时金额取自交易密文解密结果, 关联的交易须为待上链且未归入其他组;
+// 指定汇总交易时其金额须等于明细合计. 创建后关联交易不能再单独上链, 由组承诺统一上链对账
+func (s *GroupService) CreateGroup(institutionID string, req *models.CreateGroupRequest) (*models.GroupResponse, error) {
+	var group *models.ReconciliationGroup
+
+	// 组参考号作为链上 bytes32 ID, 超过32字节会被截断
+	if len(req.GroupRef) > 32 {
+		return nil, groupInvalid("组参考号不能超过32字节")
+	}
+	if blockchain.IsAnchorID(req.GroupRef) {
+		return nil, groupInvalid("组参考号不能以 %s 开头", blockchain.AnchorIDPrefix)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ReconciliationGroup{}).
+			Where("institution_id = ? AND group_ref = ?", institutionID, req.GroupRef).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check group: %w", err)
+		}
+		if count > 0 {
+			return ErrGroupExists
+		}
+
+		// 组参考号即链上ID, 不能与汇总交易以外的本方交易冲突
+		var refTx models.Transaction
+		if err := tx.Where("biz_id = ?", req.GroupRef).First(&refTx).Error; err == nil && refTx.BizID != req.ParentBizID {
+			return groupInvalid("组参考号与交易 %s 的业务流水号冲突", refTx.BizID)
+		}
+
+		// 锁定并校验关联的本方交易
+		bizIDs := make([]string, 0, len(req.Items)+1)
+		for _, item := range req.Items {
+			if item.BizID != "" {
+				bizIDs = append(bizIDs, item.BizID)
+			}
+		}
+		if req.ParentBizID != "" {
+			bizIDs = append(bizIDs, req.ParentBizID)
+		}
+		linked, err := s.lockTransactions(tx, institutionID, bizIDs)
+		if err != nil {
+			return err
+		}
+		currency, err := groupCurrency(req.Currency, linked)
+		if err != nil {
+			return err
+		}
+
+		// 计算明细承诺和合计金额
+		items := make([]models.ReconciliationGroupItem, 0, len(req.Items))
+		itemHashes := make([]string, 0, len(req.Items))
+		amounts := make([]string, 0, len(req.Items))
+		seen := make(map[string]bool, len(req.Items))
+		for i, item := range req.Items {
+			childRef, amount, err := s.resolveItem(item, linked)
+			if err != nil {
+				return groupInvalid("第%d条明细: %s", i+1, err.Error())
+			}
+			if seen[childRef] {
+				return groupInvalid("明细参考号 %s 重复", childRef)
+			}
+			seen[childRef] = true
+
+			cipher, err := utils.EncryptAmount(s.encryptionKey, amount)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt amount: %w", err)
+			}
+			itemHash := utils.CalculateGroupItemHash(req.GroupKey, childRef, amount, currency)
+			items = append(items, models.ReconciliationGroupItem{
+				Seq:          i + 1,
+				ChildRef:     childRef,
+				BizID:        item.BizID,
+				AmountCipher: cipher,
+				ItemHash:     itemHash,
+			})
+			itemHashes = append(itemHashes, itemHash)
+			amounts = append(amounts, amount)
+		}
+
+		total, err := utils.SumAmounts(amounts)
+		if err != nil {
+			return groupInvalid("%s", err.Error())
+		}
+
+		groupType := models.GroupTypeManyToOne
+		if req.ParentBizID != "" {
+			groupType = models.GroupTypeOneToMany
+			parentAmount, err := utils.DecryptAmount(s.encryptionKey, linked[req.ParentBizID].AmountCipher)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt parent amount: %w", err)
+			}
+			if !utils.AmountsEqual(parentAmount, total) {
+				return groupInvalid("汇总交易金额 %s 与明细合计 %s 不一致", parentAmount, total)
+			}
+		}
+
+		totalCipher, err := utils.EncryptAmount(s.encryptionKey, total)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt amount: %w", err)
+		}
+
+		group = &models.ReconciliationGroup{
+			GroupRef:      req.GroupRef,
+			InstitutionID: institutionID,
+			Counterparty:  req.Counterparty,
+			GroupType:     groupType,
+			ParentBizID:   req.ParentBizID,
+			Currency:      currency,
+			TotalCipher:   totalCipher,
+			ItemCount:     len(items),
+			Commitment:    utils.CalculateGroupCommitment(req.GroupKey, req.GroupRef, itemHashes, total, currency),
+			Status:        models.GroupStatusPending,
+		}
+		if err := tx.Create(group).Error; err != nil {
+			return fmt.Errorf("failed to create group: %w", err)
+		}
+		for i := range items {
+			items[i].GroupID = group.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return fmt.Errorf("failed to create group items: %w", err)
+		}
+
+		if len(bizIDs) > 0 {
+			if err := tx.Model(&models.Transaction{}).
+				Where("biz_id IN ?", bizIDs).
+				Update("group_id", group.ID).Error; err != nil {
+				return fmt.Errorf("failed to link transactions: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("reconciliation group created",
+		zap.String("group_ref", group.GroupRef),
+		zap.String("type", group.GroupType),
+		zap.Int("items", group.ItemCount))
+
+	return s.GetGroup(institutionID, group.GroupRef)
+}
+
+// lockTransactions 锁定并校验组内关联的本方交易
+func (s *GroupService) lockTransactions(tx *gorm.DB, institutionID string, bizIDs []string) (map[string]*models.Transaction, error) {
+	linked := make(map[string]*models.Transaction, len(bizIDs))
+	if len(bizIDs) == 0 {
+		return linked, nil
+	}
+
+	var txs []models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("biz_id IN ?", bizIDs).
+		Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock transactions: %w", err)
+	}
+	for i := range txs {
+		linked[txs[i].BizID] = &txs[i]
+	}
+
+	seen := make(map[string]bool, len(bizIDs))
+	currency := ""
+	for _, bizID := range bizIDs {
+		if seen[bizID] {
+			return nil, groupInvalid("交易 %s 重复关联", bizID)
+		}
+		seen[bizID] = true
+
+		t, ok := linked[bizID]
+		switch {
+		case !ok || t.InstitutionID != institutionID:
+			return nil, groupInvalid("交易 %s 不存在", bizID)
+		case t.Status != models.TxStatusPending:
+			return nil, groupInvalid("交易 %s 状态为%s, 仅待上链交易可归组", bizID, t.GetStatusText())
+		case t.GroupID != nil:
+			return nil, groupInvalid("交易 %s 已归入其他对账组", bizID)
+		case currency != "" && t.Currency != currency:
+			return nil, groupInvalid("交易 %s 币种为%s, 组内交易币种须一致(%s)", bizID, t.Currency, currency)
+		}
+		currency = t.Currency
+	}
+	return linked, nil
+}
+
+// groupCurrency 确定对账组币种, 指定币种时须与关联交易的币种一致
+func groupCurrency(requested string, linked map[string]*models.Transaction) (string, error) {
+	currency := ""
+	if requested != "" {
+		if !utils.IsValidCurrency(requested) {
+			return "", groupInvalid("币种须为ISO 4217货币代码")
+		}
+		currency = utils.NormalizeCurrency(requested)
+	}
+	for bizID, t := range linked {
+		txCurrency := t.Currency
+		if txCurrency == "" {
+			txCurrency = utils.DefaultCurrency
+		}
+		if currency == "" {
+			currency = txCurrency
+		} else if txCurrency != currency {
+			return "", groupInvalid("交易 %s 币种为%s, 与组币种%s不一致", bizID, txCurrency, currency)
+		}
+	}
+	if currency == "" {
+		currency = utils.DefaultCurrency
+	}
+	return currency, nil
+}
+
+// resolveItem 确定明细参考号和金额
+func (s *GroupService) resolveItem(item models.GroupItemRequest, linked map[string]*models.Transaction) (string, string, error) {
+	childRef := item.ChildRef
+	amount := item.Amount
+
+	if item.BizID != "" {
+		if childRef == "" {
+			childRef = item.BizID
+		}
+		txAmount, err := utils.DecryptAmount(s.encryptionKey, linked[item.BizID].AmountCipher)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decrypt amount: %w", err)
+		}
+		if amount != "" && !utils.AmountsEqual(amount, txAmount) {
+			return "", "", fmt.Errorf("金额 %s 与交易金额不一致", amount)
+		}
+		amount = txAmount
+	}
+
+	if childRef == "" {
+		return "", "", fmt.Errorf("明细参考号不能为空")
+	}
+	if _, err := utils.ParseAmount(amount); err != nil || amount == "" {
+		return "", "", fmt.Errorf("金额格式错误")
+	}
+	return childRef, amount, nil
+}
+
+// getGroup 查询机构的对账组
+func (s *GroupService) getGroup(institutionID, groupRef string) (*models.ReconciliationGroup, error) {
+	var group models.ReconciliationGroup
+	if err := s.db.Where("institution_id = ? AND group_ref = ?", institutionID, groupRef).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return &group, nil
+}
+
+// GetGroup 查询对账组详情及明细拆分
+func (s *GroupService) GetGroup(institutionID, groupRef string) (*models.GroupResponse, error) {
+	group, err := s.getGroup(institutionID, groupRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.ReconciliationGroupItem
+	if err := s.db.Where("group_id = ?", group.ID).Order("seq").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list group items: %w", err)
+	}
+
+	resp := s.toResponse(group)
+	resp.Items = make([]*models.GroupItemResponse, 0, len(items))
+	for _, item := range items {
+		amount, err := utils.DecryptAmount(s.encryptionKey, item.AmountCipher)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt amount: %w", err)
+		}
+		resp.Items = append(resp.Items, &models.GroupItemResponse{
+			Seq:      item.Seq,
+			ChildRef: item.ChildRef,
+			BizID:    item.BizID,
+			Amount:   amount,
+			ItemHash: item.ItemHash,
+		})
+	}
+	return resp, nil
+}
+
+// toResponse 转换为响应格式(解密合计金额)
+func (s *GroupService) toResponse(group *models.ReconciliationGroup) *models.GroupResponse {
+	resp := &models.GroupResponse{
+		ReconciliationGroup: group,
+		StatusText:          group.GetStatusText(),
+	}
+	if total, err := utils.DecryptAmount(s.encryptionKey, group.TotalCipher); err == nil {
+		resp.TotalAmount = total
+	}
+	return resp
+}
+
+// ListGroups 分页查询对账组
+func (s *GroupService) ListGroups(institutionID string, status *int8, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.ReconciliationGroup{}).Where("institution_id = ?", institutionID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count groups: %w", err)
+	}
+
+	var groups []models.ReconciliationGroup
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	list := make([]*models.GroupResponse, 0, len(groups))
+	for i := range groups {
+		list = append(list, s.toResponse(&groups[i]))
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  list,
+	}, nil
+}
+
+// DeleteGroup 删除待上链的对账组, 释放关联交易
+func (s *GroupService) DeleteGroup(institutionID, groupRef string) error {
+	group, err := s.getGroup(institutionID, groupRef)
+	if err != nil {
+		return err
+	}
+	if group.Status != models.GroupStatusPending {
+		return ErrGroupNotPending
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 以原状态为条件删除, 避免删除并发上链中的对账组
+		result := tx.Where("id = ? AND status = ?", group.ID, models.GroupStatusPending).Delete(&models.ReconciliationGroup{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete group: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrGroupNotPending
+		}
+		if err := tx.Model(&models.Transaction{}).
+			Where("group_id = ?", group.ID).
+			Update("group_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unlink transactions: %w", err)
+		}
+		return tx.Where("group_id = ?", group.ID).Delete(&models.ReconciliationGroupItem{}).Error
+	})
+}
+
+// setMemberStatus 在事务内将组内状态属于 from 的交易更新为 status, 返回发生变化的交易
+// 组内交易的状态与对账组保持一致, 统计、报表、账龄和通知按交易状态即可覆盖组内交易
+func (s *GroupService) setMemberStatus(tx *gorm.DB, groupID uint, from []int8, status int8) ([]statusChange, error) {
+	var members []models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("group_id = ? AND status IN ? AND status <> ?", groupID, from, status).
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock group members: %w", err)
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	if err := tx.Model(&models.Transaction{}).
+		Where("group_id = ? AND status IN ? AND status <> ?", groupID, from, status).
+		Update("status", status).Error; err != nil {
+		return nil, fmt.Errorf("failed to update group members: %w", err)
+	}
+
+	changes := make([]statusChange, len(members))
+	for i := range members {
+		changes[i] = newStatusChange(members[i], status)
+	}
+	return changes, nil
+}
+
+// UploadGroup 将组承诺上链
+// 先以原状态为条件将组标记为上链中, 并发上链同一组时只有一方会提交; 回执执行失败时退回待上链
+// 对手方已上传相同组参考号时合约立即完成哈希碰撞对账, 上链后同步链上状态
+func (s *GroupService) UploadGroup(ctx context.Context, institutionID, groupRef string) (*models.GroupResponse, error) {
+	group, err := s.getGroup(institutionID, groupRef)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status != models.GroupStatusPending {
+		return nil, ErrGroupNotPending
+	}
+
+	var changes []statusChange
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ReconciliationGroup{}).
+			Where("id = ? AND status = ?", group.ID, models.GroupStatusPending).
+			Update("status", models.GroupStatusSubmitted)
+		if result.Error != nil {
+			return fmt.Errorf("failed to claim group: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrGroupNotPending
+		}
+		changes, err = s.setMemberStatus(tx, group.ID, []int8{models.TxStatusPending}, models.TxStatusSubmitted)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	group.Status = models.GroupStatusSubmitted
+	s.txService.notifyStatusChanges(changes)
+
+	receipt, err := s.blockchain.UploadTransaction(ctx, group.GroupRef, group.Commitment)
+	if err != nil {
+		s.resetUpload(group)
+		return nil, fmt.Errorf("failed to upload group: %w", err)
+	}
+	if err := s.markUploaded(group, receipt.TxHash, receipt.BlockNumber); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("reconciliation group uploaded",
+		zap.String("group_ref", groupRef),
+		zap.String("tx_hash", receipt.TxHash),
+		zap.Int64("block_height", receipt.BlockNumber))
+
+	if err := s.syncChainStatus(ctx, group); err != nil {
+		s.logger.Warn("failed to sync group status", zap.String("group_ref", groupRef), zap.Error(err))
+	}
+	return s.GetGroup(institutionID, groupRef)
+}
+
+// markUploaded 将上链中的对账组及组内交易更新为已上链
+func (s *GroupService) markUploaded(group *models.ReconciliationGroup, txHash string, blockHeight int64) error {
+	var changes []statusChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ReconciliationGroup{}).
+			Where("id = ? AND status = ?", group.ID, models.GroupStatusSubmitted).
+			Updates(map[string]interface{}{
+				"tx_hash":      txHash,
+				"block_height": blockHeight,
+				"status":       models.GroupStatusUploaded,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to update group: %w", err)
+		}
+		var err error
+		changes, err = s.setMemberStatus(tx, group.ID, []int8{models.TxStatusSubmitted}, models.TxStatusUploaded)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	group.TxHash = txHash
+	group.BlockHeight = blockHeight
+	group.Status = models.GroupStatusUploaded
+	s.txService.notifyStatusChanges(changes)
+	return nil
+}
+
+// resetUpload 上链失败时将对账组及组内交易退回待上链
+func (s *GroupService) resetUpload(group *models.ReconciliationGroup) {
+	var changes []statusChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ReconciliationGroup{}).
+			Where("id = ? AND status = ?", group.ID, models.GroupStatusSubmitted).
+			Update("status", models.GroupStatusPending).Error; err != nil {
+			return err
+		}
+		var err error
+		changes, err = s.setMemberStatus(tx, group.ID, []int8{models.TxStatusSubmitted}, models.TxStatusPending)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to reset group upload", zap.String("group_ref", group.GroupRef), zap.Error(err))
+		return
+	}
+	group.Status = models.GroupStatusPending
+	s.txService.notifyStatusChanges(changes)
+}
+
+// RecoverInterruptedUploads 处理服务重启前停留在上链中的对账组, 需在接受请求前调用
+// 链上记录的上传方或对账方为本方账户时视为已上链(交易哈希未知), 否则退回待上链
+func (s *GroupService) RecoverInterruptedUploads(ctx context.Context) error {
+	var groups []models.ReconciliationGroup
+	if err := s.db.Where("status = ?", models.GroupStatusSubmitted).Find(&groups).Error; err != nil {
+		return fmt.Errorf("failed to list submitted groups: %w", err)
+	}
+
+	account := s.blockchain.GetAccountAddress().Hex()
+	for i := range groups {
+		group := &groups[i]
+		info, err := s.blockchain.GetTransaction(ctx, group.GroupRef)
+		if err != nil {
+			s.logger.Warn("failed to query interrupted group upload", zap.String("group_ref", group.GroupRef), zap.Error(err))
+			continue
+		}
+		if !strings.EqualFold(info.Uploader, account) && !strings.EqualFold(info.Counterparty, account) {
+			s.resetUpload(group)
+			continue
+		}
+		if err := s.markUploaded(group, "", 0); err != nil {
+			s.logger.Warn("failed to recover group upload", zap.String("group_ref", group.GroupRef), zap.Error(err))
+			continue
+		}
+		if err := s.syncChainStatus(ctx, group); err != nil {
+			s.logger.Warn("failed to sync group status", zap.String("group_ref", group.GroupRef), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// SyncGroup 从链上同步对账组的对账状态
+func (s *GroupService) SyncGroup(ctx context.Context, institutionID, groupRef string) (*models.GroupResponse, error) {
+	group, err := s.getGroup(institutionID, groupRef)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status == models.GroupStatusPending || group.Status == models.GroupStatusSubmitted {
+		return nil, fmt.Errorf("%w: group not uploaded", ErrGroupInvalid)
+	}
+
+	if err := s.syncChainStatus(ctx, group); err != nil {
+		return nil, err
+	}
+	return s.GetGroup(institutionID, groupRef)
+}
+
+// SyncUploadedGroups 同步所有已上链、待对手方上链的对账组, 返回状态发生变化的组数
+func (s *GroupService) SyncUploadedGroups(ctx context.Context) (int, error) {
+	var groups []models.ReconciliationGroup
+	if err := s.db.Where("status = ?", models.GroupStatusUploaded).Find(&groups).Error; err != nil {
+		return 0, fmt.Errorf("failed to list uploaded groups: %w", err)
+	}
+
+	changed := 0
+	for i := range groups {
+		if err := ctx.Err(); err != nil {
+			return changed, err
+		}
+		previous := groups[i].Status
+		if err := s.syncChainStatus(ctx, &groups[i]); err != nil {
+			s.logger.Warn("failed to sync group status", zap.String("group_ref", groups[i].GroupRef), zap.Error(err))
+			continue
+		}
+		if groups[i].Status != previous {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// syncChainStatus 读取链上记录, 对账完成时在同一事务内更新组状态和组内交易状态
+func (s *GroupService) syncChainStatus(ctx context.Context, group *models.ReconciliationGroup) error {
+	info, err := s.blockchain.GetTransaction(ctx, group.GroupRef)
+	if err != nil {
+		return fmt.Errorf("failed to query chain: %w", err)
+	}
+
+	status := int8(info.Status)
+	if status == group.Status || (status != models.GroupStatusMatched && status != models.GroupStatusMismatch) {
+		return nil
+	}
+
+	// 链上记录的首次上传方和对账方中, 非本方地址的一方即对手方
+	counterparty := info.Uploader
+	if counterparty == s.blockchain.GetAccountAddress().Hex() {
+		counterparty = info.Counterparty
+	}
+
+	now := time.Now()
+	updated := false
+	var changes []statusChange
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ReconciliationGroup{}).
+			Where("id = ? AND status = ?", group.ID, group.Status).
+			Updates(map[string]interface{}{
+				"status":             status,
+				"chain_counterparty": counterparty,
+				"matched_at":         now,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update group: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// 已被并发同步更新
+			return nil
+		}
+		updated = true
+		var err error
+		changes, err = s.setMemberStatus(tx, group.ID,
+			[]int8{models.TxStatusUploaded, models.TxStatusMatched, models.TxStatusMismatch}, status)
+		return err
+	})
+	if err != nil || !updated {
+		return err
+	}
+	group.Status = status
+	group.ChainCounterparty = counterparty
+	group.MatchedAt = &now
+	s.txService.notifyStatusChanges(changes)
+
+	s.logger.Info("reconciliation group reconciled",
+		zap.String("group_ref", group.GroupRef),
+		zap.Int8("status", status),
+		zap.Int("members", len(changes)))
+	return nil
+}
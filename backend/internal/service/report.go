@@ -0,0 +1,376 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 导出格式常量
+const (
+	ReportFormatExcel = "xlsx"
+	ReportFormatCSV   = "csv"
+)
+
+// ReconciliationReportFilter 对账报表筛选条件
+type ReconciliationReportFilter struct {
+	InstitutionID string     // 机构ID, 为空导出全部机构
+	StartDate     *time.Time // 起始日期(按创建时间, 含当天)
+	EndDate       *time.Time // 截止日期(按创建时间, 含当天)
+	Status        *int8      // 交易状态, 为空导出全部状态
+}
+
+// ReconciliationReportRow 对账报表行
+type ReconciliationReportRow struct {
+	BizID         string
+	InstitutionID string
+	Sender        string
+	Receiver      string
+	TxType        int8
+	TxDate        *time.Time
+	Currency      string
+	Status        int8
+	Counterparty  string // 对账对手机构
+	TxHash        string
+	BlockHeight   int64
+	MatchedAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// reportRecord 报表查询的扫描结构(关联表字段可能为空)
+type reportRecord struct {
+	BizID         string
+	InstitutionID string
+	Sender        string
+	Receiver      string
+	TxType        int8
+	TxDate        *time.Time
+	Currency      string
+	Status        int8
+	CreatedAt     time.Time
+	TxHash        *string
+	BlockHeight   *int64
+	PartyA        *string
+	PartyB        *string
+	MatchedAt     *time.Time
+}
+
+// reportSheet 报表工作表定义
+type reportSheet struct {
+	Name   string
+	Status int8
+}
+
+// reportSheets 按状态拆分的明细工作表
+var reportSheets = []reportSheet{
+	{Name: "对账成功", Status: models.TxStatusMatched},
+	{Name: "对账失败", Status: models.TxStatusMismatch},
+	{Name: "待上链", Status: models.TxStatusPending},
+	{Name: "已上链未对账", Status: models.TxStatusUploaded},
+	{Name: "已提交待确认", Status: models.TxStatusSubmitted},
+}
+
+// reportHeader 明细表头
+var reportHeader = []string{"业务流水号", "机构ID", "付款方", "收款方", "交易类型", "交易日期", "币种", "状态", "对手机构", "交易哈希", "区块高度", "对账时间", "创建时间"}
+
+// ReportService 报表服务
+type ReportService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewReportService 创建报表服务
+func NewReportService(db *gorm.DB, logger *zap.Logger) *ReportService {
+	return &ReportService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// reportQuery 构造报表基础查询(仅主表筛选条件)
+func (s *ReportService) reportQuery(filter *ReconciliationReportFilter) *gorm.DB {
+	query := s.db.Table("transactions t")
+
+	if filter.InstitutionID != "" {
+		query = query.Where("t.institution_id = ?", filter.InstitutionID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("t.created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("t.created_at < ?", filter.EndDate.AddDate(0, 0, 1))
+	}
+	if filter.Status != nil {
+		query = query.Where("t.status = ?", *filter.Status)
+	}
+	return query
+}
+
+// StreamReconciliationReport 按创建顺序逐行读取报表数据, 不在内存中缓存结果集
+func (s *ReportService) StreamReconciliationReport(filter *ReconciliationReportFilter, fn func(*ReconciliationReportRow) error) error {
+	rows, err := s.reportQuery(filter).
+		Joins("LEFT JOIN chain_receipts cr ON cr.biz_id = t.biz_id").
+		Joins("LEFT JOIN reconciliations r ON r.biz_id = t.biz_id").
+		Select("t.biz_id, t.institution_id, t.sender, t.receiver, t.tx_type, t.tx_date, t.currency, t.status, t.created_at, " +
+			"cr.tx_hash, cr.block_height, r.party_a, r.party_b, r.matched_at").
+		Order("t.id").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to query report: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec reportRecord
+		if err := s.db.ScanRows(rows, &rec); err != nil {
+			return fmt.Errorf("failed to scan report row: %w", err)
+		}
+		if err := fn(rec.toRow()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// toRow 转换为报表行, 对手机构取对账记录中的另一方
+func (r *reportRecord) toRow() *ReconciliationReportRow {
+	row := &ReconciliationReportRow{
+		BizID:         r.BizID,
+		InstitutionID: r.InstitutionID,
+		Sender:        r.Sender,
+		Receiver:      r.Receiver,
+		TxType:        r.TxType,
+		TxDate:        r.TxDate,
+		Currency:      r.Currency,
+		Status:        r.Status,
+		MatchedAt:     r.MatchedAt,
+		CreatedAt:     r.CreatedAt,
+	}
+	if r.TxHash != nil {
+		row.TxHash = *r.TxHash
+	}
+	if r.BlockHeight != nil {
+		row.BlockHeight = *r.BlockHeight
+	}
+	if r.PartyA != nil && *r.PartyA != r.InstitutionID {
+		row.Counterparty = *r.PartyA
+	} else if r.PartyB != nil {
+		row.Counterparty = *r.PartyB
+	}
+	return row
+}
+
+// values 转换为导出单元格文本
+func (r *ReconciliationReportRow) values() []string {
+	tx := models.Transaction{Status: r.Status}
+
+	txDate, matchedAt, blockHeight := "", "", ""
+	if r.TxDate != nil {
+		txDate = r.TxDate.Format("2006-01-02")
+	}
+	if r.MatchedAt != nil {
+		matchedAt = r.MatchedAt.Format("2006-01-02 15:04:05")
+	}
+	if r.BlockHeight > 0 {
+		blockHeight = strconv.FormatInt(r.BlockHeight, 10)
+	}
+
+	return []string{
+		r.BizID, r.InstitutionID, r.Sender, r.Receiver, strconv.Itoa(int(r.TxType)), txDate, r.Currency,
+		tx.GetStatusText(), r.Counterparty, r.TxHash, blockHeight, matchedAt,
+		r.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// countByStatus 按状态统计报表范围内的交易数
+func (s *ReportService) countByStatus(filter *ReconciliationReportFilter) (map[int8]int64, error) {
+	var results []struct {
+		Status int8
+		Count  int64
+	}
+
+	if err := s.reportQuery(filter).Select("t.status AS status, COUNT(*) AS count").Group("t.status").Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to count report rows: %w", err)
+	}
+
+	counts := make(map[int8]int64, len(results))
+	for _, r := range results {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
+
+// ExportReconciliationCSV 以CSV格式导出对账报表(带UTF-8 BOM, 便于Excel直接打开)
+func (s *ReportService) ExportReconciliationCSV(w io.Writer, filter *ReconciliationReportFilter) error {
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportHeader); err != nil {
+		return err
+	}
+
+	count := 0
+	err := s.StreamReconciliationReport(filter, func(row *ReconciliationReportRow) error {
+		count++
+		// 定期刷新, 数据分块写出到响应
+		if count%1000 == 0 {
+			cw.Flush()
+		}
+		return cw.Write(row.values())
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportReconciliationExcel 以多工作表Excel导出对账报表
+// 汇总表在前, 各状态明细表使用流式写入器逐行写出, 超出内存阈值的数据由excelize落盘
+func (s *ReportService) ExportReconciliationExcel(w io.Writer, filter *ReconciliationReportFilter) error {
+	counts, err := s.countByStatus(filter)
+	if err != nil {
+		return err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := s.writeSummarySheet(f, filter, counts); err != nil {
+		return err
+	}
+
+	for _, sheet := range reportSheets {
+		if filter.Status != nil && *filter.Status != sheet.Status {
+			continue
+		}
+		if err := s.writeDetailSheet(f, sheet, filter); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("failed to write excel: %w", err)
+	}
+	return nil
+}
+
+// writeSummarySheet 写入汇总表
+func (s *ReportService) writeSummarySheet(f *excelize.File, filter *ReconciliationReportFilter, counts map[int8]int64) error {
+	const sheet = "汇总"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return fmt.Errorf("failed to create summary sheet: %w", err)
+	}
+
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+	matchRate := 0.0
+	if total > 0 {
+		matchRate = float64(counts[models.TxStatusMatched]) / float64(total) * 100
+	}
+
+	institution, dateRange, status := "全部", "全部", "全部"
+	if filter.InstitutionID != "" {
+		institution = filter.InstitutionID
+	}
+	if filter.StartDate != nil || filter.EndDate != nil {
+		start, end := "", ""
+		if filter.StartDate != nil {
+			start = filter.StartDate.Format("2006-01-02")
+		}
+		if filter.EndDate != nil {
+			end = filter.EndDate.Format("2006-01-02")
+		}
+		dateRange = start + " ~ " + end
+	}
+	if filter.Status != nil {
+		status = (&models.Transaction{Status: *filter.Status}).GetStatusText()
+	}
+
+	rows := [][]interface{}{
+		{"对账报表"},
+		{"生成时间", time.Now().Format("2006-01-02 15:04:05")},
+		{"机构", institution},
+		{"日期范围", dateRange},
+		{"状态筛选", status},
+		{},
+		{"项目", "数量"},
+		{"交易总数", total},
+		{"对账成功", counts[models.TxStatusMatched]},
+		{"对账失败", counts[models.TxStatusMismatch]},
+		{"待上链", counts[models.TxStatusPending]},
+		{"已上链未对账", counts[models.TxStatusUploaded]},
+		{"已提交待确认", counts[models.TxStatusSubmitted]},
+		{"匹配率(%)", fmt.Sprintf("%.2f", matchRate)},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return fmt.Errorf("failed to write summary: %w", err)
+		}
+	}
+
+	bold, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	f.SetCellStyle(sheet, "A1", "A1", bold)
+	f.SetCellStyle(sheet, "A7", "B7", bold)
+	f.SetColWidth(sheet, "A", "B", 20)
+	return nil
+}
+
+// writeDetailSheet 流式写入某一状态的明细表
+func (s *ReportService) writeDetailSheet(f *excelize.File, sheet reportSheet, filter *ReconciliationReportFilter) error {
+	if _, err := f.NewSheet(sheet.Name); err != nil {
+		return fmt.Errorf("failed to create sheet %s: %w", sheet.Name, err)
+	}
+
+	sw, err := f.NewStreamWriter(sheet.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create stream writer: %w", err)
+	}
+	if err := sw.SetColWidth(1, len(reportHeader), 20); err != nil {
+		return err
+	}
+
+	bold, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	header := make([]interface{}, len(reportHeader))
+	for i, h := range reportHeader {
+		header[i] = excelize.Cell{StyleID: bold, Value: h}
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	sheetFilter := *filter
+	status := sheet.Status
+	sheetFilter.Status = &status
+
+	rowNum := 1
+	err = s.StreamReconciliationReport(&sheetFilter, func(row *ReconciliationReportRow) error {
+		rowNum++
+		values := row.values()
+		cells := make([]interface{}, len(values))
+		for i, v := range values {
+			cells[i] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+		return sw.SetRow(cell, cells)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sw.Flush()
+}
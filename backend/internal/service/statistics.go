@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidPeriod 统计周期无效
+	ErrInvalidPeriod = errors.New("invalid statistics period")
+)
+
+// StatisticsService 对账统计服务
+type StatisticsService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewStatisticsService 创建对账统计服务
+func NewStatisticsService(db *gorm.DB, logger *zap.Logger) *StatisticsService {
+	return &StatisticsService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// PeriodRange 计算 anchor 所在的统计区间及上一区间
+// 自定义周期使用 from/to(含当天), 上一区间为紧邻的等长区间
+func PeriodRange(period string, anchor, from, to time.Time) (current, previous models.StatisticsPeriod, err error) {
+	day := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, anchor.Location())
+
+	switch period {
+	case models.StatisticsPeriodDay:
+		current = models.StatisticsPeriod{Start: day, End: day.AddDate(0, 0, 1)}
+		previous = models.StatisticsPeriod{Start: day.AddDate(0, 0, -1), End: day}
+	case models.StatisticsPeriodWeek:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		current = models.StatisticsPeriod{Start: start, End: start.AddDate(0, 0, 7)}
+		previous = models.StatisticsPeriod{Start: start.AddDate(0, 0, -7), End: start}
+	case models.StatisticsPeriodMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		current = models.StatisticsPeriod{Start: start, End: start.AddDate(0, 1, 0)}
+		previous = models.StatisticsPeriod{Start: start.AddDate(0, -1, 0), End: start}
+	case models.StatisticsPeriodQuarter:
+		start := time.Date(day.Year(), day.Month()-(day.Month()-1)%3, 1, 0, 0, 0, 0, day.Location())
+		current = models.StatisticsPeriod{Start: start, End: start.AddDate(0, 3, 0)}
+		previous = models.StatisticsPeriod{Start: start.AddDate(0, -3, 0), End: start}
+	case models.StatisticsPeriodCustom:
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return current, previous, ErrInvalidPeriod
+		}
+		end := to.AddDate(0, 0, 1)
+		current = models.StatisticsPeriod{Start: from, End: end}
+		previous = models.StatisticsPeriod{Start: from.Add(-end.Sub(from)), End: from}
+	default:
+		return current, previous, ErrInvalidPeriod
+	}
+	return current, previous, nil
+}
+
+// matrixRecord 矩阵统计的分组结果
+type matrixRecord struct {
+	InstitutionID string
+	Sender        string
+	Receiver      string
+	Status        int8
+	IsCurrent     bool
+	Count         int64
+	MatchSeconds  float64 // 对账成功记录的耗时合计(秒)
+	Timed         int64   // 参与耗时统计的对账成功记录数
+}
+
+// matrixAccumulator 单元格累加器
+type matrixAccumulator struct {
+	cell                    *models.CounterpartyMatrixCell
+	curSeconds, prevSeconds float64
+	curTimed, prevTimed     int64
+}
+
+// CounterpartyMatrix 统计机构与各对手方之间的对账矩阵, 并与上一区间比较(两个区间须首尾相接)
+// institutionID 为空时统计全部机构; 对手方按付款方/收款方识别, 能对应到已登记机构时归并为机构ID
+func (s *StatisticsService) CounterpartyMatrix(institutionID, period string, current, previous models.StatisticsPeriod) (*models.CounterpartyMatrix, error) {
+	resolver, err := s.newPartyResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Table("transactions t").
+		Joins("LEFT JOIN chain_receipts cr ON cr.biz_id = t.biz_id").
+		Joins("LEFT JOIN reconciliations r ON r.biz_id = t.biz_id").
+		Select("t.institution_id, t.sender, t.receiver, t.status, "+
+			"t.created_at >= ? AS is_current, COUNT(*) AS count, "+
+			"SUM(CASE WHEN t.status = ? AND cr.created_at IS NOT NULL AND r.matched_at IS NOT NULL THEN TIMESTAMPDIFF(SECOND, cr.created_at, r.matched_at) ELSE 0 END) AS match_seconds, "+
+			"SUM(CASE WHEN t.status = ? AND cr.created_at IS NOT NULL AND r.matched_at IS NOT NULL THEN 1 ELSE 0 END) AS timed",
+			current.Start, models.TxStatusMatched, models.TxStatusMatched).
+		Where("t.created_at >= ? AND t.created_at < ?", previous.Start, current.End)
+	if institutionID != "" {
+		query = query.Where("t.institution_id = ?", institutionID)
+	}
+
+	var records []matrixRecord
+	if err := query.Group("t.institution_id, t.sender, t.receiver, t.status, is_current").Scan(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate transactions: %w", err)
+	}
+
+	cells := make(map[[2]string]*matrixAccumulator)
+	for _, rec := range records {
+		counterparty := resolver.counterparty(rec.InstitutionID, rec.Sender, rec.Receiver)
+		key := [2]string{rec.InstitutionID, counterparty}
+		acc, ok := cells[key]
+		if !ok {
+			acc = &matrixAccumulator{cell: &models.CounterpartyMatrixCell{
+				InstitutionID: rec.InstitutionID,
+				Counterparty:  counterparty,
+			}}
+			cells[key] = acc
+		}
+
+		metrics := &acc.cell.Previous
+		if rec.IsCurrent {
+			metrics = &acc.cell.Current
+			acc.curSeconds += rec.MatchSeconds
+			acc.curTimed += rec.Timed
+		} else {
+			acc.prevSeconds += rec.MatchSeconds
+			acc.prevTimed += rec.Timed
+		}
+		addMatrixCount(metrics, rec.Status, rec.Count)
+	}
+
+	matrix := &models.CounterpartyMatrix{
+		Period:         period,
+		Current:        current,
+		Previous:       previous,
+		Institutions:   make([]string, 0),
+		Counterparties: make([]string, 0),
+		Cells:          make([]*models.CounterpartyMatrixCell, 0, len(cells)),
+	}
+	institutions := make(map[string]bool)
+	counterparties := make(map[string]bool)
+	for _, acc := range cells {
+		finishMatrixMetrics(&acc.cell.Current, acc.curSeconds, acc.curTimed)
+		finishMatrixMetrics(&acc.cell.Previous, acc.prevSeconds, acc.prevTimed)
+		acc.cell.Change = matrixChange(&acc.cell.Current, &acc.cell.Previous)
+		matrix.Cells = append(matrix.Cells, acc.cell)
+
+		if !institutions[acc.cell.InstitutionID] {
+			institutions[acc.cell.InstitutionID] = true
+			matrix.Institutions = append(matrix.Institutions, acc.cell.InstitutionID)
+		}
+		if !counterparties[acc.cell.Counterparty] {
+			counterparties[acc.cell.Counterparty] = true
+			matrix.Counterparties = append(matrix.Counterparties, acc.cell.Counterparty)
+		}
+	}
+
+	sort.Strings(matrix.Institutions)
+	sort.Strings(matrix.Counterparties)
+	sort.Slice(matrix.Cells, func(i, j int) bool {
+		if matrix.Cells[i].InstitutionID != matrix.Cells[j].InstitutionID {
+			return matrix.Cells[i].InstitutionID < matrix.Cells[j].InstitutionID
+		}
+		return matrix.Cells[i].Counterparty < matrix.Cells[j].Counterparty
+	})
+	return matrix, nil
+}
+
+// addMatrixCount 按交易状态累加计数
+func addMatrixCount(m *models.CounterpartyMetrics, status int8, n int64) {
+	m.Total += n
+	switch status {
+	case models.TxStatusPending, models.TxStatusSubmitted:
+		m.Pending += n
+	case models.TxStatusUploaded:
+		m.Uploaded += n
+	case models.TxStatusMatched:
+		m.Uploaded += n
+		m.Matched += n
+	case models.TxStatusMismatch:
+		m.Uploaded += n
+		m.Mismatched += n
+	}
+}
+
+// finishMatrixMetrics 计算匹配率和平均对账耗时
+func finishMatrixMetrics(m *models.CounterpartyMetrics, seconds float64, timed int64) {
+	if m.Total > 0 {
+		m.MatchRate = float64(m.Matched) / float64(m.Total) * 100
+	}
+	if timed > 0 {
+		m.AvgMatchSeconds = seconds / float64(timed)
+	}
+}
+
+// matrixChange 计算环比变化
+func matrixChange(cur, prev *models.CounterpartyMetrics) models.CounterpartyChange {
+	return models.CounterpartyChange{
+		Total:           changeRate(float64(cur.Total), float64(prev.Total)),
+		Uploaded:        changeRate(float64(cur.Uploaded), float64(prev.Uploaded)),
+		Matched:         changeRate(float64(cur.Matched), float64(prev.Matched)),
+		Mismatched:      changeRate(float64(cur.Mismatched), float64(prev.Mismatched)),
+		Pending:         changeRate(float64(cur.Pending), float64(prev.Pending)),
+		MatchRate:       cur.MatchRate - prev.MatchRate,
+		AvgMatchSeconds: changeRate(cur.AvgMatchSeconds, prev.AvgMatchSeconds),
+	}
+}
+
+// changeRate 变化率(百分比), 上期为0时无意义返回 nil
+func changeRate(cur, prev float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	rate := (cur - prev) / prev * 100
+	return &rate
+}
+
+// partyResolver 将付款方/收款方名称对应到已登记机构
+type partyResolver struct {
+	byName map[string]string // 规范化的机构ID/名称 → 机构ID
+}
+
+// newPartyResolver 加载已登记机构
+func (s *StatisticsService) newPartyResolver() (*partyResolver, error) {
+	var institutions []models.Institution
+	if err := s.db.Select("institution_id, name").Find(&institutions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list institutions: %w", err)
+	}
+
+	r := &partyResolver{byName: make(map[string]string, len(institutions)*2)}
+	for _, inst := range institutions {
+		r.byName[utils.NormalizeParty(inst.InstitutionID)] = inst.InstitutionID
+		if inst.Name != "" {
+			r.byName[utils.NormalizeParty(inst.Name)] = inst.InstitutionID
+		}
+	}
+	return r, nil
+}
+
+// resolve 名称对应的机构ID, 未登记时返回原名称
+func (r *partyResolver) resolve(name string) string {
+	if id, ok := r.byName[utils.NormalizeParty(name)]; ok {
+		return id
+	}
+	return strings.TrimSpace(name)
+}
+
+// counterparty 识别对手方: 付款方为本机构时取收款方, 否则取付款方
+func (r *partyResolver) counterparty(institutionID, sender, receiver string) string {
+	if r.resolve(sender) == institutionID {
+		return r.resolve(receiver)
+	}
+	return r.resolve(sender)
+}
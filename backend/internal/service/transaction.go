@@ -2,7 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"bc-reconciliation-backend/internal/blockchain"
 	"bc-reconciliation-backend/internal/models"
@@ -10,24 +18,76 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionService 交易服务
 type TransactionService struct {
-	db           *gorm.DB
-	blockchain   *blockchain.Client
-	logger       *zap.Logger
+	db            *gorm.DB
+	blockchain    *blockchain.Client
+	logger        *zap.Logger
 	encryptionKey string // AES加密密钥(32字节)
+	blindIndexKey string // 金额盲索引主密钥
+
+	importWorkers   int // 导入时并行加密/哈希的协程数
+	importChunkSize int // 导入时每次 INSERT 的行数
+
+	notifiers []StatusNotifier // 交易状态变化通知
+}
+
+// StatusNotifier 交易状态变化通知
+type StatusNotifier interface {
+	NotifyStatusChange(tx *models.Transaction, previous int8)
+}
+
+// AddStatusNotifier 注册交易状态变化通知, 需在服务开始处理请求前调用
+func (s *TransactionService) AddStatusNotifier(notifier StatusNotifier) {
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// notifyStatusChange 通知所有订阅方
+func (s *TransactionService) notifyStatusChange(tx *models.Transaction, previous int8) {
+	for _, notifier := range s.notifiers {
+		notifier.NotifyStatusChange(tx, previous)
+	}
+}
+
+// statusChange 在数据库事务内发生的交易状态变化, 事务提交后再发出通知
+type statusChange struct {
+	tx       models.Transaction
+	previous int8
+}
+
+// newStatusChange 记录交易由 tx.Status 变为 status
+func newStatusChange(tx models.Transaction, status int8) statusChange {
+	change := statusChange{tx: tx, previous: tx.Status}
+	change.tx.Status = status
+	return change
+}
+
+// notifyStatusChanges 逐笔通知事务内发生的状态变化
+func (s *TransactionService) notifyStatusChanges(changes []statusChange) {
+	for i := range changes {
+		s.notifyStatusChange(&changes[i].tx, changes[i].previous)
+	}
 }
 
 // NewTransactionService 创建交易服务
-func NewTransactionService(db *gorm.DB, bc *blockchain.Client, logger *zap.Logger, encryptionKey string) *TransactionService {
-	return &TransactionService{
-		db:           db,
-		blockchain:   bc,
-		logger:       logger,
+func NewTransactionService(db *gorm.DB, bc *blockchain.Client, logger *zap.Logger, encryptionKey, blindIndexKey string) *TransactionService {
+	s := &TransactionService{
+		db:            db,
+		blockchain:    bc,
+		logger:        logger,
 		encryptionKey: encryptionKey,
+		blindIndexKey: blindIndexKey,
 	}
+	s.SetImportConcurrency(0, 0)
+	return s
+}
+
+// amountIndex 计算机构维度的金额盲索引
+func (s *TransactionService) amountIndex(institutionID, amount string) string {
+	return utils.AmountBlindIndex(utils.DeriveInstitutionKey(s.blindIndexKey, institutionID), amount)
 }
 
 // CreateTransactionResult 创建交易结果
@@ -39,7 +99,19 @@ type CreateTransactionResult struct {
 
 // CreateTransaction 创建交易记录
 func (s *TransactionService) CreateTransaction(req *models.CreateTransactionRequest, institutionID string) (*CreateTransactionResult, error) {
-	// 1. 检查业务流水号是否已存在
+	return s.createTransaction(req, institutionID, nil)
+}
+
+// createTransaction 创建交易记录, batchID 非空时关联导入批次
+func (s *TransactionService) createTransaction(req *models.CreateTransactionRequest, institutionID string, batchID *uint) (*CreateTransactionResult, error) {
+	// 1. 检查业务流水号是否可用(锚定前缀保留给对账证明等链上锚定记录)
+	if blockchain.IsAnchorID(req.BizID) {
+		return &CreateTransactionResult{
+			Success: false,
+			BizID:   req.BizID,
+			Message: "业务流水号不能以 " + blockchain.AnchorIDPrefix + " 开头",
+		}, nil
+	}
 	var existingTx models.Transaction
 	err := s.db.Where("biz_id = ?", req.BizID).First(&existingTx).Error
 	if err == nil {
@@ -50,32 +122,65 @@ func (s *TransactionService) CreateTransaction(req *models.CreateTransactionRequ
 		}, nil
 	}
 
-	// 2. 生成随机盐
+	// 2. 解析交易日期(可选)
+	var txDate *time.Time
+	if req.TxDate != "" {
+		d, err := time.ParseInLocation("2006-01-02", req.TxDate, time.Local)
+		if err != nil {
+			return &CreateTransactionResult{
+				Success: false,
+				BizID:   req.BizID,
+				Message: "交易日期格式错误, 应为 YYYY-MM-DD",
+			}, nil
+		}
+		txDate = &d
+	}
+
+	// 3. 校验币种(可选, 默认人民币)
+	currency := utils.DefaultCurrency
+	if req.Currency != "" {
+		if !utils.IsValidCurrency(req.Currency) {
+			return &CreateTransactionResult{
+				Success: false,
+				BizID:   req.BizID,
+				Message: "币种须为ISO 4217货币代码",
+			}, nil
+		}
+		currency = utils.NormalizeCurrency(req.Currency)
+	}
+
+	// 4. 规范化金额, 哈希、密文与盲索引均使用同一表示, 与批量导入一致
+	amount := utils.NormalizeAmount(req.Amount)
+
+	// 5. 生成随机盐
 	salt, err := utils.GenerateRandomSalt()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate salt: %w", err)
 	}
 
-	// 3. 计算数据哈希(用于上链)
-	dataHash := utils.CalculateDataHash(req.BizID, req.Amount, salt)
+	// 6. 计算数据哈希(用于上链)
+	dataHash := utils.CalculateDataHash(req.BizID, amount, currency, salt)
 
-	// 4. AES加密金额
-	amountCipher, err := utils.EncryptAmount(s.encryptionKey, req.Amount)
+	// 7. AES加密金额
+	amountCipher, err := utils.EncryptAmount(s.encryptionKey, amount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt amount: %w", err)
 	}
 
-	// 5. 创建交易记录
+	// 8. 创建交易记录
 	tx := &models.Transaction{
 		BizID:         req.BizID,
 		InstitutionID: institutionID,
 		AmountCipher:  amountCipher,
-		AmountHash:    utils.HashPassword(req.Amount),
+		Currency:      currency,
+		AmountHash:    s.amountIndex(institutionID, amount),
 		DataHash:      dataHash,
 		Salt:          salt,
 		Receiver:      req.Receiver,
 		Sender:        req.Sender,
 		TxType:        req.TxType,
+		TxDate:        txDate,
+		BatchID:       batchID,
 		Status:        models.TxStatusPending,
 	}
 
@@ -102,49 +207,275 @@ func (s *TransactionService) UploadToChain(ctx context.Context, bizId string, co
 		return fmt.Errorf("transaction not found: %w", err)
 	}
 
-	// 2. 检查状态
+	// 2. 检查状态; 对账组内的交易只能随组承诺上链
+	if tx.GroupID != nil {
+		return ErrTransactionInGroup
+	}
 	if tx.Status != models.TxStatusPending {
 		return fmt.Errorf("invalid transaction status: %d", tx.Status)
 	}
 
-	// 3. 调用智能合约上传
-	txHash, err := s.blockchain.SendTransaction(ctx, contractAddress, tx.BizID, tx.DataHash)
+	// 3. 构造上传交易, 交易哈希在提交前即可确定
+	prepared, err := s.blockchain.PrepareUploadTransaction(ctx, tx.BizID, tx.DataHash)
 	if err != nil {
+		s.saveReceipt(&models.ChainReceipt{
+			BizID:           tx.BizID,
+			ContractAddress: contractAddress,
+			Status:          models.ChainReceiptStatusFailed,
+		})
 		return fmt.Errorf("failed to upload to chain: %w", err)
 	}
 
-	// 4. 获取当前区块高度
-	blockNumber, err := s.blockchain.GetBlockNumber(ctx)
-	if err != nil {
-		s.logger.Warn("failed to get block number", zap.Error(err))
-		blockNumber = 0
-	}
-
-	// 5. 保存链上回执
+	// 4. 先记录交易哈希和区块限制并标记为已提交, 提交后进程退出也能由回执追踪恢复
+	// 以原状态为条件更新, 并发上传同一笔交易时只有一方会提交
 	receipt := &models.ChainReceipt{
 		BizID:           tx.BizID,
-		TxHash:          txHash,
-		BlockHeight:     blockNumber,
+		TxHash:          prepared.TxHash,
+		BlockLimit:      prepared.BlockLimit,
 		ContractAddress: contractAddress,
-		Status:          models.ChainReceiptStatusSuccess,
+		Status:          models.ChainReceiptStatusSubmitted,
 	}
-	if err := s.db.Create(receipt).Error; err != nil {
-		s.logger.Error("failed to save receipt", zap.Error(err))
+	err = s.db.Transaction(func(db *gorm.DB) error {
+		result := db.Model(&models.Transaction{}).
+			Where("id = ? AND status = ? AND group_id IS NULL", tx.ID, models.TxStatusPending).
+			Update("status", models.TxStatusSubmitted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid transaction status: already submitted or grouped")
+		}
+		return upsertReceipt(db, receipt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record submission: %w", err)
 	}
+	tx.Status = models.TxStatusSubmitted
+	s.notifyStatusChange(&tx, models.TxStatusPending)
 
-	// 6. 更新交易状态
-	if err := s.db.Model(&tx).Update("status", models.TxStatusUploaded).Error; err != nil {
-		s.logger.Error("failed to update status", zap.Error(err))
+	// 5. 提交交易, 不等待回执; 回执由 ReceiptTracker 确认
+	if err := s.blockchain.SubmitTransaction(ctx, prepared); err != nil {
+		s.ResetSubmission(&tx, models.ChainReceiptStatusFailed)
+		return fmt.Errorf("failed to upload to chain: %w", err)
 	}
 
-	s.logger.Info("upload to chain success",
+	s.logger.Info("transaction submitted to chain",
 		zap.String("biz_id", bizId),
-		zap.String("tx_hash", txHash),
-		zap.Int64("block_height", blockNumber))
+		zap.String("tx_hash", prepared.TxHash),
+		zap.Int64("block_limit", prepared.BlockLimit))
+
+	return nil
+}
+
+// ConfirmSubmission 回执确认成功后将已提交的交易更新为已上链, 并同步链上对账状态
+// 对手方已先行上链时合约在本次上传时即完成对账
+func (s *TransactionService) ConfirmSubmission(ctx context.Context, tx *models.Transaction, receipt *models.ChainReceipt) error {
+	err := s.db.Transaction(func(db *gorm.DB) error {
+		result := db.Model(&models.Transaction{}).
+			Where("id = ? AND status = ?", tx.ID, models.TxStatusSubmitted).
+			Update("status", models.TxStatusUploaded)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSubmissionResolved
+		}
+		return upsertReceipt(db, receipt)
+	})
+	if errors.Is(err, errSubmissionResolved) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to confirm submission: %w", err)
+	}
+	tx.Status = models.TxStatusUploaded
+	s.notifyStatusChange(tx, models.TxStatusSubmitted)
 
+	s.logger.Info("upload to chain success",
+		zap.String("biz_id", tx.BizID),
+		zap.String("tx_hash", receipt.TxHash),
+		zap.Int64("block_height", receipt.BlockHeight))
+
+	if _, err := s.SyncChainStatus(ctx, tx); err != nil {
+		s.logger.Warn("failed to sync chain status", zap.String("biz_id", tx.BizID), zap.Error(err))
+	}
 	return nil
 }
 
+// ResetSubmission 将已提交的交易退回待上链以便重新上传, 回执记为 receiptStatus
+func (s *TransactionService) ResetSubmission(tx *models.Transaction, receiptStatus int8) {
+	err := s.db.Transaction(func(db *gorm.DB) error {
+		result := db.Model(&models.Transaction{}).
+			Where("id = ? AND status = ?", tx.ID, models.TxStatusSubmitted).
+			Update("status", models.TxStatusPending)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSubmissionResolved
+		}
+		return db.Model(&models.ChainReceipt{}).
+			Where("biz_id = ?", tx.BizID).
+			Update("status", receiptStatus).Error
+	})
+	if errors.Is(err, errSubmissionResolved) {
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to reset submission", zap.String("biz_id", tx.BizID), zap.Error(err))
+		return
+	}
+	tx.Status = models.TxStatusPending
+	s.notifyStatusChange(tx, models.TxStatusSubmitted)
+
+	s.logger.Warn("submission reset to pending", zap.String("biz_id", tx.BizID))
+}
+
+// ErrTransactionInGroup 交易已归入对账组, 不能单独上链
+var ErrTransactionInGroup = errors.New("transaction belongs to a reconciliation group, upload the group instead")
+
+// errSubmissionResolved 交易已不处于已提交状态(已被其他流程确认或退回)
+var errSubmissionResolved = errors.New("submission already resolved")
+
+// saveReceipt 保存链上回执; 上链失败也记录回执(状态为失败), 重新上链成功后覆盖
+func (s *TransactionService) saveReceipt(receipt *models.ChainReceipt) {
+	if err := upsertReceipt(s.db, receipt); err != nil {
+		s.logger.Error("failed to save receipt", zap.Error(err))
+	}
+}
+
+// upsertReceipt 按业务流水号写入回执
+func upsertReceipt(db *gorm.DB, receipt *models.ChainReceipt) error {
+	columns := []string{"tx_hash", "block_height", "block_hash", "gas_used", "block_limit", "contract_address", "status", "created_at"}
+	return db.Model(&models.ChainReceipt{}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "biz_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(receipt.CreateValues()).Error
+}
+
+// SyncChainStatus 读取链上记录, 对账完成(成功或失败)时更新交易状态并发出通知
+// 返回状态是否发生变化
+func (s *TransactionService) SyncChainStatus(ctx context.Context, tx *models.Transaction) (bool, error) {
+	info, err := s.blockchain.GetTransaction(ctx, tx.BizID)
+	if err != nil {
+		return false, fmt.Errorf("failed to query chain: %w", err)
+	}
+
+	status := int8(info.Status)
+	if status != models.TxStatusMatched && status != models.TxStatusMismatch {
+		return false, nil
+	}
+	return s.ApplyChainStatus(tx, info)
+}
+
+// ApplyChainStatus 将交易状态更新为链上记录的状态并发出通知, 返回状态是否发生变化
+// 以原状态为条件更新, 并发同步时只有一方会发出通知
+func (s *TransactionService) ApplyChainStatus(tx *models.Transaction, info *blockchain.TransactionInfo) (bool, error) {
+	status := int8(info.Status)
+	if status == tx.Status {
+		return false, nil
+	}
+
+	previous := tx.Status
+	result := s.db.Model(&models.Transaction{}).
+		Where("id = ? AND status = ?", tx.ID, previous).
+		Update("status", status)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	tx.Status = status
+
+	if status == models.TxStatusMatched || status == models.TxStatusMismatch {
+		s.recordReconciliation(tx, info, status)
+	}
+
+	s.logger.Info("transaction status synced from chain",
+		zap.String("biz_id", tx.BizID),
+		zap.Int8("previous", previous),
+		zap.Int8("status", status))
+
+	s.notifyStatusChange(tx, previous)
+	return true, nil
+}
+
+// recordReconciliation 记录链上对账结果, 仅对账成功时记录对账时间(对账耗时统计以此为准)
+// 双方按链上地址映射为机构ID, 与链索引重建一致; 已人工确认的容差匹配记录保持不变
+func (s *TransactionService) recordReconciliation(tx *models.Transaction, info *blockchain.TransactionInfo, status int8) {
+	var existing models.Reconciliation
+	if err := s.db.Select("status").Where("biz_id = ?", tx.BizID).Take(&existing).Error; err == nil &&
+		existing.Status == models.ReconciliationStatusAdjusted {
+		return
+	}
+
+	rec := &models.Reconciliation{
+		BizID:  tx.BizID,
+		PartyA: s.chainParty(info.Uploader),
+		PartyB: s.chainParty(info.Counterparty),
+		Status: status,
+	}
+	columns := []string{"party_a", "party_b", "status"}
+	if status == models.TxStatusMatched {
+		now := time.Now()
+		rec.MatchedAt = &now
+		columns = append(columns, "matched_at")
+		if info.MatchHeight != nil && info.MatchHeight.Sign() > 0 {
+			height := info.MatchHeight.Int64()
+			rec.BlockHeight = &height
+			columns = append(columns, "block_height")
+		}
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "biz_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(rec).Error; err != nil {
+		s.logger.Warn("failed to record reconciliation", zap.String("biz_id", tx.BizID), zap.Error(err))
+	}
+}
+
+// chainParty 链上地址对应的机构ID, 未登记时返回地址
+func (s *TransactionService) chainParty(address string) string {
+	var inst models.Institution
+	err := s.db.Select("institution_id").
+		Where("LOWER(address) = ?", strings.ToLower(address)).
+		Take(&inst).Error
+	if err != nil {
+		return address
+	}
+	return inst.InstitutionID
+}
+
+// SyncUploadedStatuses 同步所有已上链、待对手方上链交易的链上对账状态, 返回状态发生变化的笔数
+// 对账组内的交易以组承诺上链, 不逐笔同步
+func (s *TransactionService) SyncUploadedStatuses(ctx context.Context) (int, error) {
+	changed := 0
+	var txs []models.Transaction
+	err := s.db.Where("status = ? AND group_id IS NULL", models.TxStatusUploaded).
+		FindInBatches(&txs, 100, func(_ *gorm.DB, _ int) error {
+			for i := range txs {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				ok, err := s.SyncChainStatus(ctx, &txs[i])
+				if err != nil {
+					s.logger.Warn("failed to sync chain status", zap.String("biz_id", txs[i].BizID), zap.Error(err))
+					continue
+				}
+				if ok {
+					changed++
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return changed, fmt.Errorf("failed to sync uploaded transactions: %w", err)
+	}
+	return changed, nil
+}
+
 // BatchUploadToChain 批量上链
 func (s *TransactionService) BatchUploadToChain(ctx context.Context, bizIds []string, contractAddress string) *models.BatchUploadResult {
 	result := &models.BatchUploadResult{
@@ -179,88 +510,115 @@ func (s *TransactionService) GetTransaction(bizId string) (*models.TransactionRe
 	return tx.ToResponse(), nil
 }
 
-// ListTransactions 查询交易列表(分页)
-func (s *TransactionService) ListTransactions(institutionID string, page, size int, status int8) (*models.PageResponse, error) {
-	var txs []models.Transaction
-	var total int64
+// ImportMode 导入模式
+const (
+	ImportModePartial = "partial" // 部分导入: 仅导入校验通过的行
+	ImportModeStrict  = "strict"  // 严格模式: 存在任一错误行则整体拒绝
+)
 
-	query := s.db.Model(&models.Transaction{})
+// ImportResult 导入结果
+type ImportResult struct {
+	models.BatchUploadResult
+	Mode          string           `json:"mode"`
+	Rejected      bool             `json:"rejected"`                  // 严格模式下整体拒绝
+	Errors        []utils.RowError `json:"errors"`                    // 行级错误明细
+	ErrorReportID string           `json:"error_report_id,omitempty"` // 错误标注工作簿ID
+	BatchNo       string           `json:"batch_no,omitempty"`        // 导入批次号
+}
 
-	// 机构过滤
-	if institutionID != "" {
-		query = query.Where("institution_id = ?", institutionID)
-	}
+// ParseExcelAndCreate 解析导入文件(Excel/CSV/定长文本)并创建交易
+// mode 为 ImportModePartial 时导入所有校验通过的行; 为 ImportModeStrict 时任一行有错即整体拒绝
+// opts 为导入方案对应的解析选项, 为 nil 时使用默认表头
+// 校验和查重在内存中完成, 有效行并行加密后在一个数据库事务中分块写入, 写入失败时整个文件回滚
+func (s *TransactionService) ParseExcelAndCreate(filePath, institutionID, mode string, opts *utils.ParseOptions) (*ImportResult, error) {
+	return s.parseAndCreate(filePath, institutionID, mode, opts, nil)
+}
 
-	// 状态过滤
-	if status > 0 {
-		query = query.Where("status = ?", status)
+// parseAndCreate 校验导入文件并创建交易, batchID 非空时创建的交易关联该批次
+func (s *TransactionService) parseAndCreate(filePath, institutionID, mode string, opts *utils.ParseOptions, batchID *uint) (*ImportResult, error) {
+	// 1. 校验导入文件, 收集所有行级错误
+	parsed, err := utils.ValidateImportFile(filePath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse excel: %w", err)
 	}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	result := &ImportResult{
+		BatchUploadResult: models.BatchUploadResult{Total: parsed.TotalRows},
+		Mode:              mode,
+		Errors:            parsed.Errors,
 	}
 
-	// 分页查询
-	offset := (page - 1) * size
-	if err := query.Offset(offset).Limit(size).Order("created_at DESC").Find(&txs).Error; err != nil {
-		return nil, fmt.Errorf("failed to list transactions: %w", err)
-	}
+	failedRows := append([]utils.ExcelRow{}, parsed.InvalidRows...)
 
-	// 转换为响应格式
-	responses := make([]*models.TransactionResponse, len(txs))
-	for i, tx := range txs {
-		responses[i] = tx.ToResponse()
+	// 2. 检查与库中已有业务流水号的重复
+	validRows, dupRows, dupErrs, err := s.excludeExistingBizIDs(parsed)
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = append(result.Errors, dupErrs...)
+	failedRows = append(failedRows, dupRows...)
+
+	// 3. 严格模式下存在错误则整体拒绝
+	if mode == ImportModeStrict && len(result.Errors) > 0 {
+		result.Rejected = true
+		failedRows = append(failedRows, validRows...)
+		validRows = nil
 	}
 
-	return &models.PageResponse{
-		Total: total,
-		Page:  page,
-		Size:  size,
-		Data:  responses,
-	}, nil
-}
+	// 4. 并行计算哈希和密文, 在一个数据库事务中分块写入
+	prepared := s.prepareTransactions(validRows, institutionID, batchID)
+	txs := make([]*models.Transaction, 0, len(prepared))
+	for _, p := range prepared {
+		if p.err != nil {
+			failedRows = append(failedRows, p.row)
+			result.Errors = append(result.Errors, utils.RowError{
+				Row:      p.row.RowNum,
+				ColIndex: -1,
+				Value:    p.row.BizID,
+				Reason:   "创建失败: " + p.err.Error(),
+			})
+			continue
+		}
+		txs = append(txs, p.tx)
+	}
 
-// ParseExcelAndCreate 解析Excel文件并创建交易
-func (s *TransactionService) ParseExcelAndCreate(filePath, institutionID string) (*models.BatchUploadResult, error) {
-	// 1. 解析Excel
-	rows, err := utils.ParseExcelFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse excel: %w", err)
+	if mode == ImportModeStrict && len(txs) < len(prepared) {
+		result.Rejected = true
+		for _, p := range prepared {
+			if p.err == nil {
+				failedRows = append(failedRows, p.row)
+			}
+		}
+		txs = nil
 	}
 
-	result := &models.BatchUploadResult{
-		Total: len(rows),
+	if err := s.bulkInsertTransactions(txs); err != nil {
+		return nil, err
 	}
+	for _, tx := range txs {
+		result.SuccessIDs = append(result.SuccessIDs, tx.BizID)
+	}
+	result.Success = len(txs)
 
-	// 2. 遍历行数据
-	for _, row := range rows {
-		req := &models.CreateTransactionRequest{
-			BizID:    row.BizID,
-			Amount:   row.Amount,
-			Sender:   row.Sender,
-			Receiver: row.Receiver,
-			TxType:   row.TxType,
-		}
+	// 5. 汇总失败行
+	result.Failed = len(failedRows)
+	for _, row := range failedRows {
+		result.FailedIDs = append(result.FailedIDs, row.BizID)
+	}
 
-		// 3. 创建交易
-		createResult, err := s.CreateTransaction(req, institutionID)
+	// 6. 生成错误标注工作簿
+	if len(result.Errors) > 0 {
+		reportID, err := s.createErrorReport(filePath, institutionID, parsed, result.Errors)
 		if err != nil {
-			result.Failed++
-			result.FailedIDs = append(result.FailedIDs, row.BizID)
-			s.logger.Error("failed to create transaction",
-				zap.String("biz_id", row.BizID),
-				zap.Error(err))
-		} else if !createResult.Success {
-			result.Failed++
-			result.FailedIDs = append(result.FailedIDs, row.BizID)
+			s.logger.Warn("failed to create import error report", zap.Error(err))
 		} else {
-			result.Success++
-			result.SuccessIDs = append(result.SuccessIDs, row.BizID)
+			result.ErrorReportID = reportID
 		}
 	}
 
 	s.logger.Info("excel parse completed",
+		zap.String("mode", mode),
+		zap.Bool("rejected", result.Rejected),
 		zap.Int("total", result.Total),
 		zap.Int("success", result.Success),
 		zap.Int("failed", result.Failed))
@@ -268,37 +626,273 @@ func (s *TransactionService) ParseExcelAndCreate(filePath, institutionID string)
 	return result, nil
 }
 
-// GetStatistics 获取统计数据
-func (s *TransactionService) GetStatistics(institutionID string) (*models.StatisticsResponse, error) {
-	var stats models.StatisticsResponse
+// ImportFileOptions 文件导入参数
+type ImportFileOptions struct {
+	FileName      string              // 原始文件名
+	InstitutionID string              // 机构ID
+	Uploader      string              // 上传人
+	Mode          string              // 导入模式
+	ProfileName   string              // 导入方案名称
+	Parse         *utils.ParseOptions // 导入方案对应的解析选项
+	Force         bool                // 忽略重复文件检测
+}
 
-	query := s.db.Model(&models.Transaction{})
-	if institutionID != "" {
-		query = query.Where("institution_id = ?", institutionID)
+// ImportFile 以批次方式导入文件
+// 先按文件摘要检测同一机构是否已导入过相同文件, 再创建批次并导入, 创建的交易关联批次,
+// 导入结束后回写批次的行数统计与状态
+func (s *TransactionService) ImportFile(filePath string, in *ImportFileOptions) (*ImportResult, error) {
+	digest, size, err := utils.CalculateFileDigest(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !in.Force {
+		existing, err := s.findImportedBatch(in.InstitutionID, digest)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, &DuplicateImportError{Batch: existing}
+		}
+	}
+
+	batchNo, err := utils.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+	batch := &models.ImportBatch{
+		BatchNo:       batchNo,
+		InstitutionID: in.InstitutionID,
+		FileName:      in.FileName,
+		FileDigest:    digest,
+		FileSize:      size,
+		FileFormat:    utils.DetectFileFormat(filePath, in.Parse),
+		Uploader:      in.Uploader,
+		ProfileName:   in.ProfileName,
+		Mode:          in.Mode,
+		Status:        models.ImportBatchStatusProcessing,
+	}
+	if err := s.db.Create(batch).Error; err != nil {
+		return nil, fmt.Errorf("failed to create import batch: %w", err)
+	}
+
+	result, err := s.parseAndCreate(filePath, in.InstitutionID, in.Mode, in.Parse, &batch.ID)
+	if err != nil {
+		message := err.Error()
+		if len(message) > 512 {
+			message = message[:512]
+		}
+		s.db.Model(batch).Updates(map[string]interface{}{
+			"status":        models.ImportBatchStatusFailed,
+			"error_message": message,
+		})
+		return nil, err
+	}
+
+	status := models.ImportBatchStatusCompleted
+	if result.Rejected {
+		status = models.ImportBatchStatusRejected
+	}
+	if err := s.db.Model(batch).Updates(map[string]interface{}{
+		"total_rows":      result.Total,
+		"success_rows":    result.Success,
+		"failed_rows":     result.Failed,
+		"error_report_id": result.ErrorReportID,
+		"status":          status,
+	}).Error; err != nil {
+		s.logger.Error("failed to update import batch", zap.String("batch_no", batchNo), zap.Error(err))
+	}
+
+	result.BatchNo = batchNo
+	return result, nil
+}
+
+// findImportedBatch 查找机构已导入(或正在导入)相同摘要文件的批次, 不存在时返回 nil
+func (s *TransactionService) findImportedBatch(institutionID, digest string) (*models.ImportBatch, error) {
+	var existing models.ImportBatch
+	err := s.db.Where("institution_id = ? AND file_digest = ? AND status IN ?", institutionID, digest,
+		[]int8{models.ImportBatchStatusProcessing, models.ImportBatchStatusCompleted}).
+		Order("id DESC").
+		First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("failed to check duplicate file: %w", err)
+}
+
+// excludeExistingBizIDs 按流水号集合批量查询, 剔除库中已存在的业务流水号
+// 返回剩余行、重复行及对应的行级错误
+func (s *TransactionService) excludeExistingBizIDs(parsed *utils.ExcelParseResult) ([]utils.ExcelRow, []utils.ExcelRow, []utils.RowError, error) {
+	rows := parsed.ValidRows
+	if len(rows) == 0 {
+		return rows, nil, nil, nil
+	}
+
+	bizIDs := make([]string, len(rows))
+	for i, row := range rows {
+		bizIDs[i] = row.BizID
+	}
+
+	var existing []string
+	for start := 0; start < len(bizIDs); start += bizIDQueryChunkSize {
+		end := start + bizIDQueryChunkSize
+		if end > len(bizIDs) {
+			end = len(bizIDs)
+		}
+		var found []string
+		if err := s.db.Model(&models.Transaction{}).
+			Where("biz_id IN ?", bizIDs[start:end]).
+			Pluck("biz_id", &found).Error; err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to check duplicate biz_id: %w", err)
+		}
+		existing = append(existing, found...)
+	}
+	if len(existing) == 0 {
+		return rows, nil, nil, nil
+	}
+
+	existingSet := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		existingSet[id] = true
+	}
+
+	var remaining, duplicates []utils.ExcelRow
+	var errs []utils.RowError
+	for _, row := range rows {
+		if existingSet[row.BizID] {
+			duplicates = append(duplicates, row)
+			errs = append(errs, utils.RowError{
+				Row:      row.RowNum,
+				Column:   parsed.ColumnName(utils.FieldBizID),
+				ColIndex: parsed.ColumnIndex(utils.FieldBizID),
+				Value:    row.BizID,
+				Reason:   "业务流水号已存在",
+			})
+			continue
+		}
+		remaining = append(remaining, row)
+	}
+
+	return remaining, duplicates, errs, nil
+}
+
+// importReportTTL 错误标注工作簿保留时长, 过期后不可下载并在生成新报告时清理
+const importReportTTL = 24 * time.Hour
+
+// importReportDir 错误标注工作簿存放目录
+func importReportDir() string {
+	return filepath.Join(os.TempDir(), "import_reports")
+}
+
+// institutionReportDir 机构的错误标注工作簿目录, 按机构隔离, 下载时只在本机构目录中查找
+func institutionReportDir(institutionID string) string {
+	return filepath.Join(importReportDir(), hex.EncodeToString([]byte(institutionID)))
+}
+
+// createErrorReport 生成错误标注工作簿, 返回报告ID
+func (s *TransactionService) createErrorReport(srcPath, institutionID string, parsed *utils.ExcelParseResult, rowErrs []utils.RowError) (string, error) {
+	s.cleanupErrorReports()
+
+	dir := institutionReportDir(institutionID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create report dir: %w", err)
 	}
 
-	// 总交易数
-	query.Count(&stats.TotalTransactions)
+	reportID, err := utils.GenerateRandomID()
+	if err != nil {
+		return "", err
+	}
+
+	errs := make([]utils.RowError, len(rowErrs))
+	copy(errs, rowErrs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+
+	if err := utils.CreateErrorWorkbook(srcPath, filepath.Join(dir, reportID+".xlsx"), parsed, errs); err != nil {
+		return "", err
+	}
+
+	return reportID, nil
+}
+
+// GetImportErrorReportPath 获取机构的错误标注工作簿路径, 其他机构的报告和已过期的报告视为不存在
+func (s *TransactionService) GetImportErrorReportPath(institutionID, reportID string) (string, error) {
+	if _, err := hex.DecodeString(reportID); err != nil || len(reportID) != 32 {
+		return "", fmt.Errorf("invalid report id: %s", reportID)
+	}
+
+	path := filepath.Join(institutionReportDir(institutionID), reportID+".xlsx")
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("report not found: %w", err)
+	}
+	if time.Since(info.ModTime()) > importReportTTL {
+		os.Remove(path)
+		return "", fmt.Errorf("report expired: %s", reportID)
+	}
+
+	return path, nil
+}
 
-	// 对账成功数
-	s.db.Model(&models.Transaction{}).
-		Where("status = ?", models.TxStatusMatched).
-		Count(&stats.MatchedCount)
+// cleanupErrorReports 删除过期的错误标注工作簿, 清理失败不影响导入
+func (s *TransactionService) cleanupErrorReports() {
+	root := importReportDir()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if time.Since(info.ModTime()) > importReportTTL {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				s.logger.Warn("failed to remove expired import error report", zap.String("path", path), zap.Error(err))
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Warn("failed to clean up import error reports", zap.Error(err))
+	}
+}
 
-	// 对账失败数
-	s.db.Model(&models.Transaction{}).
-		Where("status = ?", models.TxStatusMismatch).
-		Count(&stats.MismatchCount)
+// GetStatistics 获取统计数据, institutionID 为空时统计全部机构
+func (s *TransactionService) GetStatistics(institutionID string) (*models.StatisticsResponse, error) {
+	var rows []struct {
+		Status int8
+		Count  int64
+	}
 
-	// 待上链数
-	s.db.Model(&models.Transaction{}).
-		Where("status = ?", models.TxStatusPending).
-		Count(&stats.PendingCount)
+	query := s.db.Model(&models.Transaction{})
+	if institutionID != "" {
+		query = query.Where("institution_id = ?", institutionID)
+	}
+	if err := query.Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	}
 
-	// 已上链数
-	s.db.Model(&models.Transaction{}).
-		Where("status = ?", models.TxStatusUploaded).
-		Count(&stats.UploadedCount)
+	var stats models.StatisticsResponse
+	for _, row := range rows {
+		stats.TotalTransactions += row.Count
+		switch row.Status {
+		case models.TxStatusMatched:
+			stats.MatchedCount = row.Count
+		case models.TxStatusMismatch:
+			stats.MismatchCount = row.Count
+		case models.TxStatusPending:
+			stats.PendingCount = row.Count
+		case models.TxStatusUploaded:
+			stats.UploadedCount = row.Count
+		case models.TxStatusSubmitted:
+			stats.SubmittedCount = row.Count
+		}
+	}
 
 	// 计算匹配率
 	if stats.TotalTransactions > 0 {
@@ -308,6 +902,24 @@ func (s *TransactionService) GetStatistics(institutionID string) (*models.Statis
 	return &stats, nil
 }
 
+// FindTransactionsByAmount 按金额等值检索交易
+// 基于盲索引查询, 数据库中不保存可被字典还原的金额哈希
+func (s *TransactionService) FindTransactionsByAmount(institutionID, amount string) ([]*models.TransactionResponse, error) {
+	var txs []models.Transaction
+	if err := s.db.Where("institution_id = ? AND amount_hash = ?", institutionID, s.amountIndex(institutionID, amount)).
+		Order("created_at DESC").
+		Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find transactions by amount: %w", err)
+	}
+
+	responses := make([]*models.TransactionResponse, len(txs))
+	for i, tx := range txs {
+		responses[i] = tx.ToResponse()
+	}
+
+	return responses, nil
+}
+
 // DecryptAmount 解密金额(用于审计)
 func (s *TransactionService) DecryptAmount(bizId string) (string, error) {
 	var tx models.Transaction
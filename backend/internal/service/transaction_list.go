@@ -0,0 +1,270 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bc-reconciliation-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidCursor 游标无效或与排序条件不一致
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSortField 不支持的排序字段
+	ErrInvalidSortField = errors.New("invalid sort field")
+)
+
+// 交易列表分页大小
+const (
+	defaultListSize = 10
+	maxListSize     = 100
+)
+
+// transactionSortColumns 排序字段对应的列, block_height 需要关联链上回执
+var transactionSortColumns = map[string]string{
+	"created_at":   "transactions.created_at",
+	"updated_at":   "transactions.updated_at",
+	"tx_date":      "transactions.tx_date",
+	"biz_id":       "transactions.biz_id",
+	"status":       "transactions.status",
+	"block_height": "cr.block_height",
+	"id":           "transactions.id",
+}
+
+// listCursor 游标内容: 上一页最后一条记录的排序值及ID
+type listCursor struct {
+	SortBy string          `json:"s"`
+	Desc   bool            `json:"d"`
+	Value  json.RawMessage `json:"v"` // null 表示排序列为 NULL
+	ID     uint            `json:"id"`
+}
+
+// encode 编码为 URL 安全的字符串
+func (c *listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor 解析游标, 排序条件须与生成游标时一致
+func decodeListCursor(raw, sortBy string, desc bool) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortBy != sortBy || c.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// value 按排序字段类型还原排序值, NULL 返回 nil
+func (c *listCursor) value() (interface{}, error) {
+	if len(c.Value) == 0 || string(c.Value) == "null" {
+		return nil, nil
+	}
+
+	switch c.SortBy {
+	case "created_at", "updated_at", "tx_date":
+		var s string
+		if err := json.Unmarshal(c.Value, &s); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case "biz_id":
+		var s string
+		if err := json.Unmarshal(c.Value, &s); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return s, nil
+	default:
+		var n int64
+		if err := json.Unmarshal(c.Value, &n); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return n, nil
+	}
+}
+
+// ListTransactions 查询交易列表(游标分页)
+// 按排序字段及ID做 keyset 分页, 翻页性能不随页数下降; 游标与排序条件绑定, 更换排序需从第一页开始
+func (s *TransactionService) ListTransactions(q *models.TransactionListQuery) (*models.CursorPageResponse, error) {
+	sortBy := q.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	column, ok := transactionSortColumns[sortBy]
+	if !ok {
+		return nil, ErrInvalidSortField
+	}
+	size := q.Size
+	if size < 1 || size > maxListSize {
+		size = defaultListSize
+	}
+
+	query := s.db.Model(&models.Transaction{}).Select("transactions.*")
+	if sortBy == "block_height" || q.TxHash != "" || q.BlockFrom != nil || q.BlockTo != nil {
+		query = query.Joins("LEFT JOIN chain_receipts cr ON cr.biz_id = transactions.biz_id")
+	}
+	query = applyTransactionFilters(query, q)
+
+	if q.Cursor != "" {
+		cursor, err := decodeListCursor(q.Cursor, sortBy, q.Desc)
+		if err != nil {
+			return nil, err
+		}
+		value, err := cursor.value()
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(keysetCondition(s.db, column, q.Desc, value, cursor.ID))
+	}
+
+	direction := "ASC"
+	if q.Desc {
+		direction = "DESC"
+	}
+	order := fmt.Sprintf("%s %s", column, direction)
+	if sortBy != "id" {
+		order += ", transactions.id " + direction
+	}
+
+	var txs []models.Transaction
+	if err := query.Order(order).Limit(size + 1).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	result := &models.CursorPageResponse{Size: size}
+	if len(txs) > size {
+		txs = txs[:size]
+		result.HasMore = true
+
+		last := &txs[size-1]
+		value, err := s.sortValue(last, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		encoded, _ := json.Marshal(value)
+		result.NextCursor = (&listCursor{SortBy: sortBy, Desc: q.Desc, Value: encoded, ID: last.ID}).encode()
+	}
+
+	responses := make([]*models.TransactionResponse, len(txs))
+	for i := range txs {
+		responses[i] = txs[i].ToResponse()
+	}
+	result.Data = responses
+	return result, nil
+}
+
+// applyTransactionFilters 添加列表过滤条件
+func applyTransactionFilters(query *gorm.DB, q *models.TransactionListQuery) *gorm.DB {
+	if q.InstitutionID != "" {
+		query = query.Where("transactions.institution_id = ?", q.InstitutionID)
+	}
+	if q.Status != nil {
+		query = query.Where("transactions.status = ?", *q.Status)
+	}
+	if q.TxType != nil {
+		query = query.Where("transactions.tx_type = ?", *q.TxType)
+	}
+	if q.DateFrom != nil {
+		query = query.Where("transactions.tx_date >= ?", q.DateFrom.Format("2006-01-02"))
+	}
+	if q.DateTo != nil {
+		query = query.Where("transactions.tx_date <= ?", q.DateTo.Format("2006-01-02"))
+	}
+	if q.Sender != "" {
+		query = query.Where("transactions.sender = ?", q.Sender)
+	}
+	if q.Receiver != "" {
+		query = query.Where("transactions.receiver = ?", q.Receiver)
+	}
+	if q.Counterparty != "" {
+		query = query.Where("(transactions.sender = ? OR transactions.receiver = ?)", q.Counterparty, q.Counterparty)
+	}
+	if q.BizIDPrefix != "" {
+		query = query.Where("transactions.biz_id LIKE ?", escapeLike(q.BizIDPrefix)+"%")
+	}
+	if q.TxHash != "" {
+		query = query.Where("cr.tx_hash = ?", q.TxHash)
+	}
+	if q.BlockFrom != nil {
+		query = query.Where("cr.block_height >= ?", *q.BlockFrom)
+	}
+	if q.BlockTo != nil {
+		query = query.Where("cr.block_height <= ?", *q.BlockTo)
+	}
+	return query
+}
+
+// keysetCondition 构造"位于游标之后"的条件
+// MySQL 中 NULL 升序排在最前、降序排在最后, 排序列可能为 NULL(tx_date、block_height)时需单独处理
+func keysetCondition(db *gorm.DB, column string, desc bool, value interface{}, id uint) *gorm.DB {
+	cmp := ">"
+	if desc {
+		cmp = "<"
+	}
+	cond := db.Session(&gorm.Session{NewDB: true})
+
+	if value == nil {
+		if desc {
+			return cond.Where(column+" IS NULL AND transactions.id < ?", id)
+		}
+		return cond.Where("("+column+" IS NULL AND transactions.id > ?) OR "+column+" IS NOT NULL", id)
+	}
+
+	cond = cond.Where(fmt.Sprintf("%s %s ? OR (%s = ? AND transactions.id %s ?)", column, cmp, column, cmp), value, value, id)
+	if desc {
+		cond = cond.Or(column + " IS NULL")
+	}
+	return cond
+}
+
+// sortValue 记录的排序值, 用于生成下一页游标
+func (s *TransactionService) sortValue(tx *models.Transaction, sortBy string) (interface{}, error) {
+	switch sortBy {
+	case "created_at":
+		return tx.CreatedAt.Format(time.RFC3339Nano), nil
+	case "updated_at":
+		return tx.UpdatedAt.Format(time.RFC3339Nano), nil
+	case "tx_date":
+		if tx.TxDate == nil {
+			return nil, nil
+		}
+		return tx.TxDate.Format(time.RFC3339Nano), nil
+	case "biz_id":
+		return tx.BizID, nil
+	case "status":
+		return tx.Status, nil
+	case "block_height":
+		var receipt models.ChainReceipt
+		err := s.db.Select("block_height").Where("biz_id = ?", tx.BizID).First(&receipt).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block height: %w", err)
+		}
+		return receipt.BlockHeight, nil
+	default:
+		return tx.ID, nil
+	}
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,743 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"bc-reconciliation-backend/internal/models"
+	"bc-reconciliation-backend/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Webhook 推送请求头
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"     // 事件类型
+	WebhookHeaderID        = "X-Webhook-Id"        // 事件ID, 重试和重放保持不变, 接收方可据此去重
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"  // 推送记录ID
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp" // 推送时间(Unix秒)
+	WebhookHeaderSignature = "X-Webhook-Signature" // sha256=HMAC-SHA256(secret, timestamp + "." + body)
+)
+
+// webhookErrorLimit 推送记录中保存的错误信息长度上限
+const webhookErrorLimit = 512
+
+var (
+	// ErrWebhookNotFound 订阅不存在
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrWebhookDisabled 订阅已停用
+	ErrWebhookDisabled = errors.New("webhook subscription disabled")
+	// ErrWebhookInvalid 订阅参数无效
+	ErrWebhookInvalid = errors.New("invalid webhook subscription")
+	// ErrWebhookDeliveryNotFound 推送记录不存在
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// errWebhookAddressBlocked 推送地址解析到内网、回环或链路本地地址
+	errWebhookAddressBlocked = errors.New("webhook address is not a public address")
+)
+
+// blockedWebhookNets 除内网、回环、链路本地外不允许推送的地址段
+var blockedWebhookNets = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "64:ff9b::/96")
+
+// mustParseCIDRs 解析固定的地址段
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// webhookInvalid 包装订阅参数错误
+func webhookInvalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrWebhookInvalid, fmt.Sprintf(format, args...))
+}
+
+// WebhookOptions 推送参数
+type WebhookOptions struct {
+	Timeout     time.Duration // 单次推送超时
+	MaxAttempts int           // 最大尝试次数(含首次推送)
+	RetryBase   time.Duration // 首次重试间隔, 之后按2倍递增
+	RetryMax    time.Duration // 重试间隔上限
+}
+
+// withDefaults 补齐未配置的推送参数
+func (o WebhookOptions) withDefaults() WebhookOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 6
+	}
+	if o.RetryBase <= 0 {
+		o.RetryBase = 30 * time.Second
+	}
+	if o.RetryMax < o.RetryBase {
+		o.RetryMax = time.Hour
+	}
+	return o
+}
+
+// backoff 第 attempts 次失败后的重试间隔
+func (o WebhookOptions) backoff(attempts int) time.Duration {
+	delay := o.RetryBase
+	for i := 1; i < attempts && delay < o.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > o.RetryMax {
+		delay = o.RetryMax
+	}
+	return delay
+}
+
+// WebhookService Webhook推送服务
+type WebhookService struct {
+	db            *gorm.DB
+	encryptionKey string // 签名密钥以AES加密保存
+	options       WebhookOptions
+	client        *http.Client
+	logger        *zap.Logger
+}
+
+// NewWebhookService 创建Webhook推送服务
+func NewWebhookService(db *gorm.DB, encryptionKey string, options WebhookOptions, logger *zap.Logger) *WebhookService {
+	options = options.withDefaults()
+	return &WebhookService{
+		db:            db,
+		encryptionKey: encryptionKey,
+		options:       options,
+		client:        newWebhookClient(options.Timeout),
+		logger:        logger,
+	}
+}
+
+// newWebhookClient 创建推送用的 HTTP 客户端
+// 在建立连接时校验DNS解析后的实际地址, 拒绝内网、回环和链路本地地址(包括解析结果被篡改的情况);
+// 不经过代理、不跟随重定向, 3xx 响应按推送失败处理
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errWebhookAddressBlocked
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublicIP 是否为允许推送的公网地址
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range blockedWebhookNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeEventTypes 校验并去重订阅的事件类型
+func normalizeEventTypes(eventTypes []string) (string, error) {
+	supported := make(map[string]bool, len(models.WebhookEventTypes))
+	for _, e := range models.WebhookEventTypes {
+		supported[e] = true
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	normalized := make([]string, 0, len(eventTypes))
+	for _, e := range eventTypes {
+		e = strings.TrimSpace(e)
+		if !supported[e] {
+			return "", webhookInvalid("不支持的事件类型 %q, 可选: %s", e, strings.Join(models.WebhookEventTypes, ", "))
+		}
+		if !seen[e] {
+			seen[e] = true
+			normalized = append(normalized, e)
+		}
+	}
+	if len(normalized) == 0 {
+		return "", webhookInvalid("至少订阅一种事件类型")
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// validateWebhookURL 校验推送地址
+// 主机为IP时须为公网地址; 域名在每次推送建立连接时按解析结果校验
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return webhookInvalid("推送地址必须为 http(s) 地址")
+	}
+	if u.User != nil {
+		return webhookInvalid("推送地址不能包含用户名或密码")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return webhookInvalid("推送地址不能为本机地址")
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return webhookInvalid("推送地址不能为内网、回环或链路本地地址")
+	}
+	return nil
+}
+
+// toWebhookResponse 转换为订阅响应
+func toWebhookResponse(sub *models.WebhookSubscription, secret string) *models.WebhookResponse {
+	return &models.WebhookResponse{
+		WebhookSubscription: sub,
+		EventTypes:          sub.Events(),
+		Secret:              secret,
+	}
+}
+
+// CreateSubscription 创建订阅; 未指定签名密钥时自动生成, 密钥仅在本次响应中返回
+func (s *WebhookService) CreateSubscription(institutionID, operator string, req *models.CreateWebhookRequest) (*models.WebhookResponse, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = utils.GenerateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+	secretCipher, err := utils.EncryptAmount(s.encryptionKey, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	sub := &models.WebhookSubscription{
+		InstitutionID: institutionID,
+		URL:           req.URL,
+		EventTypes:    eventTypes,
+		SecretCipher:  secretCipher,
+		Description:   req.Description,
+		Enabled:       true,
+		CreatedBy:     operator,
+	}
+	if err := s.db.Create(sub).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	s.logger.Info("webhook subscription created",
+		zap.Uint("id", sub.ID),
+		zap.String("institution", institutionID),
+		zap.String("events", eventTypes))
+
+	return toWebhookResponse(sub, secret), nil
+}
+
+// getSubscription 查询机构的订阅
+func (s *WebhookService) getSubscription(institutionID string, id uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	if err := s.db.Where("institution_id = ? AND id = ?", institutionID, id).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// GetSubscription 查询订阅详情
+func (s *WebhookService) GetSubscription(institutionID string, id uint) (*models.WebhookResponse, error) {
+	sub, err := s.getSubscription(institutionID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(sub, ""), nil
+}
+
+// ListSubscriptions 查询机构的订阅列表
+func (s *WebhookService) ListSubscriptions(institutionID string, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.WebhookSubscription{}).Where("institution_id = ?", institutionID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count webhook subscriptions: %w", err)
+	}
+
+	var subs []models.WebhookSubscription
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	responses := make([]*models.WebhookResponse, 0, len(subs))
+	for i := range subs {
+		responses = append(responses, toWebhookResponse(&subs[i], ""))
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  responses,
+	}, nil
+}
+
+// UpdateSubscription 更新订阅地址、事件类型、说明或启用状态
+func (s *WebhookService) UpdateSubscription(institutionID string, id uint, req *models.UpdateWebhookRequest) (*models.WebhookResponse, error) {
+	sub, err := s.getSubscription(institutionID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.URL != "" {
+		if err := validateWebhookURL(req.URL); err != nil {
+			return nil, err
+		}
+		sub.URL = req.URL
+		updates["url"] = sub.URL
+	}
+	if req.EventTypes != nil {
+		if sub.EventTypes, err = normalizeEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+		updates["event_types"] = sub.EventTypes
+	}
+	if req.Description != nil {
+		sub.Description = *req.Description
+		updates["description"] = sub.Description
+	}
+	if req.Enabled != nil {
+		sub.Enabled = *req.Enabled
+		updates["enabled"] = sub.Enabled
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(sub).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+		}
+	}
+	return toWebhookResponse(sub, ""), nil
+}
+
+// DeleteSubscription 删除订阅, 推送记录保留备查
+func (s *WebhookService) DeleteSubscription(institutionID string, id uint) error {
+	sub, err := s.getSubscription(institutionID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(sub).Error; err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// NotifyStatusChange 交易对账完成(成功或失败)时向订阅了对应事件的地址推送, 推送在后台执行
+func (s *WebhookService) NotifyStatusChange(tx *models.Transaction, previous int8) {
+	var eventType string
+	switch tx.Status {
+	case models.TxStatusMatched:
+		eventType = models.WebhookEventMatched
+	case models.TxStatusMismatch:
+		eventType = models.WebhookEventMismatch
+	default:
+		return
+	}
+
+	var subs []models.WebhookSubscription
+	if err := s.db.Where("institution_id = ? AND enabled = ?", tx.InstitutionID, true).Find(&subs).Error; err != nil {
+		s.logger.Error("failed to load webhook subscriptions", zap.String("biz_id", tx.BizID), zap.Error(err))
+		return
+	}
+
+	event, err := newWebhookEvent(eventType, tx.InstitutionID, &models.ReconciliationEventData{
+		BizID:          tx.BizID,
+		Status:         tx.Status,
+		StatusText:     tx.GetStatusText(),
+		PreviousStatus: previous,
+		Currency:       tx.Currency,
+		TxDate:         tx.TxDate,
+		DataHash:       tx.DataHash,
+	})
+	if err != nil {
+		s.logger.Error("failed to build webhook event", zap.String("biz_id", tx.BizID), zap.Error(err))
+		return
+	}
+
+	for i := range subs {
+		if !subs[i].Subscribes(eventType) {
+			continue
+		}
+		delivery, err := s.enqueue(&subs[i], event, tx.BizID, nil)
+		if err != nil {
+			s.logger.Error("failed to enqueue webhook delivery",
+				zap.Uint("subscription", subs[i].ID),
+				zap.String("biz_id", tx.BizID),
+				zap.Error(err))
+			continue
+		}
+		go func(id uint) {
+			if _, err := s.deliver(context.Background(), id, true); err != nil {
+				s.logger.Error("webhook delivery failed", zap.Uint("delivery", id), zap.Error(err))
+			}
+		}(delivery.ID)
+	}
+}
+
+// newWebhookEvent 生成事件
+func newWebhookEvent(eventType, institutionID string, data interface{}) (*models.WebhookEvent, error) {
+	eventID, err := utils.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebhookEvent{
+		ID:            eventID,
+		Type:          eventType,
+		InstitutionID: institutionID,
+		CreatedAt:     time.Now(),
+		Data:          data,
+	}, nil
+}
+
+// enqueue 为订阅生成待推送记录
+func (s *WebhookService) enqueue(sub *models.WebhookSubscription, event *models.WebhookEvent, bizID string, replayOf *uint) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		InstitutionID:  sub.InstitutionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		BizID:          bizID,
+		Payload:        string(payload),
+		Status:         models.WebhookDeliveryPending,
+		NextRetryAt:    &now,
+		ReplayOf:       replayOf,
+	}
+	if err := s.db.Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// deliver 执行一次推送并记录结果
+// 先以条件更新占用待推送记录, 避免后台重试与即时推送重复发送;
+// 失败且允许重试时按指数退避安排下次重试, 达到最大尝试次数后标记为推送失败
+func (s *WebhookService) deliver(ctx context.Context, id uint, retry bool) (*models.WebhookDelivery, error) {
+	claim := s.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, models.WebhookDeliveryPending).
+		Update("status", models.WebhookDeliveryDelivering)
+	if claim.Error != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", claim.Error)
+	}
+
+	var delivery models.WebhookDelivery
+	if err := s.db.First(&delivery, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if claim.RowsAffected == 0 {
+		// 已被其他协程推送
+		return &delivery, nil
+	}
+
+	var (
+		code    int
+		sendErr error
+	)
+	var sub models.WebhookSubscription
+	err := s.db.First(&sub, delivery.SubscriptionID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		sendErr, retry = ErrWebhookNotFound, false
+	case err != nil:
+		sendErr = fmt.Errorf("failed to get webhook subscription: %w", err)
+	case !sub.Enabled:
+		sendErr, retry = ErrWebhookDisabled, false
+	default:
+		code, sendErr = s.send(ctx, &sub, &delivery)
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.LastError = ""
+	delivery.NextRetryAt = nil
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case retry && delivery.Attempts < s.options.MaxAttempts:
+		next := now.Add(s.options.backoff(delivery.Attempts))
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextRetryAt = &next
+		delivery.LastError = truncate(sendErr.Error(), webhookErrorLimit)
+	default:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = truncate(sendErr.Error(), webhookErrorLimit)
+	}
+
+	if err := s.db.Model(&delivery).Updates(map[string]interface{}{
+		"status":        delivery.Status,
+		"attempts":      delivery.Attempts,
+		"response_code": delivery.ResponseCode,
+		"last_error":    delivery.LastError,
+		"next_retry_at": delivery.NextRetryAt,
+		"delivered_at":  delivery.DeliveredAt,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if sendErr != nil {
+		s.logger.Warn("webhook delivery attempt failed",
+			zap.Uint("delivery", delivery.ID),
+			zap.String("event", delivery.EventType),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Int("response_code", code),
+			zap.Error(sendErr))
+	}
+	return &delivery, nil
+}
+
+// send 签名并发送推送, 2xx 响应视为成功; 只记录响应码, 不读取响应内容
+func (s *WebhookService) send(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	secret, err := utils.DecryptAmount(s.encryptionKey, sub.SecretCipher)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bc-reconciliation-webhook/1.0")
+	req.Header.Set(WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(WebhookHeaderID, delivery.EventID)
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+utils.SignWebhookPayload(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// truncate 截断字符串到指定字节数以内(不截断多字节字符)
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+// RetryDue 推送已到重试时间的记录, 返回本次处理的笔数
+func (s *WebhookService) RetryDue(ctx context.Context) (int, error) {
+	var ids []uint
+	if err := s.db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND next_retry_at <= ?", models.WebhookDeliveryPending, time.Now()).
+		Order("next_retry_at").
+		Limit(100).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := s.deliver(ctx, id, true); err != nil {
+			s.logger.Error("webhook delivery failed", zap.Uint("delivery", id), zap.Error(err))
+		}
+	}
+	return len(ids), nil
+}
+
+// RunRetries 按固定间隔重试失败的推送, 直到 ctx 取消
+// 启动时先把上次退出时仍在推送中的记录恢复为待推送
+func (s *WebhookService) RunRetries(ctx context.Context, interval time.Duration) {
+	if err := s.db.Model(&models.WebhookDelivery{}).
+		Where("status = ?", models.WebhookDeliveryDelivering).
+		Updates(map[string]interface{}{
+			"status":        models.WebhookDeliveryPending,
+			"next_retry_at": time.Now(),
+		}).Error; err != nil {
+		s.logger.Error("failed to recover interrupted webhook deliveries", zap.Error(err))
+	}
+
+	s.logger.Info("webhook retry worker started", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("webhook retry worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.RetryDue(ctx); err != nil {
+				s.logger.Error("webhook retry failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ListDeliveries 查询推送记录
+func (s *WebhookService) ListDeliveries(institutionID string, subscriptionID *uint, status *int8, bizID string, page, size int) (*models.PageResponse, error) {
+	query := s.db.Model(&models.WebhookDelivery{}).Where("institution_id = ?", institutionID)
+	if subscriptionID != nil {
+		query = query.Where("subscription_id = ?", *subscriptionID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if bizID != "" {
+		query = query.Where("biz_id = ?", bizID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return &models.PageResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		Data:  deliveries,
+	}, nil
+}
+
+// GetDelivery 查询推送记录详情
+func (s *WebhookService) GetDelivery(institutionID string, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := s.db.Where("institution_id = ? AND id = ?", institutionID, id).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// ReplayDelivery 重放推送: 以原事件内容(事件ID不变)生成新的推送记录并立即推送, 失败时按重试策略继续
+func (s *WebhookService) ReplayDelivery(ctx context.Context, institutionID string, id uint) (*models.WebhookDelivery, error) {
+	original, err := s.GetDelivery(institutionID, id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.getSubscription(institutionID, original.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.Enabled {
+		return nil, ErrWebhookDisabled
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		InstitutionID:  sub.InstitutionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		BizID:          original.BizID,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextRetryAt:    &now,
+		ReplayOf:       &original.ID,
+	}
+	if err := s.db.Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	s.logger.Info("webhook delivery replayed",
+		zap.Uint("original", original.ID),
+		zap.Uint("delivery", delivery.ID))
+
+	return s.deliver(ctx, delivery.ID, true)
+}
+
+// TestDelivery 向订阅地址发送一次测试事件(不重试), 返回推送结果
+func (s *WebhookService) TestDelivery(ctx context.Context, institutionID string, id uint) (*models.WebhookDelivery, error) {
+	sub, err := s.getSubscription(institutionID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.Enabled {
+		return nil, ErrWebhookDisabled
+	}
+
+	event, err := newWebhookEvent(models.WebhookEventTest, institutionID, map[string]interface{}{
+		"subscription_id": sub.ID,
+		"message":         "webhook test delivery",
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := s.enqueue(sub, event, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ctx, delivery.ID, false)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ParseAmount 将金额字符串解析为精确有理数
+func ParseAmount(amount string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(NormalizeAmount(amount))
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %q", amount)
+	}
+	return r, nil
+}
+
+// FormatAmount 将有理数格式化为规范化金额字符串
+func FormatAmount(r *big.Rat) string {
+	// 金额最多保留 18 位小数, 规范化去除末尾的零
+	return NormalizeAmount(r.FloatString(18))
+}
+
+// SumAmounts 精确求和, 返回规范化金额字符串
+func SumAmounts(amounts []string) (string, error) {
+	total := new(big.Rat)
+	for _, a := range amounts {
+		r, err := ParseAmount(a)
+		if err != nil {
+			return "", err
+		}
+		total.Add(total, r)
+	}
+	return FormatAmount(total), nil
+}
+
+// AmountsEqual 判断两个金额是否相等(忽略格式差异)
+func AmountsEqual(a, b string) bool {
+	ra, err := ParseAmount(a)
+	if err != nil {
+		return false
+	}
+	rb, err := ParseAmount(b)
+	if err != nil {
+		return false
+	}
+	return ra.Cmp(rb) == 0
+}
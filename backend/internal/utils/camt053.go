@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// camt053Document camt.053 文档(仅声明用到的元素, 标签不带命名空间以兼容各版本)
+type camt053Document struct {
+	XMLName    xml.Name      `xml:"Document"`
+	Statements []camt053Stmt `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camt053Stmt struct {
+	Account camt053Account `xml:"Acct"`
+	Entries []camt053Entry `xml:"Ntry"`
+}
+
+type camt053Account struct {
+	IBAN      string `xml:"Id>IBAN"`
+	OtherID   string `xml:"Id>Othr>Id"`
+	Currency  string `xml:"Ccy"`
+	OwnerName string `xml:"Ownr>Nm"`
+}
+
+type camt053Amount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camt053Entry struct {
+	Amount       camt053Amount     `xml:"Amt"`
+	CdtDbtInd    string            `xml:"CdtDbtInd"`
+	Reversal     bool              `xml:"RvslInd"`
+	BookingDate  camt053Date       `xml:"BookgDt"`
+	ValueDate    camt053Date       `xml:"ValDt"`
+	AcctSvcrRef  string            `xml:"AcctSvcrRef"`
+	Transactions []camt053TxDetail `xml:"NtryDtls>TxDtls"`
+}
+
+type camt053Date struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camt053TxDetail struct {
+	EndToEndID  string        `xml:"Refs>EndToEndId"`
+	InstrID     string        `xml:"Refs>InstrId"`
+	AcctSvcrRef string        `xml:"Refs>AcctSvcrRef"`
+	Amount      camt053Amount `xml:"Amt"`
+	TxAmount    camt053Amount `xml:"AmtDtls>TxAmt>Amt"`
+	Debtor      camt053Party  `xml:"RltdPties>Dbtr"`
+	Creditor    camt053Party  `xml:"RltdPties>Cdtr"`
+}
+
+// camt053Party 相关方, 兼容 v2 (Dbtr/Nm) 与 v8+ (Dbtr/Pty/Nm)
+type camt053Party struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+func (p camt053Party) name() string {
+	return firstNonEmpty(p.Name, p.PartyName)
+}
+
+func (d camt053Date) value() string {
+	if d.Date != "" {
+		return d.Date
+	}
+	if len(d.DateTime) >= 10 {
+		return d.DateTime[:10]
+	}
+	return ""
+}
+
+// ParseCamt053 解析 ISO 20022 camt.053 对账单
+// 每个 TxDtls 生成一条明细(批量入账拆分), 无明细的 Ntry 按条目本身生成
+func ParseCamt053(data []byte) ([]StatementEntry, error) {
+	var doc camt053Document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse camt.053: %w", err)
+	}
+
+	var entries []StatementEntry
+	seq := 0
+	for _, stmt := range doc.Statements {
+		owner := firstNonEmpty(stmt.Account.OwnerName, stmt.Account.IBAN, stmt.Account.OtherID)
+
+		for _, ntry := range stmt.Entries {
+			direction := DirectionCredit
+			if strings.EqualFold(ntry.CdtDbtInd, "DBIT") {
+				direction = DirectionDebit
+			}
+
+			base := StatementEntry{
+				Currency:    firstNonEmpty(ntry.Amount.Currency, stmt.Account.Currency),
+				Direction:   direction,
+				Reversal:    ntry.Reversal,
+				Owner:       owner,
+				BookingDate: ntry.BookingDate.value(),
+				ValueDate:   ntry.ValueDate.value(),
+			}
+
+			if len(ntry.Transactions) == 0 {
+				seq++
+				entry := base
+				entry.Reference = strings.TrimSpace(ntry.AcctSvcrRef)
+				entry.Amount = strings.TrimSpace(ntry.Amount.Value)
+				entry.Line = seq
+				entries = append(entries, entry)
+				continue
+			}
+
+			for _, tx := range ntry.Transactions {
+				seq++
+				entry := base
+				entry.Line = seq
+				entry.Reference = camt053Reference(tx, ntry.AcctSvcrRef)
+
+				switch {
+				case tx.Amount.Value != "":
+					entry.Amount = strings.TrimSpace(tx.Amount.Value)
+					entry.Currency = firstNonEmpty(tx.Amount.Currency, entry.Currency)
+				case tx.TxAmount.Value != "":
+					entry.Amount = strings.TrimSpace(tx.TxAmount.Value)
+					entry.Currency = firstNonEmpty(tx.TxAmount.Currency, entry.Currency)
+				case len(ntry.Transactions) == 1:
+					entry.Amount = strings.TrimSpace(ntry.Amount.Value)
+				}
+
+				// 贷记时对手方为付款人(Dbtr), 借记时为收款人(Cdtr)
+				if direction == DirectionCredit {
+					entry.Counterparty = tx.Debtor.name()
+				} else {
+					entry.Counterparty = tx.Creditor.name()
+				}
+
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// camt053Reference 业务参考号: 端到端ID优先, 其次指令ID与服务方参考号
+func camt053Reference(tx camt053TxDetail, entryRef string) string {
+	e2e := strings.TrimSpace(tx.EndToEndID)
+	if e2e == "NOTPROVIDED" {
+		e2e = ""
+	}
+	return firstNonEmpty(e2e, tx.InstrID, tx.AcctSvcrRef, entryRef)
+}
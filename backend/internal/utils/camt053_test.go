@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCamt053(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want []StatementEntry
+	}{
+		{
+			name: "batch entry split into transaction details",
+			xml: `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt><Stmt>
+    <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy><Ownr><Nm>ACME GmbH</Nm></Ownr></Acct>
+    <Ntry>
+      <Amt Ccy="EUR">300.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+      <BookgDt><Dt>2024-01-02</Dt></BookgDt><ValDt><Dt>2024-01-03</Dt></ValDt>
+      <AcctSvcrRef>BANKREF1</AcctSvcrRef>
+      <NtryDtls>
+        <TxDtls>
+          <Refs><EndToEndId>E2E-1</EndToEndId></Refs>
+          <Amt Ccy="EUR">100.00</Amt>
+          <RltdPties><Dbtr><Nm>Payer One</Nm></Dbtr></RltdPties>
+        </TxDtls>
+        <TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId><InstrId>INSTR-2</InstrId></Refs>
+          <AmtDtls><TxAmt><Amt Ccy="EUR">200.00</Amt></TxAmt></AmtDtls>
+          <RltdPties><Dbtr><Nm>Payer Two</Nm></Dbtr></RltdPties>
+        </TxDtls>
+      </NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`,
+			want: []StatementEntry{
+				{
+					Reference:    "E2E-1",
+					Amount:       "100.00",
+					Currency:     "EUR",
+					Direction:    DirectionCredit,
+					Owner:        "ACME GmbH",
+					Counterparty: "Payer One",
+					BookingDate:  "2024-01-02",
+					ValueDate:    "2024-01-03",
+					Line:         1,
+				},
+				{
+					Reference:    "INSTR-2",
+					Amount:       "200.00",
+					Currency:     "EUR",
+					Direction:    DirectionCredit,
+					Owner:        "ACME GmbH",
+					Counterparty: "Payer Two",
+					BookingDate:  "2024-01-02",
+					ValueDate:    "2024-01-03",
+					Line:         2,
+				},
+			},
+		},
+		{
+			name: "debit reversal with party wrapper and entry without details",
+			xml: `<Document>
+  <BkToCstmrStmt><Stmt>
+    <Acct><Id><Othr><Id>6222020200112233</Id></Othr></Id><Ccy>CNY</Ccy></Acct>
+    <Ntry>
+      <Amt Ccy="CNY">88.80</Amt><CdtDbtInd>DBIT</CdtDbtInd><RvslInd>true</RvslInd>
+      <BookgDt><DtTm>2024-02-01T10:00:00</DtTm></BookgDt>
+      <NtryDtls><TxDtls>
+        <Refs><AcctSvcrRef>SVC-9</AcctSvcrRef></Refs>
+        <RltdPties><Cdtr><Pty><Nm>Beneficiary Ltd</Nm></Pty></Cdtr></RltdPties>
+      </TxDtls></NtryDtls>
+    </Ntry>
+    <Ntry>
+      <Amt>5.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+      <ValDt><Dt>2024-02-02</Dt></ValDt>
+      <AcctSvcrRef>FEE-1</AcctSvcrRef>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`,
+			want: []StatementEntry{
+				{
+					Reference:    "SVC-9",
+					Amount:       "88.80",
+					Currency:     "CNY",
+					Direction:    DirectionDebit,
+					Reversal:     true,
+					Owner:        "6222020200112233",
+					Counterparty: "Beneficiary Ltd",
+					BookingDate:  "2024-02-01",
+					Line:         1,
+				},
+				{
+					Reference: "FEE-1",
+					Amount:    "5.00",
+					Currency:  "CNY",
+					Direction: DirectionCredit,
+					Owner:     "6222020200112233",
+					ValueDate: "2024-02-02",
+					Line:      2,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCamt053([]byte(tt.xml))
+			if err != nil {
+				t.Fatalf("ParseCamt053() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCamt053() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCamt053InvalidXML(t *testing.T) {
+	if _, err := ParseCamt053([]byte("<Document><BkToCstmrStmt>")); err == nil {
+		t.Fatal("ParseCamt053() error = nil, want error for malformed xml")
+	}
+}
@@ -3,12 +3,17 @@ package utils
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
+	"strings"
 )
 
 var (
@@ -16,6 +21,8 @@ var (
 	ErrInvalidBlockSize = errors.New("ciphertext block size is invalid")
 	// ErrInvalidPKCSData PKCS数据错误
 	ErrInvalidPKCSData = errors.New("invalid pkcs7 data")
+	// ErrInvalidBlindedValue 字段盲化值不是曲线上的点
+	ErrInvalidBlindedValue = errors.New("invalid blinded field value")
 )
 
 // EncryptAmount AES加密金额
@@ -122,8 +129,188 @@ func GenerateRandomSalt() (string, error) {
 	return hex.EncodeToString(salt), nil
 }
 
+// GenerateRandomID 生成随机标识(16字节hex)
+func GenerateRandomID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
 // HashPassword 使用SHA256哈希密码
 func HashPassword(password string) string {
 	hash := sha256.Sum256([]byte(password))
 	return hex.EncodeToString(hash[:])
 }
+
+// DeriveInstitutionKey 派生机构级盲索引密钥
+// key = HMAC-SHA256(masterKey, institutionID), 不同机构的相同金额得到不同索引
+func DeriveInstitutionKey(masterKey, institutionID string) []byte {
+	mac := hmac.New(sha256.New, []byte(masterKey))
+	mac.Write([]byte(institutionID))
+	return mac.Sum(nil)
+}
+
+// AmountBlindIndex 计算金额盲索引(用于等值检索)
+// index = HMAC-SHA256(institutionKey, NormalizeAmount(amount))
+func AmountBlindIndex(institutionKey []byte, amount string) string {
+	mac := hmac.New(sha256.New, institutionKey)
+	mac.Write([]byte(NormalizeAmount(amount)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// diagnosisCurve 字段盲化使用的椭圆曲线, P-256 余因子为1, 曲线上的点均属于素数阶群
+var diagnosisCurve = ecdh.P256()
+
+// GenerateDiagnosisKey 生成一次性诊断私钥(P-256 标量, 32字节hex)
+func GenerateDiagnosisKey() (string, error) {
+	key, err := diagnosisCurve.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate diagnosis key: %w", err)
+	}
+	return hex.EncodeToString(key.Bytes()), nil
+}
+
+// DiagnosisKeyID 计算诊断密钥指纹, 作为诊断会话标识
+// keyID = SHA256(key) 的前8字节
+func DiagnosisKeyID(key []byte) string {
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:8])
+}
+
+// BlindField 计算字段盲化值
+// 将 bizId + "|" + field + "|" + 规范化字段值 哈希到曲线点 H, 返回 key·H 的 x 坐标
+// 盲化值绑定流水号和字段名; 不持有私钥无法由盲化值验证猜测的取值, 对手方不能离线穷举
+func BlindField(key []byte, bizID, field, value string) (string, error) {
+	return scalarMultX(key, hashToCurve([]byte(bizID+"|"+field+"|"+value)))
+}
+
+// ReblindField 以本方私钥对对手方的字段盲化值做二次盲化
+// 标量乘法可交换, a·(b·H) = b·(a·H), 双方字段取值相同时二次盲化值相同
+func ReblindField(key []byte, blinded string) (string, error) {
+	x, err := hex.DecodeString(blinded)
+	if err != nil || len(x) != 32 {
+		return "", ErrInvalidBlindedValue
+	}
+	point, ok := liftX(new(big.Int).SetBytes(x))
+	if !ok {
+		return "", ErrInvalidBlindedValue
+	}
+	return scalarMultX(key, point)
+}
+
+// hashToCurve 以递增计数器重试的方式将消息哈希到 P-256 曲线点(非压缩编码)
+func hashToCurve(msg []byte) []byte {
+	p := elliptic.P256().Params().P
+	var counter [4]byte
+	for i := uint32(0); ; i++ {
+		counter[0], counter[1], counter[2], counter[3] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		hash := sha256.Sum256(append(append([]byte{}, msg...), counter[:]...))
+		x := new(big.Int).SetBytes(hash[:])
+		if x.Cmp(p) >= 0 {
+			continue
+		}
+		if point, ok := liftX(x); ok {
+			return point
+		}
+	}
+}
+
+// liftX 由 x 坐标求曲线点, y 取任一平方根; ±y 两点标量乘后 x 坐标相同, 不影响比对
+func liftX(x *big.Int) ([]byte, bool) {
+	params := elliptic.P256().Params()
+	if x.Sign() < 0 || x.Cmp(params.P) >= 0 {
+		return nil, false
+	}
+	// y² = x³ - 3x + b
+	rhs := new(big.Int).Exp(x, big.NewInt(3), params.P)
+	rhs.Sub(rhs, new(big.Int).Mul(x, big.NewInt(3)))
+	rhs.Add(rhs, params.B)
+	rhs.Mod(rhs, params.P)
+	y := new(big.Int).ModSqrt(rhs, params.P)
+	if y == nil {
+		return nil, false
+	}
+
+	point := make([]byte, 65)
+	point[0] = 4
+	x.FillBytes(point[1:33])
+	y.FillBytes(point[33:])
+	return point, true
+}
+
+// scalarMultX 计算 key·point, 返回结果点的 x 坐标(hex)
+func scalarMultX(key, point []byte) (string, error) {
+	priv, err := diagnosisCurve.NewPrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid diagnosis key: %w", err)
+	}
+	pub, err := diagnosisCurve.NewPublicKey(point)
+	if err != nil {
+		return "", ErrInvalidBlindedValue
+	}
+	x, err := priv.ECDH(pub)
+	if err != nil {
+		return "", ErrInvalidBlindedValue
+	}
+	return hex.EncodeToString(x), nil
+}
+
+// GenerateWebhookSecret 生成Webhook签名密钥(32字节hex)
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// SignWebhookPayload 计算Webhook签名
+// signature = HMAC-SHA256(secret, timestamp + "." + body)
+// 签名覆盖时间戳, 接收方可据此拒绝过期的重放请求
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NormalizeParty 规范化付款方/收款方名称
+// 去除首尾空白、合并连续空白并转为大写, 使仅大小写或空格不同的名称得到相同承诺
+func NormalizeParty(name string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(name), " "))
+}
+
+// NormalizeAmount 规范化金额字符串
+// 去除空白、千分位逗号、多余的前导零和小数末尾的零, 使 "1,000.50" 与 "1000.5" 得到相同索引
+func NormalizeAmount(amount string) string {
+	s := strings.ReplaceAll(strings.TrimSpace(amount), ",", "")
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		intPart, fracPart = s[:idx], s[idx+1:]
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	if intPart == "0" && fracPart == "" {
+		return "0"
+	}
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNormalizeAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   string
+	}{
+		{"1000.50", "1000.5"},
+		{"1,000.50", "1000.5"},
+		{" 001000.500 ", "1000.5"},
+		{"1000.00", "1000"},
+		{"1000", "1000"},
+		{"+12.30", "12.3"},
+		{"-0012.30", "-12.3"},
+		{"0.05", "0.05"},
+		{".5", "0.5"},
+		{"0.00", "0"},
+		{"-0", "0"},
+		{"", "0"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeAmount(tt.amount); got != tt.want {
+			t.Errorf("NormalizeAmount(%q) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestAmountBlindIndex(t *testing.T) {
+	key := DeriveInstitutionKey("master-key", "INST001")
+	otherKey := DeriveInstitutionKey("master-key", "INST002")
+
+	tests := []struct {
+		name  string
+		a     string
+		keyA  []byte
+		b     string
+		keyB  []byte
+		equal bool
+	}{
+		{"same amount", "100.50", key, "100.50", key, true},
+		{"formatting differences", "1,000.50", key, "1000.5", key, true},
+		{"trailing zeros", "12", key, "12.00", key, true},
+		{"different amount", "100.50", key, "100.51", key, false},
+		{"different institution", "100.50", key, "100.50", otherKey, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := AmountBlindIndex(tt.keyA, tt.a)
+			b := AmountBlindIndex(tt.keyB, tt.b)
+			if len(a) != 64 {
+				t.Fatalf("AmountBlindIndex() length = %d, want 64 hex chars", len(a))
+			}
+			if (a == b) != tt.equal {
+				t.Errorf("AmountBlindIndex(%q) == AmountBlindIndex(%q) is %v, want %v", tt.a, tt.b, a == b, tt.equal)
+			}
+		})
+	}
+}
+
+func TestBlindFieldCommutative(t *testing.T) {
+	newKey := func() []byte {
+		t.Helper()
+		keyHex, err := GenerateDiagnosisKey()
+		if err != nil {
+			t.Fatalf("GenerateDiagnosisKey() error = %v", err)
+		}
+		key, err := hex.DecodeString(keyHex)
+		if err != nil {
+			t.Fatalf("decode key: %v", err)
+		}
+		return key
+	}
+	keyA, keyB := newKey(), newKey()
+
+	// doubleBlind 以 first 盲化后再以 second 二次盲化
+	doubleBlind := func(first, second []byte, value string) string {
+		t.Helper()
+		blinded, err := BlindField(first, "BIZ001", "sender", value)
+		if err != nil {
+			t.Fatalf("BlindField() error = %v", err)
+		}
+		reblinded, err := ReblindField(second, blinded)
+		if err != nil {
+			t.Fatalf("ReblindField() error = %v", err)
+		}
+		return reblinded
+	}
+
+	tests := []struct {
+		name   string
+		valueA string
+		valueB string
+		equal  bool
+	}{
+		{"same value", "ACME CORP", "ACME CORP", true},
+		{"different value", "ACME CORP", "ACME CO", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ab := doubleBlind(keyA, keyB, tt.valueA)
+			ba := doubleBlind(keyB, keyA, tt.valueB)
+			if (ab == ba) != tt.equal {
+				t.Errorf("Reblind(b, Blind(a, x)) == Reblind(a, Blind(b, y)) is %v, want %v", ab == ba, tt.equal)
+			}
+		})
+	}
+
+	if _, err := ReblindField(keyA, "not-hex"); err != ErrInvalidBlindedValue {
+		t.Errorf("ReblindField(invalid) error = %v, want %v", err, ErrInvalidBlindedValue)
+	}
+}
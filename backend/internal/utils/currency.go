@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"strings"
+)
+
+// DefaultCurrency 未指定币种时使用的默认币种
+const DefaultCurrency = "CNY"
+
+// iso4217Codes ISO 4217 现行货币代码
+var iso4217Codes = map[string]struct{}{}
+
+func init() {
+	codes := `AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN
+BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL
+GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD
+KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR
+NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC
+SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XAG XAU
+XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XUA YER ZAR ZMW ZWG`
+	for _, code := range strings.Fields(codes) {
+		iso4217Codes[code] = struct{}{}
+	}
+}
+
+// NormalizeCurrency 规范化币种代码(去除空白并转为大写)
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValidCurrency 是否为 ISO 4217 现行货币代码
+func IsValidCurrency(code string) bool {
+	_, ok := iso4217Codes[NormalizeCurrency(code)]
+	return ok
+}
@@ -2,161 +2,359 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
 // ExcelRow Excel行数据
 type ExcelRow struct {
+	RowNum   int    // Excel行号(从1开始)
 	BizID    string // 业务流水号
-	Amount   string // 金额
+	Amount   string // 金额(已规范化为 1234.56 格式)
 	Sender   string // 付款方
 	Receiver string // 收款方
 	TxType   int8   // 交易类型
+	TxDate   string // 交易日期(2006-01-02), 未提供时为空
+	Currency string // 币种(ISO 4217), 未提供时为默认币种
 }
 
+// RowError 行级校验错误
+type RowError struct {
+	Row      int    `json:"row"`    // Excel行号(从1开始)
+	Column   string `json:"column"` // 列名, 整行错误时为空
+	ColIndex int    `json:"-"`      // 列索引(从0开始), -1表示整行错误
+	Value    string `json:"value"`  // 原始值
+	Reason   string `json:"reason"` // 错误原因
+}
+
+// ExcelParseResult Excel校验结果
+type ExcelParseResult struct {
+	Format      string         // 文件格式
+	SheetName   string         // 工作表名称
+	HeaderRow   int            // 表头所在行号(从1开始)
+	LineOffset  int            // 行号偏移: 原文件行号 = 表格行号 + LineOffset
+	Rows        [][]string     // 原始表格数据
+	Header      []string       // 表头
+	FieldIndex  map[string]int // 字段到列索引的映射
+	TotalRows   int            // 数据行总数(不含空行)
+	ValidRows   []ExcelRow     // 校验通过的行
+	InvalidRows []ExcelRow     // 校验未通过的行
+	Errors      []RowError     // 所有行级错误
+}
+
+// ColumnName 获取字段对应的列名(用于错误提示)
+func (r *ExcelParseResult) ColumnName(field string) string {
+	if idx, ok := r.FieldIndex[field]; ok && idx < len(r.Header) && r.Header[idx] != "" {
+		return r.Header[idx]
+	}
+	return defaultColumnNames[field]
+}
+
+// ColumnIndex 获取字段对应的列索引, 未映射时返回-1
+func (r *ExcelParseResult) ColumnIndex(field string) int {
+	if idx, ok := r.FieldIndex[field]; ok {
+		return idx
+	}
+	return -1
+}
+
+// 默认列名常量
+const (
+	ColBizID    = "业务流水号"
+	ColAmount   = "金额"
+	ColSender   = "付款方"
+	ColReceiver = "收款方"
+	ColTxType   = "交易类型"
+	ColTxDate   = "交易日期"
+	ColCurrency = "币种"
+)
+
+// amountPattern 规范化后的金额格式
+var amountPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
 // ParseExcelFile 解析Excel文件
 // 支持的格式: .xlsx, .xls
 // Excel格式要求:
 //   - 第一行为表头
 //   - 必须包含列: 业务流水号, 金额, 付款方, 收款方
-//   - 可选列: 交易类型
+//   - 可选列: 交易类型, 交易日期, 币种
+//
+// 遇到第一个错误行即返回错误, 需要逐行错误明细请使用 ValidateExcelFile
 func ParseExcelFile(filePath string) ([]ExcelRow, error) {
+	result, err := ValidateExcelFile(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Errors) > 0 {
+		first := result.Errors[0]
+		return nil, fmt.Errorf("failed to parse row %d: %s %s", first.Row, first.Column, first.Reason)
+	}
+
+	return result.ValidRows, nil
+}
+
+// ValidateExcelFile 校验Excel文件
+// 与 ParseExcelFile 不同, 不会在第一个错误行中止, 而是收集每一行的全部错误
+// 仅文件级问题(无法打开、缺少必需列等)返回 error
+// opts 为 nil 时使用默认导入配置
+func ValidateExcelFile(filePath string, opts *ParseOptions) (*ExcelParseResult, error) {
+	if opts == nil {
+		opts = DefaultParseOptions()
+	}
+
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open excel file: %w", err)
 	}
 	defer f.Close()
 
-	// 获取第一个工作表
+	// 选择工作表: 配置指定优先, 否则取第一个
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
 		return nil, fmt.Errorf("no sheet found in excel file")
 	}
+	sheetName := sheets[0]
+	if opts.SheetName != "" {
+		if idx, _ := f.GetSheetIndex(opts.SheetName); idx < 0 {
+			return nil, fmt.Errorf("sheet not found: %s", opts.SheetName)
+		}
+		sheetName = opts.SheetName
+	}
 
 	// 读取所有行
-	rows, err := f.GetRows(sheets[0])
+	rows, err := f.GetRows(sheetName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read rows: %w", err)
 	}
 
-	if len(rows) <= 1 {
+	result, err := ValidateRows(sheetName, rows, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Format = FileFormatExcel
+	return result, nil
+}
+
+// ValidateRows 按导入配置校验二维表格数据
+// Excel、CSV、定长文本等格式读取为 [][]string 后统一走此校验
+func ValidateRows(sheetName string, rows [][]string, opts *ParseOptions) (*ExcelParseResult, error) {
+	return validateRows(sheetName, rows, opts, 0)
+}
+
+// validateRows 校验二维表格数据, lineOffset 用于将表格行号换算为原文件行号
+func validateRows(sheetName string, rows [][]string, opts *ParseOptions, lineOffset int) (*ExcelParseResult, error) {
+	if opts == nil {
+		opts = DefaultParseOptions()
+	}
+
+	headerRow := opts.HeaderRow
+	if headerRow < 1 {
+		headerRow = 1
+	}
+
+	if len(rows) <= headerRow {
 		return nil, fmt.Errorf("excel file is empty or only has header")
 	}
 
-	// 解析表头,获取列索引
-	header := rows[0]
-	colIndexMap, err := parseHeader(header)
+	// 解析表头,获取字段到列索引的映射
+	header := rows[headerRow-1]
+	fieldIndex, err := parseHeader(header, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	result := &ExcelParseResult{
+		SheetName:  sheetName,
+		HeaderRow:  headerRow,
+		LineOffset: lineOffset,
+		Rows:       rows,
+		Header:     header,
+		FieldIndex: fieldIndex,
+	}
+
 	// 解析数据行
-	var excelRows []ExcelRow
-	for i := 1; i < len(rows); i++ {
+	seen := make(map[string]int) // 业务流水号 -> 首次出现的行号
+	for i := headerRow; i < len(rows); i++ {
 		row := rows[i]
 
 		// 跳过空行
 		if isEmptyRow(row) {
 			continue
 		}
+		result.TotalRows++
+
+		rowNum := i + 1 + lineOffset
+		excelRow, rowErrs := parseRow(row, result, opts, rowNum)
+
+		if excelRow.BizID != "" {
+			if firstRow, ok := seen[excelRow.BizID]; ok {
+				rowErrs = append(rowErrs, RowError{
+					Row:      rowNum,
+					Column:   result.ColumnName(FieldBizID),
+					ColIndex: result.ColumnIndex(FieldBizID),
+					Value:    excelRow.BizID,
+					Reason:   fmt.Sprintf("文件内业务流水号重复(首次出现于第%d行)", firstRow),
+				})
+			} else {
+				seen[excelRow.BizID] = rowNum
+			}
+		}
 
-		excelRow, err := parseRow(row, colIndexMap)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse row %d: %w", i+1, err)
+		if len(rowErrs) > 0 {
+			result.Errors = append(result.Errors, rowErrs...)
+			result.InvalidRows = append(result.InvalidRows, excelRow)
+			continue
 		}
 
-		excelRows = append(excelRows, excelRow)
+		result.ValidRows = append(result.ValidRows, excelRow)
 	}
 
-	return excelRows, nil
+	return result, nil
 }
 
-// parseHeader 解析表头,返回列名到索引的映射
-func parseHeader(header []string) (map[string]int, error) {
-	colIndexMap := make(map[string]int)
+// parseHeader 解析表头,返回字段到列索引的映射
+// 列定义指定了位置时直接使用位置, 否则按别名(忽略大小写与首尾空白)匹配表头
+func parseHeader(header []string, opts *ParseOptions) (map[string]int, error) {
+	normalized := make(map[string]int, len(header))
+	for colIdx, cell := range header {
+		key := strings.ToLower(strings.TrimSpace(cell))
+		if _, exists := normalized[key]; !exists {
+			normalized[key] = colIdx
+		}
+	}
 
-	requiredColumns := []string{"业务流水号", "金额", "付款方", "收款方"}
-	foundColumns := make(map[string]bool)
+	fieldIndex := make(map[string]int)
+	for _, field := range allFields {
+		spec, ok := opts.Columns[field]
+		if !ok {
+			continue
+		}
 
-	for colIdx, cell := range header {
-		colIndexMap[cell] = colIdx
+		if spec.Position > 0 {
+			fieldIndex[field] = spec.Position - 1
+			continue
+		}
 
-		for _, reqCol := range requiredColumns {
-			if cell == reqCol {
-				foundColumns[reqCol] = true
+		for _, alias := range spec.Aliases {
+			if idx, ok := normalized[strings.ToLower(strings.TrimSpace(alias))]; ok {
+				fieldIndex[field] = idx
+				break
 			}
 		}
 	}
 
-	// 检查必需列是否都存在
-	for _, reqCol := range requiredColumns {
-		if !foundColumns[reqCol] {
-			return nil, fmt.Errorf("missing required column: %s", reqCol)
+	// 检查必需字段: 已映射到列或配置了常量默认值
+	for _, field := range requiredFields {
+		if _, ok := fieldIndex[field]; ok {
+			continue
+		}
+		if _, ok := opts.Defaults[field]; ok {
+			continue
 		}
+		return nil, fmt.Errorf("missing required column: %s", opts.columnLabel(field))
 	}
 
-	return colIndexMap, nil
+	return fieldIndex, nil
 }
 
-// parseRow 解析单行数据
-func parseRow(row []string, colIndexMap map[string]int) (ExcelRow, error) {
-	var excelRow ExcelRow
+// parseRow 解析并校验单行数据, 返回该行的全部错误
+func parseRow(row []string, parsed *ExcelParseResult, opts *ParseOptions, rowNum int) (ExcelRow, []RowError) {
+	excelRow := ExcelRow{RowNum: rowNum}
+	var errs []RowError
 
-	// 业务流水号 (必需)
-	bizIDIdx, ok := colIndexMap["业务流水号"]
-	if !ok || bizIDIdx >= len(row) {
-		return excelRow, fmt.Errorf("invalid biz_id column")
+	cell := func(field string) string {
+		if idx, ok := parsed.FieldIndex[field]; ok && idx < len(row) {
+			if v := strings.TrimSpace(row[idx]); v != "" {
+				return v
+			}
+		}
+		return opts.Defaults[field]
 	}
-	excelRow.BizID = row[bizIDIdx]
+	addErr := func(field, value, reason string) {
+		errs = append(errs, RowError{
+			Row:      rowNum,
+			Column:   parsed.ColumnName(field),
+			ColIndex: parsed.ColumnIndex(field),
+			Value:    value,
+			Reason:   reason,
+		})
+	}
+
+	// 业务流水号 (必需)
+	excelRow.BizID = cell(FieldBizID)
 	if excelRow.BizID == "" {
-		return excelRow, fmt.Errorf("biz_id is empty")
+		addErr(FieldBizID, "", "不能为空")
+	} else if len(excelRow.BizID) > 64 {
+		addErr(FieldBizID, excelRow.BizID, "长度不能超过64个字符")
 	}
 
 	// 金额 (必需)
-	amountIdx, ok := colIndexMap["金额"]
-	if !ok || amountIdx >= len(row) {
-		return excelRow, fmt.Errorf("invalid amount column")
-	}
-	excelRow.Amount = row[amountIdx]
-	if excelRow.Amount == "" {
-		return excelRow, fmt.Errorf("amount is empty")
+	rawAmount := cell(FieldAmount)
+	if rawAmount == "" {
+		addErr(FieldAmount, "", "不能为空")
+	} else if normalized := NormalizeAmount(opts.canonicalAmount(rawAmount)); !amountPattern.MatchString(normalized) {
+		addErr(FieldAmount, rawAmount, "金额格式错误")
+	} else if normalized == "0" {
+		addErr(FieldAmount, rawAmount, "金额必须大于0")
+	} else {
+		excelRow.Amount = normalized
 	}
 
 	// 付款方 (必需)
-	senderIdx, ok := colIndexMap["付款方"]
-	if !ok || senderIdx >= len(row) {
-		return excelRow, fmt.Errorf("invalid sender column")
-	}
-	excelRow.Sender = row[senderIdx]
+	excelRow.Sender = cell(FieldSender)
 	if excelRow.Sender == "" {
-		return excelRow, fmt.Errorf("sender is empty")
+		addErr(FieldSender, "", "不能为空")
+	} else if len(excelRow.Sender) > 128 {
+		addErr(FieldSender, excelRow.Sender, "长度不能超过128个字符")
 	}
 
 	// 收款方 (必需)
-	receiverIdx, ok := colIndexMap["收款方"]
-	if !ok || receiverIdx >= len(row) {
-		return excelRow, fmt.Errorf("invalid receiver column")
-	}
-	excelRow.Receiver = row[receiverIdx]
+	excelRow.Receiver = cell(FieldReceiver)
 	if excelRow.Receiver == "" {
-		return excelRow, fmt.Errorf("receiver is empty")
+		addErr(FieldReceiver, "", "不能为空")
+	} else if len(excelRow.Receiver) > 128 {
+		addErr(FieldReceiver, excelRow.Receiver, "长度不能超过128个字符")
 	}
 
 	// 交易类型 (可选,默认为1)
-	txTypeIdx, ok := colIndexMap["交易类型"]
-	if ok && txTypeIdx < len(row) && row[txTypeIdx] != "" {
-		txType, err := strconv.Atoi(row[txTypeIdx])
-		if err != nil {
-			return excelRow, fmt.Errorf("invalid tx_type: %w", err)
+	if txTypeStr := cell(FieldTxType); txTypeStr != "" {
+		txType, err := strconv.Atoi(txTypeStr)
+		if err != nil || txType < 1 || txType > 3 {
+			addErr(FieldTxType, txTypeStr, "交易类型必须为1-转账, 2-退款, 3-其他")
+		} else {
+			excelRow.TxType = int8(txType)
 		}
-		excelRow.TxType = int8(txType)
 	} else {
 		excelRow.TxType = 1 // 默认为转账
 	}
 
-	return excelRow, nil
+	// 交易日期 (可选)
+	if txDateStr := cell(FieldTxDate); txDateStr != "" {
+		txDate, err := opts.parseDate(txDateStr)
+		if err != nil {
+			addErr(FieldTxDate, txDateStr, "日期格式错误")
+		} else {
+			excelRow.TxDate = txDate.Format("2006-01-02")
+		}
+	}
+
+	// 币种 (可选,默认为人民币)
+	if currency := cell(FieldCurrency); currency != "" {
+		if !IsValidCurrency(currency) {
+			addErr(FieldCurrency, currency, "币种须为ISO 4217货币代码")
+		} else {
+			excelRow.Currency = NormalizeCurrency(currency)
+		}
+	} else {
+		excelRow.Currency = DefaultCurrency
+	}
+
+	return excelRow, errs
 }
 
 // isEmptyRow 检查是否为空行
@@ -176,7 +374,7 @@ func CreateExcelTemplate(filePath string) error {
 	defer f.Close()
 
 	// 设置表头
-	headers := []string{"业务流水号", "金额", "付款方", "收款方", "交易类型"}
+	headers := []string{ColBizID, ColAmount, ColSender, ColReceiver, ColTxType, ColCurrency}
 	sheetName := "Sheet1"
 
 	for colIdx, header := range headers {
@@ -192,8 +390,8 @@ func CreateExcelTemplate(filePath string) error {
 
 	// 添加示例数据
 	examples := []interface{}{
-		"TX20260113001", "1000000", "机构A", "机构B", "1",
-		"TX20260113002", "2000000", "机构B", "机构C", "1",
+		"TX20260113001", "1000000", "机构A", "机构B", "1", "CNY",
+		"TX20260113002", "2000000", "机构B", "机构C", "1", "USD",
 	}
 
 	for colIdx, example := range examples {
@@ -202,7 +400,7 @@ func CreateExcelTemplate(filePath string) error {
 	}
 
 	// 设置列宽
-	f.SetColWidth(sheetName, "A", "E", 20)
+	f.SetColWidth(sheetName, "A", "F", 20)
 
 	if err := f.SaveAs(filePath); err != nil {
 		return fmt.Errorf("failed to save template: %w", err)
@@ -210,3 +408,125 @@ func CreateExcelTemplate(filePath string) error {
 
 	return nil
 }
+
+// CreateErrorWorkbook 基于原始导入文件生成错误标注工作簿
+// 错误单元格标红并附加批注, 行末追加"错误说明"列, 另附"错误明细"工作表
+// Excel 源文件在原工作簿上标注; CSV/定长文本先将解析后的表格写入新工作簿
+func CreateErrorWorkbook(srcPath, dstPath string, parsed *ExcelParseResult, rowErrs []RowError) error {
+	f, sheet, err := openErrorWorkbookSource(srcPath, parsed)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	errStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"FFC7CE"}, Pattern: 1},
+		Font: &excelize.Font{Color: "9C0006"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+
+	// 按行聚合错误原因
+	reasonCol := len(parsed.Header) + 1
+	reasonHeader, _ := excelize.CoordinatesToCellName(reasonCol, parsed.HeaderRow)
+	f.SetCellValue(sheet, reasonHeader, "错误说明")
+	f.SetCellStyle(sheet, reasonHeader, reasonHeader, headerStyle)
+
+	rowReasons := make(map[int][]string)
+	cellReasons := make(map[string][]string)
+	for _, e := range rowErrs {
+		reason := e.Reason
+		if e.Column != "" {
+			reason = e.Column + ": " + e.Reason
+		}
+		sheetRow := e.Row - parsed.LineOffset
+		rowReasons[sheetRow] = append(rowReasons[sheetRow], reason)
+
+		if e.ColIndex >= 0 && e.Column != "" {
+			cell, _ := excelize.CoordinatesToCellName(e.ColIndex+1, sheetRow)
+			cellReasons[cell] = append(cellReasons[cell], e.Reason)
+		}
+	}
+
+	// 标注错误单元格
+	for cell, reasons := range cellReasons {
+		if err := f.SetCellStyle(sheet, cell, cell, errStyle); err != nil {
+			return fmt.Errorf("failed to set cell style: %w", err)
+		}
+		if err := f.AddComment(sheet, excelize.Comment{
+			Cell:   cell,
+			Author: "导入校验",
+			Text:   strings.Join(reasons, "; "),
+		}); err != nil {
+			return fmt.Errorf("failed to add comment: %w", err)
+		}
+	}
+
+	// 行末错误说明
+	rowNums := make([]int, 0, len(rowReasons))
+	for rowNum := range rowReasons {
+		rowNums = append(rowNums, rowNum)
+	}
+	sort.Ints(rowNums)
+	for _, rowNum := range rowNums {
+		cell, _ := excelize.CoordinatesToCellName(reasonCol, rowNum)
+		f.SetCellValue(sheet, cell, strings.Join(rowReasons[rowNum], "; "))
+		f.SetCellStyle(sheet, cell, cell, errStyle)
+	}
+	colName, _ := excelize.ColumnNumberToName(reasonCol)
+	f.SetColWidth(sheet, colName, colName, 50)
+
+	// 错误明细工作表
+	detailSheet := "错误明细"
+	if _, err := f.NewSheet(detailSheet); err != nil {
+		return fmt.Errorf("failed to create sheet: %w", err)
+	}
+	f.SetSheetRow(detailSheet, "A1", &[]interface{}{"行号", "列名", "原始值", "错误原因"})
+	f.SetCellStyle(detailSheet, "A1", "D1", headerStyle)
+	for i, e := range rowErrs {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		f.SetSheetRow(detailSheet, cell, &[]interface{}{e.Row, e.Column, e.Value, e.Reason})
+	}
+	f.SetColWidth(detailSheet, "A", "A", 10)
+	f.SetColWidth(detailSheet, "B", "C", 20)
+	f.SetColWidth(detailSheet, "D", "D", 50)
+
+	if err := f.SaveAs(dstPath); err != nil {
+		return fmt.Errorf("failed to save error workbook: %w", err)
+	}
+
+	return nil
+}
+
+// openErrorWorkbookSource 打开用于标注的工作簿, 返回工作簿与数据所在工作表
+func openErrorWorkbookSource(srcPath string, parsed *ExcelParseResult) (*excelize.File, string, error) {
+	if parsed.Format == FileFormatExcel {
+		f, err := excelize.OpenFile(srcPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open excel file: %w", err)
+		}
+		return f, parsed.SheetName, nil
+	}
+
+	f := excelize.NewFile()
+	sheet := "Sheet1"
+	for i, row := range parsed.Rows {
+		values := make([]interface{}, len(row))
+		for j, v := range row {
+			values[j] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			f.Close()
+			return nil, "", fmt.Errorf("failed to write row %d: %w", i+1, err)
+		}
+	}
+	return f, sheet, nil
+}
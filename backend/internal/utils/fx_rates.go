@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 汇率文件默认列名
+const (
+	ColRateDate      = "日期"
+	ColBaseCurrency  = "基准币种"
+	ColQuoteCurrency = "报价币种"
+	ColRate          = "汇率"
+	ColRateSource    = "来源"
+)
+
+// fxRateAliases 汇率文件列别名(忽略大小写)
+var fxRateAliases = map[string][]string{
+	ColRateDate:      {ColRateDate, "rate_date", "date"},
+	ColBaseCurrency:  {ColBaseCurrency, "base_currency", "base"},
+	ColQuoteCurrency: {ColQuoteCurrency, "quote_currency", "quote"},
+	ColRate:          {ColRate, "rate"},
+	ColRateSource:    {ColRateSource, "source"},
+}
+
+// FXRateRow 汇率文件行: 1 单位基准币种 = Rate 单位报价币种
+type FXRateRow struct {
+	RowNum        int
+	RateDate      string // 2006-01-02
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          string // 规范化后的汇率
+	Source        string
+}
+
+// FXRateParseResult 汇率文件解析结果
+type FXRateParseResult struct {
+	TotalRows int
+	ValidRows []FXRateRow
+	Errors    []RowError
+}
+
+// ParseFXRateFile 解析汇率文件(.xlsx 或 CSV)
+// 首行为表头, 必须包含列: 日期, 基准币种, 报价币种, 汇率; 可选列: 来源
+func ParseFXRateFile(filePath string) (*FXRateParseResult, error) {
+	var rows [][]string
+	if DetectFileFormat(filePath, nil) == FileFormatExcel {
+		f, err := excelize.OpenFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open excel file: %w", err)
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("no sheet found in excel file")
+		}
+		if rows, err = f.GetRows(sheets[0]); err != nil {
+			return nil, fmt.Errorf("failed to read rows: %w", err)
+		}
+	} else {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+		if rows, err = ReadCSVRows(file, DefaultParseOptions()); err != nil {
+			return nil, err
+		}
+	}
+
+	return parseFXRateRows(rows)
+}
+
+// parseFXRateRows 校验汇率表格数据
+func parseFXRateRows(rows [][]string) (*FXRateParseResult, error) {
+	if len(rows) <= 1 {
+		return nil, fmt.Errorf("rate file is empty or only has header")
+	}
+
+	header := make(map[string]int, len(rows[0]))
+	for idx, cell := range rows[0] {
+		key := strings.ToLower(strings.TrimSpace(cell))
+		if _, exists := header[key]; !exists {
+			header[key] = idx
+		}
+	}
+	index := make(map[string]int, len(fxRateAliases))
+	for col, aliases := range fxRateAliases {
+		for _, alias := range aliases {
+			if idx, ok := header[strings.ToLower(alias)]; ok {
+				index[col] = idx
+				break
+			}
+		}
+	}
+	for _, col := range []string{ColRateDate, ColBaseCurrency, ColQuoteCurrency, ColRate} {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("missing required column: %s", col)
+		}
+	}
+
+	opts := DefaultParseOptions()
+	result := &FXRateParseResult{}
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		if isEmptyRow(row) {
+			continue
+		}
+		result.TotalRows++
+
+		rowNum := i + 1
+		cell := func(col string) string {
+			if idx, ok := index[col]; ok && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+			return ""
+		}
+		var errs []RowError
+		addErr := func(col, value, reason string) {
+			errs = append(errs, RowError{Row: rowNum, Column: col, ColIndex: index[col], Value: value, Reason: reason})
+		}
+
+		rate := FXRateRow{
+			RowNum:        rowNum,
+			BaseCurrency:  NormalizeCurrency(cell(ColBaseCurrency)),
+			QuoteCurrency: NormalizeCurrency(cell(ColQuoteCurrency)),
+			Source:        cell(ColRateSource),
+		}
+
+		if value := cell(ColRateDate); value == "" {
+			addErr(ColRateDate, "", "不能为空")
+		} else if d, err := opts.parseDate(value); err != nil {
+			addErr(ColRateDate, value, "日期格式错误")
+		} else {
+			rate.RateDate = d.Format("2006-01-02")
+		}
+
+		if !IsValidCurrency(rate.BaseCurrency) {
+			addErr(ColBaseCurrency, cell(ColBaseCurrency), "币种须为ISO 4217货币代码")
+		}
+		if !IsValidCurrency(rate.QuoteCurrency) {
+			addErr(ColQuoteCurrency, cell(ColQuoteCurrency), "币种须为ISO 4217货币代码")
+		}
+		if rate.BaseCurrency != "" && rate.BaseCurrency == rate.QuoteCurrency {
+			addErr(ColQuoteCurrency, cell(ColQuoteCurrency), "报价币种不能与基准币种相同")
+		}
+
+		value := cell(ColRate)
+		if r, err := ParseAmount(value); err != nil || value == "" {
+			addErr(ColRate, value, "汇率格式错误")
+		} else if r.Sign() <= 0 {
+			addErr(ColRate, value, "汇率必须大于0")
+		} else {
+			rate.Rate = FormatAmount(r)
+		}
+
+		if len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			continue
+		}
+		result.ValidRows = append(result.ValidRows, rate)
+	}
+
+	return result, nil
+}
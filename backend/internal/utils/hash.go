@@ -1,15 +1,20 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 )
 
 // CalculateDataHash 计算数据哈希(用于上链)
-// dataHash = SHA256(bizId + amount + salt)
-func CalculateDataHash(bizId, amount, salt string) string {
-	data := fmt.Sprintf("%s%s%s", bizId, amount, salt)
+// dataHash = SHA256(bizId + amount + currency + salt), 币种不同的同额交易不会碰撞成功
+func CalculateDataHash(bizId, amount, currency, salt string) string {
+	data := fmt.Sprintf("%s%s%s%s", bizId, amount, NormalizeCurrency(currency), salt)
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
@@ -22,7 +27,47 @@ func CalculateBizIdHash(bizId string) string {
 }
 
 // VerifyDataHash 验证数据哈希
-func VerifyDataHash(bizId, amount, salt, expectedHash string) bool {
-	calculatedHash := CalculateDataHash(bizId, amount, salt)
+func VerifyDataHash(bizId, amount, currency, salt, expectedHash string) bool {
+	calculatedHash := CalculateDataHash(bizId, amount, currency, salt)
 	return calculatedHash == expectedHash
 }
+
+// CalculateFileDigest 计算文件摘要(SHA256), 同时返回文件大小
+// 用于识别重复导入的同一文件
+func CalculateFileDigest(filePath string) (string, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// CalculateGroupItemHash 计算对账组明细承诺
+// itemHash = HMAC-SHA256(groupKey, childRef + "|" + 规范化金额 + "|" + 币种)
+// groupKey 由双方线下约定, 参考号和金额可被猜测时也无法离线穷举
+func CalculateGroupItemHash(groupKey, childRef, amount, currency string) string {
+	return groupHMAC(groupKey, childRef+"|"+NormalizeAmount(amount)+"|"+NormalizeCurrency(currency))
+}
+
+// CalculateGroupCommitment 计算对账组承诺(上链哈希)
+// commitment = HMAC-SHA256(groupKey, groupRef + "|" + 排序后的明细承诺(逗号分隔) + "|" + 规范化合计金额 + "|" + 币种)
+// 明细承诺排序后参与计算, 双方录入明细的顺序不影响结果
+func CalculateGroupCommitment(groupKey, groupRef string, itemHashes []string, total, currency string) string {
+	sorted := append([]string(nil), itemHashes...)
+	sort.Strings(sorted)
+	return groupHMAC(groupKey, groupRef+"|"+strings.Join(sorted, ",")+"|"+NormalizeAmount(total)+"|"+NormalizeCurrency(currency))
+}
+
+// groupHMAC 以组密钥计算 HMAC-SHA256
+func groupHMAC(groupKey, data string) string {
+	mac := hmac.New(sha256.New, []byte(groupKey))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,43 @@
+package utils
+
+import "testing"
+
+func TestCalculateGroupCommitment(t *testing.T) {
+	const key = "shared-group-key-0001"
+	items := []string{
+		CalculateGroupItemHash(key, "INV-1", "100.00", "CNY"),
+		CalculateGroupItemHash(key, "INV-2", "50.5", "CNY"),
+	}
+	base := CalculateGroupCommitment(key, "GRP001", items, "150.50", "CNY")
+
+	tests := []struct {
+		name  string
+		got   string
+		equal bool
+	}{
+		{"item order and amount format", CalculateGroupCommitment(key, "GRP001", []string{
+			CalculateGroupItemHash(key, "INV-2", "50.50", "cny"),
+			CalculateGroupItemHash(key, "INV-1", "100", "CNY"),
+		}, "150.5", "cny"), true},
+		{"different group key", CalculateGroupCommitment("another-group-key-02", "GRP001", []string{
+			CalculateGroupItemHash("another-group-key-02", "INV-1", "100.00", "CNY"),
+			CalculateGroupItemHash("another-group-key-02", "INV-2", "50.5", "CNY"),
+		}, "150.50", "CNY"), false},
+		{"different currency", CalculateGroupCommitment(key, "GRP001", []string{
+			CalculateGroupItemHash(key, "INV-1", "100.00", "USD"),
+			CalculateGroupItemHash(key, "INV-2", "50.5", "USD"),
+		}, "150.50", "USD"), false},
+		{"different item amount", CalculateGroupCommitment(key, "GRP001", []string{
+			CalculateGroupItemHash(key, "INV-1", "100.01", "CNY"),
+			CalculateGroupItemHash(key, "INV-2", "50.5", "CNY"),
+		}, "150.50", "CNY"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.got == base) != tt.equal {
+				t.Errorf("commitment equal = %v, want %v", tt.got == base, tt.equal)
+			}
+		})
+	}
+}
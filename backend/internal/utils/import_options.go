@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 导入字段常量
+const (
+	FieldBizID    = "biz_id"
+	FieldAmount   = "amount"
+	FieldSender   = "sender"
+	FieldReceiver = "receiver"
+	FieldTxType   = "tx_type"
+	FieldTxDate   = "tx_date"
+	FieldCurrency = "currency"
+)
+
+// allFields 所有可映射字段
+var allFields = []string{FieldBizID, FieldAmount, FieldSender, FieldReceiver, FieldTxType, FieldTxDate, FieldCurrency}
+
+// requiredFields 必需字段
+var requiredFields = []string{FieldBizID, FieldAmount, FieldSender, FieldReceiver}
+
+// defaultColumnNames 字段默认列名
+var defaultColumnNames = map[string]string{
+	FieldBizID:    ColBizID,
+	FieldAmount:   ColAmount,
+	FieldSender:   ColSender,
+	FieldReceiver: ColReceiver,
+	FieldTxType:   ColTxType,
+	FieldTxDate:   ColTxDate,
+	FieldCurrency: ColCurrency,
+}
+
+// IsImportField 是否为可映射的导入字段
+func IsImportField(field string) bool {
+	_, ok := defaultColumnNames[field]
+	return ok
+}
+
+// ColumnSpec 列定义
+type ColumnSpec struct {
+	Aliases  []string `json:"aliases"`  // 表头别名(忽略大小写)
+	Position int      `json:"position"` // 列位置(从1开始), 0表示按表头别名匹配
+}
+
+// ParseOptions 导入解析选项
+// 由机构的导入配置方案(import profile)转换而来
+type ParseOptions struct {
+	FileFormat         string                // 文件格式: excel, csv, fixed_width, 为空按扩展名推断
+	Encoding           string                // 文本编码: auto, utf-8, gbk, gb18030 (仅文本格式)
+	Delimiter          string                // CSV分隔符, 默认 ","
+	Quote              string                // CSV引号字符, 默认 "
+	FixedColumns       []FixedWidthColumn    // 定长列定义
+	FixedUnit          string                // 定长计量单位: byte(默认), char
+	SkipLines          int                   // 定长文本开头跳过的行数(如原有表头行)
+	SheetName          string                // 工作表名称, 为空取第一个
+	HeaderRow          int                   // 表头所在行(从1开始), 默认1
+	Columns            map[string]ColumnSpec // 字段 -> 列定义
+	DateFormat         string                // 日期格式, 如 yyyy-MM-dd, 为空时尝试常见格式
+	DecimalSeparator   string                // 小数分隔符, 默认 "."
+	ThousandsSeparator string                // 千分位分隔符, 默认 ","
+	Defaults           map[string]string     // 字段 -> 常量默认值(列缺失或单元格为空时使用)
+}
+
+// DefaultParseOptions 默认导入配置(中文表头, 兼容常见英文表头)
+func DefaultParseOptions() *ParseOptions {
+	return &ParseOptions{
+		HeaderRow: 1,
+		Columns: map[string]ColumnSpec{
+			FieldBizID:    {Aliases: []string{ColBizID, "biz_id", "BizID", "Reference"}},
+			FieldAmount:   {Aliases: []string{ColAmount, "amount"}},
+			FieldSender:   {Aliases: []string{ColSender, "sender", "payer"}},
+			FieldReceiver: {Aliases: []string{ColReceiver, "receiver", "payee"}},
+			FieldTxType:   {Aliases: []string{ColTxType, "tx_type"}},
+			FieldTxDate:   {Aliases: []string{ColTxDate, "tx_date", "date"}},
+			FieldCurrency: {Aliases: []string{ColCurrency, "currency", "ccy"}},
+		},
+		DecimalSeparator:   ".",
+		ThousandsSeparator: ",",
+	}
+}
+
+// columnLabel 字段的展示名称(用于文件级错误提示)
+func (o *ParseOptions) columnLabel(field string) string {
+	if spec, ok := o.Columns[field]; ok && len(spec.Aliases) > 0 {
+		return spec.Aliases[0]
+	}
+	return defaultColumnNames[field]
+}
+
+// canonicalAmount 按配置的分隔符将金额转为 1234.56 格式
+func (o *ParseOptions) canonicalAmount(amount string) string {
+	decimalSep := o.DecimalSeparator
+	if decimalSep == "" {
+		decimalSep = "."
+	}
+	thousandsSep := o.ThousandsSeparator
+	if thousandsSep == "" && decimalSep != "," {
+		thousandsSep = ","
+	}
+
+	s := strings.ReplaceAll(amount, " ", "")
+	if thousandsSep != "" {
+		s = strings.ReplaceAll(s, thousandsSep, "")
+	}
+	if decimalSep != "." {
+		s = strings.ReplaceAll(s, decimalSep, ".")
+	}
+	return s
+}
+
+// commonDateLayouts 未配置日期格式时尝试的格式
+var commonDateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"20060102",
+	"2006-01-02 15:04:05",
+	"2006/1/2",
+	"01-02-06",
+}
+
+// parseDate 按配置的日期格式解析日期
+func (o *ParseOptions) parseDate(value string) (time.Time, error) {
+	if o.DateFormat != "" {
+		return time.ParseInLocation(ConvertDateLayout(o.DateFormat), value, time.Local)
+	}
+
+	for _, layout := range commonDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date: %s", value)
+}
+
+// dateLayoutReplacer 常见日期格式占位符到Go布局的映射
+var dateLayoutReplacer = strings.NewReplacer(
+	"yyyy", "2006",
+	"yy", "06",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+// ConvertDateLayout 将 yyyy-MM-dd 风格的日期格式转为Go时间布局
+// 已经是Go布局(如 2006-01-02)时原样返回
+func ConvertDateLayout(format string) string {
+	return dateLayoutReplacer.Replace(format)
+}
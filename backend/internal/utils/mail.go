@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMailNotConfigured 未配置SMTP服务器
+var ErrMailNotConfigured = errors.New("smtp server not configured")
+
+// SMTPMailer SMTP邮件发送
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer 创建SMTP邮件发送器, host 为空时发送返回 ErrMailNotConfigured
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	if port <= 0 {
+		port = 25
+	}
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Configured 是否已配置SMTP服务器
+func (m *SMTPMailer) Configured() bool {
+	return m != nil && m.host != ""
+}
+
+// Send 发送纯文本邮件(UTF-8), 服务器支持时自动启用 STARTTLS
+func (m *SMTPMailer) Send(to []string, subject, body string) error {
+	if !m.Configured() {
+		return ErrMailNotConfigured
+	}
+	if len(to) == 0 {
+		return errors.New("no mail recipients")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	if err := smtp.SendMail(m.addr, auth, m.from, to, BuildMailMessage(m.from, to, subject, body)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// BuildMailMessage 构造邮件内容: 主题按 RFC 2047 编码, 正文 base64 编码
+func BuildMailMessage(from string, to []string, subject, body string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
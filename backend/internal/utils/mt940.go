@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mt940StatementLine :61: 字段格式
+// 起息日(YYMMDD) [记账日(MMDD)] 借贷标记(C/D/RC/RD) [资金代码] 金额 交易类型码 参考号[//银行参考号]
+var mt940StatementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d*)([NFS][A-Z0-9]{3})(.*)$`)
+
+// mt940OpeningBalance :60F:/:60M: 字段格式, 用于获取币种
+var mt940OpeningBalance = regexp.MustCompile(`^[CD]\d{6}([A-Z]{3})`)
+
+// mt940SwiftCode :86: 中 /CODE/value 形式的结构化子字段
+var mt940SwiftCode = regexp.MustCompile(`/(EREF|NAME|ORDP|BENM|REMI|IREF|KREF|MREF|CRNM|DBNM)/`)
+
+// mt940GermanCode :86: 中 ?NN 形式的结构化子字段(德国银行常用)
+var mt940GermanCode = regexp.MustCompile(`\?(\d{2})`)
+
+// mt940Field MT940 字段
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+// ParseMT940 解析 SWIFT MT940 对账单
+// 每个 :61: 生成一条明细, 紧随其后的 :86: 提供端到端ID与对手方名称
+func ParseMT940(text string) ([]StatementEntry, error) {
+	fields := splitMT940Fields(text)
+
+	var (
+		entries  []StatementEntry
+		account  string
+		currency string
+		current  *StatementEntry
+	)
+	flush := func() {
+		if current != nil {
+			entries = append(entries, *current)
+			current = nil
+		}
+	}
+
+	for _, f := range fields {
+		switch f.tag {
+		case "20":
+			flush()
+		case "25":
+			account = strings.TrimSpace(f.value)
+		case "60F", "60M":
+			if m := mt940OpeningBalance.FindStringSubmatch(f.value); m != nil {
+				currency = m[1]
+			}
+		case "61":
+			flush()
+			entry, err := parseMT940StatementLine(f.value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", f.line, err)
+			}
+			entry.Owner = account
+			entry.Currency = currency
+			entry.Line = f.line
+			current = entry
+		case "86":
+			if current != nil {
+				applyMT940Information(current, f.value)
+			}
+		case "62F", "62M", "64", "65":
+			flush()
+		}
+	}
+	flush()
+
+	return entries, nil
+}
+
+// splitMT940Fields 按 :TAG: 切分字段, 非标签开头的行视为上一字段的续行
+func splitMT940Fields(text string) []mt940Field {
+	var fields []mt940Field
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \r")
+
+		// 去除 SWIFT 报文块包装 {1:...}{2:...}{4: 与结束符 -}
+		if idx := strings.Index(line, "{4:"); idx >= 0 {
+			line = line[idx+3:]
+		}
+		if line == "" || line == "-}" || line == "-" {
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				fields = append(fields, mt940Field{
+					tag:   line[1 : end+1],
+					value: line[end+2:],
+					line:  i + 1,
+				})
+				continue
+			}
+		}
+
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	return fields
+}
+
+// parseMT940StatementLine 解析 :61: 字段
+func parseMT940StatementLine(value string) (*StatementEntry, error) {
+	firstLine, supplementary, _ := strings.Cut(value, "\n")
+
+	m := mt940StatementLine.FindStringSubmatch(firstLine)
+	if m == nil {
+		return nil, fmt.Errorf("invalid :61: statement line: %s", firstLine)
+	}
+
+	entry := &StatementEntry{
+		ValueDate: mt940Date(m[1]),
+		Amount:    strings.TrimSuffix(strings.Replace(m[5], ",", ".", 1), "."),
+		Reversal:  strings.HasPrefix(m[3], "R"),
+	}
+	if m[2] != "" {
+		entry.BookingDate = mt940BookingDate(m[1], m[2])
+	}
+
+	// RC(贷记冲正)实际为借记, RD(借记冲正)实际为贷记
+	switch m[3] {
+	case "C", "RD":
+		entry.Direction = DirectionCredit
+	default:
+		entry.Direction = DirectionDebit
+	}
+
+	ownerRef, bankRef, _ := strings.Cut(m[7], "//")
+	ownerRef = strings.TrimSpace(ownerRef)
+	bankRef = strings.TrimSpace(bankRef)
+	switch {
+	case ownerRef != "" && ownerRef != "NONREF":
+		entry.Reference = ownerRef
+	default:
+		entry.Reference = bankRef
+	}
+
+	if entry.Counterparty == "" {
+		entry.Counterparty = strings.TrimSpace(supplementary)
+	}
+
+	return entry, nil
+}
+
+// applyMT940Information 从 :86: 字段提取端到端ID与对手方名称
+func applyMT940Information(entry *StatementEntry, value string) {
+	info := strings.ReplaceAll(value, "\n", "")
+
+	var eref, name string
+	switch {
+	case mt940SwiftCode.MatchString(info):
+		codes := splitCoded(info, mt940SwiftCode)
+		eref = codes["EREF"]
+		name = firstNonEmpty(codes["NAME"], codes["ORDP"], codes["BENM"], codes["CRNM"], codes["DBNM"])
+	case mt940GermanCode.MatchString(info):
+		codes := splitCoded(info, mt940GermanCode)
+		name = strings.TrimSpace(codes["32"] + codes["33"])
+		for _, key := range []string{"20", "21", "22", "23", "24", "25", "26", "27", "28", "29"} {
+			if ref, ok := strings.CutPrefix(codes[key], "EREF+"); ok {
+				eref = ref
+				break
+			}
+		}
+	default:
+		name = strings.TrimSpace(info)
+	}
+
+	if eref = strings.TrimSpace(eref); eref != "" && eref != "NOTPROVIDED" {
+		entry.Reference = eref
+	}
+	if name != "" {
+		entry.Counterparty = name
+	}
+}
+
+// splitCoded 按子字段代码切分文本, 返回 代码 -> 内容
+func splitCoded(text string, pattern *regexp.Regexp) map[string]string {
+	result := make(map[string]string)
+	locs := pattern.FindAllStringSubmatchIndex(text, -1)
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		code := text[loc[2]:loc[3]]
+		result[code] += strings.TrimSpace(text[loc[1]:end])
+	}
+	return result
+}
+
+// mt940Date 将 YYMMDD 转为 2006-01-02
+func mt940Date(yymmdd string) string {
+	if len(yymmdd) != 6 {
+		return ""
+	}
+	return "20" + yymmdd[:2] + "-" + yymmdd[2:4] + "-" + yymmdd[4:6]
+}
+
+// mt940BookingDate 由起息日(YYMMDD)推算记账日(MMDD)的年份
+// 记账日不带年份, 跨年时可能早于或晚于起息日所在年份, 取与起息日最接近的年份; 相差超过半年视为无效
+func mt940BookingDate(valueDate, mmdd string) string {
+	const maxDistance = 183 * 24 * time.Hour
+
+	value, err := time.Parse("20060102", "20"+valueDate)
+	if err != nil {
+		return ""
+	}
+
+	var booking time.Time
+	for _, year := range []int{value.Year() - 1, value.Year(), value.Year() + 1} {
+		d, err := time.Parse("20060102", fmt.Sprintf("%04d%s", year, mmdd))
+		if err != nil {
+			continue // 如非闰年的0229
+		}
+		if distance := absDuration(d.Sub(value)); distance > maxDistance ||
+			(!booking.IsZero() && distance >= absDuration(booking.Sub(value))) {
+			continue
+		}
+		booking = d
+	}
+	if booking.IsZero() {
+		return ""
+	}
+	return booking.Format("2006-01-02")
+}
+
+// absDuration 返回时间间隔的绝对值
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
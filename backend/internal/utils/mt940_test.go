@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseMT940(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []StatementEntry
+	}{
+		{
+			name: "swift coded information",
+			text: ":20:STMT001\n" +
+				":25:6222020200112233\n" +
+				":28C:1/1\n" +
+				":60F:C240102CNY1000,00\n" +
+				":61:2401020102C1234,56NTRFBIZ001//BANK001\n" +
+				":86:/EREF/E2E001/NAME/Shanghai Trading Co\n" +
+				":62F:C240102CNY2234,56\n",
+			want: []StatementEntry{{
+				Reference:    "E2E001",
+				Amount:       "1234.56",
+				Currency:     "CNY",
+				Direction:    DirectionCredit,
+				Owner:        "6222020200112233",
+				Counterparty: "Shanghai Trading Co",
+				BookingDate:  "2024-01-02",
+				ValueDate:    "2024-01-02",
+				Line:         5,
+			}},
+		},
+		{
+			name: "german coded information without booking date",
+			text: "{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:\n" +
+				":20:STMT002\n" +
+				":25:DE89370400440532013000\n" +
+				":60F:C240301EUR0,\n" +
+				":61:240305D50,NTRFNONREF//B2403\n" +
+				"05\n" +
+				":86:166?20EREF+E2E-777?21SVWZ+Miete?32Max Muster\n" +
+				"?33mann\n" +
+				":62F:D240305EUR50,\n" +
+				"-}\n",
+			want: []StatementEntry{{
+				Reference:    "E2E-777",
+				Amount:       "50",
+				Currency:     "EUR",
+				Direction:    DirectionDebit,
+				Owner:        "DE89370400440532013000",
+				Counterparty: "Max Mustermann",
+				ValueDate:    "2024-03-05",
+				Line:         5,
+			}},
+		},
+		{
+			name: "reversal and free text information",
+			text: ":20:STMT003\n" +
+				":25:ACC1\n" +
+				":60F:C240410USD0,\n" +
+				":61:2404100410RC10,5NMSCREF1\n" +
+				":86:Refund to customer\n",
+			want: []StatementEntry{{
+				Reference:    "REF1",
+				Amount:       "10.5",
+				Currency:     "USD",
+				Direction:    DirectionDebit,
+				Reversal:     true,
+				Owner:        "ACC1",
+				Counterparty: "Refund to customer",
+				BookingDate:  "2024-04-10",
+				ValueDate:    "2024-04-10",
+				Line:         4,
+			}},
+		},
+		{
+			name: "booking date in the next year",
+			text: ":20:STMT004\n" +
+				":25:ACC2\n" +
+				":60F:C231231CNY0,\n" +
+				":61:2312310102C100,NTRFREF2\n",
+			want: []StatementEntry{{
+				Reference:   "REF2",
+				Amount:      "100",
+				Currency:    "CNY",
+				Direction:   DirectionCredit,
+				Owner:       "ACC2",
+				BookingDate: "2024-01-02",
+				ValueDate:   "2023-12-31",
+				Line:        4,
+			}},
+		},
+		{
+			name: "booking date in the previous year",
+			text: ":20:STMT005\n" +
+				":25:ACC3\n" +
+				":60F:C240102CNY0,\n" +
+				":61:2401021229D7,25NCHGREF3\n",
+			want: []StatementEntry{{
+				Reference:   "REF3",
+				Amount:      "7.25",
+				Currency:    "CNY",
+				Direction:   DirectionDebit,
+				Owner:       "ACC3",
+				BookingDate: "2023-12-29",
+				ValueDate:   "2024-01-02",
+				Line:        4,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMT940(tt.text)
+			if err != nil {
+				t.Fatalf("ParseMT940() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMT940() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMT940InvalidStatementLine(t *testing.T) {
+	_, err := ParseMT940(":20:STMT\n:25:ACC\n:61:NOT A STATEMENT LINE\n")
+	if err == nil {
+		t.Fatal("ParseMT940() error = nil, want error for malformed :61:")
+	}
+}
+
+func TestMT940BookingDate(t *testing.T) {
+	tests := []struct {
+		valueDate string
+		mmdd      string
+		want      string
+	}{
+		{"240315", "0315", "2024-03-15"},
+		{"240315", "0318", "2024-03-18"},
+		{"231231", "0102", "2024-01-02"},
+		{"240102", "1231", "2023-12-31"},
+		{"240228", "0229", "2024-02-29"},
+		{"230228", "0229", ""},
+		{"2312", "0102", ""},
+	}
+
+	for _, tt := range tests {
+		if got := mt940BookingDate(tt.valueDate, tt.mmdd); got != tt.want {
+			t.Errorf("mt940BookingDate(%q, %q) = %q, want %q", tt.valueDate, tt.mmdd, got, tt.want)
+		}
+	}
+}
+
+func TestValidateImportFileStatementUsesProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statement.sta")
+	statement := ":20:STMT006\n:25:ACC4\n:60F:C240102CNY0,\n:61:2401020102D12,50NTRFREF6\n"
+	if err := os.WriteFile(path, []byte(statement), 0o600); err != nil {
+		t.Fatalf("write statement: %v", err)
+	}
+
+	// 借记明细缺少对手方名称, 收款方取导入方案的默认值
+	opts := DefaultParseOptions()
+	opts.Defaults = map[string]string{FieldReceiver: "UNKNOWN PAYEE"}
+	result, err := ValidateImportFile(path, opts)
+	if err != nil {
+		t.Fatalf("ValidateImportFile() error = %v", err)
+	}
+	if result.Format != FileFormatMT940 || len(result.ValidRows) != 1 {
+		t.Fatalf("ValidateImportFile() = format %q, %d valid rows, errors %+v", result.Format, len(result.ValidRows), result.Errors)
+	}
+	if got := result.ValidRows[0].Receiver; got != "UNKNOWN PAYEE" {
+		t.Errorf("receiver = %q, want %q", got, "UNKNOWN PAYEE")
+	}
+}
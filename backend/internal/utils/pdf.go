@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF页面参数(A4, 单位: point)
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// TextPDF 纯文本PDF生成器
+// 使用内置 Courier 等宽字体, 无需外部依赖; 内置字体仅支持ASCII, 其余字符以 '?' 代替
+type TextPDF struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	y       float64
+}
+
+// NewTextPDF 创建纯文本PDF
+func NewTextPDF() *TextPDF {
+	p := &TextPDF{}
+	p.newPage()
+	return p
+}
+
+func (p *TextPDF) newPage() {
+	p.current = &bytes.Buffer{}
+	p.pages = append(p.pages, p.current)
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// AddLine 添加一行文本, 超出页宽自动折行, 超出页高自动分页
+func (p *TextPDF) AddLine(text string, size float64) {
+	// Courier 字宽为字号的0.6倍
+	maxChars := int((pdfPageWidth - 2*pdfMargin) / (size * 0.6))
+	text = pdfASCII(text)
+
+	for {
+		line := text
+		if len(line) > maxChars {
+			line = text[:maxChars]
+		}
+		p.writeLine(line, size)
+
+		if len(text) <= maxChars {
+			return
+		}
+		text = text[maxChars:]
+	}
+}
+
+// AddBlank 添加空行
+func (p *TextPDF) AddBlank(size float64) {
+	p.writeLine("", size)
+}
+
+func (p *TextPDF) writeLine(line string, size float64) {
+	leading := size * 1.4
+	if p.y-leading < pdfMargin {
+		p.newPage()
+	}
+	p.y -= leading
+	if line == "" {
+		return
+	}
+	fmt.Fprintf(p.current, "BT /F1 %.1f Tf %.1f %.1f Td (%s) Tj ET\n", size, pdfMargin, p.y, pdfEscape(line))
+}
+
+// Bytes 生成PDF文件内容
+func (p *TextPDF) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// 对象编号: 1-Catalog, 2-Pages, 3-Font, 之后每页依次为 Page 与 Contents
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range p.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+i*2))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfASCII 将非ASCII字符替换为 '?'
+func pdfASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x20 || r > 0x7E {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pdfEscape 转义PDF字符串中的特殊字符
+func pdfEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(s)
+}
@@ -0,0 +1,435 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 导入文件格式常量
+const (
+	FileFormatExcel      = "excel"       // .xlsx / .xls
+	FileFormatCSV        = "csv"         // 分隔符文本
+	FileFormatFixedWidth = "fixed_width" // 定长文本
+)
+
+// 文本编码常量
+const (
+	EncodingAuto    = "auto"
+	EncodingUTF8    = "utf-8"
+	EncodingGBK     = "gbk"
+	EncodingGB18030 = "gb18030"
+)
+
+// 定长字段计量单位
+const (
+	FixedUnitByte = "byte" // 按原始编码字节计算(核心系统导出的常见约定)
+	FixedUnitChar = "char" // 按解码后的字符计算
+)
+
+// utf8BOM UTF-8 字节顺序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// encodingSniffSize 逐行读取时用于自动检测编码的文件开头字节数
+const encodingSniffSize = 64 * 1024
+
+// errInvalidUTF8 按UTF-8读取时遇到非法字节
+var errInvalidUTF8 = errors.New("invalid utf-8 text")
+
+// FixedWidthColumn 定长文本列定义
+type FixedWidthColumn struct {
+	Name  string `json:"name"`  // 列名(作为表头参与别名匹配)
+	Start int    `json:"start"` // 起始位置(从1开始)
+	Width int    `json:"width"` // 宽度
+}
+
+// ValidateImportFile 按文件格式读取导入文件并统一校验
+// 格式由 opts.FileFormat 指定, 为空时按扩展名推断: .xlsx/.xls 为Excel, 其余文本文件
+// 在配置了定长列时按定长解析, 识别为 MT940/camt.053 对账单时按对账单解析, 否则按CSV解析
+func ValidateImportFile(filePath string, opts *ParseOptions) (*ExcelParseResult, error) {
+	if opts == nil {
+		opts = DefaultParseOptions()
+	}
+
+	switch format := DetectFileFormat(filePath, opts); format {
+	case FileFormatExcel:
+		return ValidateExcelFile(filePath, opts)
+	case FileFormatMT940, FileFormatCamt053:
+		return ValidateStatementFile(filePath, format, opts, nil)
+	case FileFormatFixedWidth:
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+		rows, err := ReadFixedWidthRows(file, opts)
+		if err != nil {
+			return nil, err
+		}
+		// 首行为生成的表头, 行号偏移后与原文件行号一致
+		fixed := *opts
+		fixed.HeaderRow = 1
+		result, err := validateRows(filepath.Base(filePath), rows, &fixed, opts.SkipLines-1)
+		if err != nil {
+			return nil, err
+		}
+		result.Format = FileFormatFixedWidth
+		return result, nil
+	default:
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+		rows, err := ReadCSVRows(file, opts)
+		if err != nil {
+			return nil, err
+		}
+		result, err := ValidateRows(filepath.Base(filePath), rows, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Format = FileFormatCSV
+		return result, nil
+	}
+}
+
+// DetectFileFormat 判断导入文件格式
+func DetectFileFormat(filePath string, opts *ParseOptions) string {
+	if opts != nil && opts.FileFormat != "" {
+		return opts.FileFormat
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".xlsx", ".xls", ".xlsm":
+		return FileFormatExcel
+	}
+
+	if opts != nil && len(opts.FixedColumns) > 0 {
+		return FileFormatFixedWidth
+	}
+	if format := detectStatementFormat(filePath); format != "" {
+		return format
+	}
+	return FileFormatCSV
+}
+
+// DetectEncoding 检测文本编码
+// 带BOM或合法UTF-8视为UTF-8; 否则按GB18030解码, 未出现四字节序列时报告为GBK
+func DetectEncoding(data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+		return EncodingUTF8
+	}
+
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			i++
+		case i+3 < len(data) && data[i+1] >= 0x30 && data[i+1] <= 0x39:
+			// GB18030 四字节序列: 第二字节为数字 0x30-0x39
+			return EncodingGB18030
+		default:
+			i += 2
+		}
+	}
+	return EncodingGBK
+}
+
+// DecodeText 将文本按指定编码转为UTF-8, encoding 为空或 auto 时自动检测
+func DecodeText(data []byte, encoding string) (string, error) {
+	if encoding == "" || encoding == EncodingAuto {
+		encoding = DetectEncoding(data)
+	}
+
+	switch strings.ToLower(encoding) {
+	case EncodingUTF8, "utf8":
+		if !utf8.Valid(data) {
+			return "", errInvalidUTF8
+		}
+		return string(bytes.TrimPrefix(data, utf8BOM)), nil
+	case EncodingGBK:
+		decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode gbk: %w", err)
+		}
+		return string(decoded), nil
+	case EncodingGB18030:
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode gb18030: %w", err)
+		}
+		return string(decoded), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// ReadCSVRows 读取分隔符文本为二维表格
+// 支持自定义分隔符与引号字符, 引号内允许分隔符和换行, 连续两个引号表示一个引号字面量; 逐行读取解码
+func ReadCSVRows(r io.Reader, opts *ParseOptions) ([][]string, error) {
+	lines, err := newLineReader(r, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := ','
+	if opts.Delimiter != "" {
+		delimiter, _ = utf8.DecodeRuneInString(unescapeDelimiter(opts.Delimiter))
+	}
+	quote := '"'
+	if opts.Quote != "" {
+		quote, _ = utf8.DecodeRuneInString(opts.Quote)
+	}
+
+	var (
+		rows     [][]string
+		row      []string
+		field    strings.Builder
+		inQuotes bool
+	)
+	for {
+		rawLine, err := lines.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		text, err := DecodeText(rawLine, lines.encoding)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lines.line, err)
+		}
+		text += "\n"
+
+		for i := 0; i < len(text); {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			i += size
+
+			if inQuotes {
+				if r == quote {
+					if next, nextSize := utf8.DecodeRuneInString(text[i:]); next == quote && nextSize > 0 {
+						field.WriteRune(quote)
+						i += nextSize
+					} else {
+						inQuotes = false
+					}
+					continue
+				}
+				field.WriteRune(r)
+				continue
+			}
+
+			switch r {
+			case quote:
+				inQuotes = true
+			case delimiter:
+				row = append(row, field.String())
+				field.Reset()
+			case '\r':
+				// 忽略, 由 \n 结束行
+			case '\n':
+				row = append(row, field.String())
+				field.Reset()
+				rows = append(rows, row)
+				row = nil
+			default:
+				field.WriteRune(r)
+			}
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quoted field near line %d", lines.line)
+	}
+	return rows, nil
+}
+
+// ReadFixedWidthRows 读取定长文本为二维表格
+// 跳过文件开头 opts.SkipLines 行后每行按定长切分, 返回结果的首行为按列定义生成的表头; 逐行读取解码
+func ReadFixedWidthRows(r io.Reader, opts *ParseOptions) ([][]string, error) {
+	if len(opts.FixedColumns) == 0 {
+		return nil, fmt.Errorf("fixed width columns are not configured")
+	}
+
+	lines, err := newLineReader(r, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]string, len(opts.FixedColumns))
+	for i, col := range opts.FixedColumns {
+		header[i] = col.Name
+	}
+	rows := [][]string{header}
+
+	for {
+		rawLine, err := lines.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if lines.line <= opts.SkipLines {
+			continue
+		}
+
+		row, err := splitFixedWidthLine(bytes.TrimRight(rawLine, "\r"), lines.encoding, opts)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lines.line, err)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// lineReader 逐行读取文本文件并确定每行的编码
+// 自动检测编码时只检查文件开头 encodingSniffSize 字节; 开头均为ASCII而后续出现非法UTF-8时
+// 改按GB18030(兼容GBK)读取其余内容, 已出现非ASCII的UTF-8内容后再遇到非法UTF-8则拒绝导入
+type lineReader struct {
+	reader   *bufio.Reader
+	encoding string // 当前行的编码
+	auto     bool   // 编码为自动检测结果
+	nonASCII bool   // 已读取到非ASCII的UTF-8内容
+	line     int    // 当前行号(从1开始)
+}
+
+// newLineReader 创建逐行读取器, encoding 为空或 auto 时按文件开头自动检测, 并跳过UTF-8 BOM
+func newLineReader(r io.Reader, encoding string) (*lineReader, error) {
+	l := &lineReader{
+		reader:   bufio.NewReaderSize(r, encodingSniffSize),
+		encoding: strings.ToLower(encoding),
+	}
+	if l.encoding == "utf8" {
+		l.encoding = EncodingUTF8
+	}
+	if l.encoding == "" || l.encoding == EncodingAuto {
+		l.auto = true
+		prefix, err := l.reader.Peek(encodingSniffSize)
+		switch {
+		case err == nil:
+			// 开头可能截断在多字节字符中间, 去掉不完整的字符再检测
+			l.encoding = DetectEncoding(trimPartialRune(prefix))
+		case err == io.EOF:
+			l.encoding = DetectEncoding(prefix)
+		default:
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	if bom, _ := l.reader.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		l.reader.Discard(len(utf8BOM))
+	}
+	return l, nil
+}
+
+// readLine 读取下一行(不含换行符)并校验编码, 文件结束时返回 io.EOF
+// GBK/GB18030 多字节字符不含 0x0A, 按换行符切分不会截断字符
+func (l *lineReader) readLine() ([]byte, error) {
+	rawLine, err := l.reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(rawLine) == 0 && err == io.EOF {
+		return nil, io.EOF
+	}
+	l.line++
+	rawLine = bytes.TrimSuffix(rawLine, []byte("\n"))
+
+	if l.encoding != EncodingUTF8 {
+		return rawLine, nil
+	}
+	switch {
+	case utf8.Valid(rawLine):
+		if !l.nonASCII && !isASCII(rawLine) {
+			l.nonASCII = true
+		}
+	case l.auto && !l.nonASCII:
+		// 此前均为ASCII, 与GB18030解码结果一致, 后续按GB18030读取
+		l.encoding = EncodingGB18030
+	default:
+		return nil, fmt.Errorf("line %d: %w", l.line, errInvalidUTF8)
+	}
+	return rawLine, nil
+}
+
+// isASCII 是否全部为ASCII字符
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// splitFixedWidthLine 按列定义切分一行定长文本
+func splitFixedWidthLine(rawLine []byte, encoding string, opts *ParseOptions) ([]string, error) {
+	row := make([]string, len(opts.FixedColumns))
+	if opts.FixedUnit == FixedUnitChar {
+		decoded, err := DecodeText(rawLine, encoding)
+		if err != nil {
+			return nil, err
+		}
+		runes := []rune(decoded)
+		for i, col := range opts.FixedColumns {
+			row[i] = strings.TrimSpace(string(sliceRange(runes, col.Start, col.Width)))
+		}
+		return row, nil
+	}
+
+	for i, col := range opts.FixedColumns {
+		decoded, err := DecodeText(sliceRange(rawLine, col.Start, col.Width), encoding)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		row[i] = strings.TrimSpace(decoded)
+	}
+	return row, nil
+}
+
+// trimPartialRune 去掉末尾不完整的 UTF-8 字符
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			return data
+		}
+	}
+	return data
+}
+
+// sliceRange 按起始位置(从1开始)和宽度截取, 越界部分忽略
+func sliceRange[T any](s []T, start, width int) []T {
+	from := start - 1
+	if from < 0 {
+		from = 0
+	}
+	if from >= len(s) {
+		return nil
+	}
+	to := from + width
+	if to > len(s) {
+		to = len(s)
+	}
+	return s[from:to]
+}
+
+// unescapeDelimiter 支持以 \t 形式配置制表符
+func unescapeDelimiter(delimiter string) string {
+	if delimiter == `\t` {
+		return "\t"
+	}
+	return delimiter
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// mustGBK 将UTF-8文本编码为GBK
+func mustGBK(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("encode gbk: %v", err)
+	}
+	return data
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"ascii", []byte("biz_id,amount\nA001,1.00\n"), EncodingUTF8},
+		{"utf-8", []byte("付款方,收款方\n"), EncodingUTF8},
+		{"utf-8 with bom", append(append([]byte{}, utf8BOM...), "付款方"...), EncodingUTF8},
+		{"gbk", mustGBK(t, "付款方,收款方\n上海银行,北京银行\n"), EncodingGBK},
+		{"gb18030 four-byte sequence", []byte{0xB8, 0xB6, 0x81, 0x30, 0x81, 0x30}, EncodingGB18030},
+		{"empty", nil, EncodingUTF8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectEncoding(tt.data); got != tt.want {
+				t.Errorf("DetectEncoding() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		opts    ParseOptions
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "default delimiter and crlf",
+			data: []byte("biz_id,amount\r\nA001,1.00\r\nA002,2.50"),
+			want: [][]string{{"biz_id", "amount"}, {"A001", "1.00"}, {"A002", "2.50"}},
+		},
+		{
+			name: "quoted delimiter, newline and escaped quote",
+			data: []byte("biz_id,remark\nA001,\"a,b\"\nA002,\"line1\nline2\"\nA003,\"say \"\"hi\"\"\"\n"),
+			want: [][]string{{"biz_id", "remark"}, {"A001", "a,b"}, {"A002", "line1\nline2"}, {"A003", `say "hi"`}},
+		},
+		{
+			name: "tab delimiter and custom quote",
+			data: []byte("biz_id\tname\nA001\t'x\ty'\n"),
+			opts: ParseOptions{Delimiter: `\t`, Quote: "'"},
+			want: [][]string{{"biz_id", "name"}, {"A001", "x\ty"}},
+		},
+		{
+			name: "gbk content",
+			data: mustGBK(t, "流水号,付款方\nA001,上海银行\n"),
+			opts: ParseOptions{Encoding: EncodingAuto},
+			want: [][]string{{"流水号", "付款方"}, {"A001", "上海银行"}},
+		},
+		{
+			name: "utf-8 bom is stripped",
+			data: append(append([]byte{}, utf8BOM...), "biz_id\nA001\n"...),
+			want: [][]string{{"biz_id"}, {"A001"}},
+		},
+		{
+			name:    "unterminated quote",
+			data:    []byte("biz_id,remark\nA001,\"open\n"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid utf-8 with explicit encoding",
+			data:    append([]byte("biz_id,sender\nA001,"), mustGBK(t, "上海银行\n")...),
+			opts:    ParseOptions{Encoding: EncodingUTF8},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSVRows(bytes.NewReader(tt.data), &tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadCSVRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadCSVRows() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFixedWidthRows(t *testing.T) {
+	columns := []FixedWidthColumn{
+		{Name: "biz_id", Start: 1, Width: 6},
+		{Name: "sender", Start: 7, Width: 8},
+		{Name: "amount", Start: 15, Width: 8},
+	}
+	header := []string{"biz_id", "sender", "amount"}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		opts    ParseOptions
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "ascii with skipped title line",
+			data: []byte("REPORT 20240102\nA00001ACME       12.50\r\nA00002BETA         3\n"),
+			opts: ParseOptions{FixedColumns: columns, SkipLines: 1},
+			want: [][]string{header, {"A00001", "ACME", "12.50"}, {"A00002", "BETA", "3"}},
+		},
+		{
+			name: "gbk measured in bytes",
+			data: mustGBK(t, "A00001上海银行   12.50\n"),
+			opts: ParseOptions{FixedColumns: columns, Encoding: EncodingAuto, FixedUnit: FixedUnitByte},
+			want: [][]string{header, {"A00001", "上海银行", "12.50"}},
+		},
+		{
+			name: "utf-8 measured in characters",
+			data: []byte("\xEF\xBB\xBFA00001上海银行       12.50"),
+			opts: ParseOptions{
+				FixedColumns: []FixedWidthColumn{
+					{Name: "biz_id", Start: 1, Width: 6},
+					{Name: "sender", Start: 7, Width: 8},
+					{Name: "amount", Start: 15, Width: 8},
+				},
+				FixedUnit: FixedUnitChar,
+			},
+			want: [][]string{header, {"A00001", "上海银行", "12.50"}},
+		},
+		{
+			name: "short line leaves missing columns empty",
+			data: []byte("A00001ACME\n"),
+			opts: ParseOptions{FixedColumns: columns},
+			want: [][]string{header, {"A00001", "ACME", ""}},
+		},
+		{
+			name:    "columns not configured",
+			data:    []byte("A00001\n"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFixedWidthRows(bytes.NewReader(tt.data), &tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFixedWidthRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadFixedWidthRows() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFixedWidthRowsDetectsEncodingFromPrefix(t *testing.T) {
+	// 编码检测窗口截断在多字节字符中间时仍应识别为UTF-8
+	line := strings.Repeat("A", 5) + strings.Repeat("银", 30) + "\n"
+	var data []byte
+	for len(data) <= encodingSniffSize {
+		data = append(data, line...)
+	}
+
+	opts := &ParseOptions{
+		FixedColumns: []FixedWidthColumn{{Name: "sender", Start: 6, Width: 2}},
+		FixedUnit:    FixedUnitChar,
+	}
+	rows, err := ReadFixedWidthRows(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("ReadFixedWidthRows() error = %v", err)
+	}
+	if len(rows) != len(data)/len(line)+1 {
+		t.Fatalf("ReadFixedWidthRows() returned %d rows, want %d", len(rows), len(data)/len(line)+1)
+	}
+	if got := rows[len(rows)-1][0]; got != "银银" {
+		t.Errorf("last row = %q, want %q", got, "银银")
+	}
+}
+
+func TestReadRowsEncodingAfterPrefix(t *testing.T) {
+	// 编码检测窗口内均为ASCII, 之后出现的GBK内容按GB18030解码
+	asciiLine := "A0001,ACME\n"
+	var ascii []byte
+	for len(ascii) <= encodingSniffSize {
+		ascii = append(ascii, asciiLine...)
+	}
+	asciiRows := len(ascii) / len(asciiLine)
+
+	// 窗口内已出现UTF-8中文, 之后的非法UTF-8视为编码混杂
+	utf8Line := "A0001,银行\n"
+	var mixed []byte
+	for len(mixed) <= encodingSniffSize {
+		mixed = append(mixed, utf8Line...)
+	}
+	mixed = append(mixed, mustGBK(t, "A0002,银行\n")...)
+
+	t.Run("csv falls back to gb18030", func(t *testing.T) {
+		data := append(append([]byte{}, ascii...), mustGBK(t, "A0002,上海银行\n")...)
+		rows, err := ReadCSVRows(bytes.NewReader(data), &ParseOptions{})
+		if err != nil {
+			t.Fatalf("ReadCSVRows() error = %v", err)
+		}
+		if len(rows) != asciiRows+1 || rows[asciiRows][1] != "上海银行" {
+			t.Errorf("ReadCSVRows() last row = %q, want %q", rows[len(rows)-1], []string{"A0002", "上海银行"})
+		}
+	})
+
+	t.Run("fixed width falls back to gb18030", func(t *testing.T) {
+		data := append(append([]byte{}, ascii...), mustGBK(t, "A0002,上海银行\n")...)
+		opts := &ParseOptions{
+			FixedColumns: []FixedWidthColumn{{Name: "sender", Start: 7, Width: 8}},
+			FixedUnit:    FixedUnitByte,
+		}
+		rows, err := ReadFixedWidthRows(bytes.NewReader(data), opts)
+		if err != nil {
+			t.Fatalf("ReadFixedWidthRows() error = %v", err)
+		}
+		if got := rows[len(rows)-1][0]; got != "上海银行" {
+			t.Errorf("last row = %q, want %q", got, "上海银行")
+		}
+	})
+
+	t.Run("csv rejects mixed encodings", func(t *testing.T) {
+		if _, err := ReadCSVRows(bytes.NewReader(mixed), &ParseOptions{}); err == nil {
+			t.Fatal("ReadCSVRows() error = nil, want invalid utf-8 error")
+		}
+	})
+
+	t.Run("fixed width rejects mixed encodings", func(t *testing.T) {
+		opts := &ParseOptions{FixedColumns: []FixedWidthColumn{{Name: "sender", Start: 7, Width: 8}}}
+		if _, err := ReadFixedWidthRows(bytes.NewReader(mixed), opts); err == nil {
+			t.Fatal("ReadFixedWidthRows() error = nil, want invalid utf-8 error")
+		}
+	})
+}
@@ -34,13 +34,17 @@ CREATE TABLE `transactions` (
   `biz_id` VARCHAR(64) NOT NULL COMMENT '业务流水号',
   `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
   `amount_cipher` VARCHAR(256) NOT NULL COMMENT '金额密文(AES加密)',
-  `amount_hash` VARCHAR(64) NOT NULL COMMENT '金额哈希(用于链上验证)',
-  `data_hash` VARCHAR(64) NOT NULL COMMENT '数据哈希(SHA256,上链用)',
+  `currency` CHAR(3) NOT NULL DEFAULT 'CNY' COMMENT '币种(ISO 4217)',
+  `amount_hash` VARCHAR(64) NOT NULL COMMENT '金额盲索引(HMAC-SHA256,按机构派生密钥)',
+  `data_hash` VARCHAR(64) NOT NULL COMMENT '数据哈希(SHA256(biz_id+amount+currency+salt),上链用)',
   `salt` VARCHAR(64) NOT NULL COMMENT '随机盐',
   `receiver` VARCHAR(128) NOT NULL COMMENT '收款方',
   `sender` VARCHAR(128) NOT NULL COMMENT '付款方',
   `tx_type` TINYINT NOT NULL DEFAULT 1 COMMENT '交易类型: 1-转账, 2-退款, 3-其他',
-  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-待上链, 1-已上链, 2-对账成功, 3-对账失败',
+  `tx_date` DATE DEFAULT NULL COMMENT '交易日期',
+  `batch_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '导入批次ID',
+  `group_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '对账组ID',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-待上链, 1-已上链, 2-对账成功, 3-对账失败, 4-已提交待确认',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
@@ -48,7 +52,13 @@ CREATE TABLE `transactions` (
   KEY `idx_institution_id` (`institution_id`),
   KEY `idx_status` (`status`),
   KEY `idx_created_at` (`created_at`),
-  KEY `idx_data_hash` (`data_hash`)
+  KEY `idx_tx_date` (`tx_date`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_group_id` (`group_id`),
+  KEY `idx_currency` (`currency`),
+  KEY `idx_data_hash` (`data_hash`),
+  KEY `idx_institution_amount` (`institution_id`, `amount_hash`),
+  KEY `idx_institution_created` (`institution_id`, `created_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='交易流水主表';
 
 -- ========================================
@@ -63,13 +73,15 @@ CREATE TABLE `chain_receipts` (
   `block_hash` VARCHAR(128) NOT NULL COMMENT '区块哈希',
   `contract_address` VARCHAR(42) NOT NULL COMMENT '合约地址',
   `gas_used` BIGINT NOT NULL DEFAULT 0 COMMENT 'Gas消耗',
-  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 0-失败, 1-成功',
+  `block_limit` BIGINT NOT NULL DEFAULT 0 COMMENT '交易有效的最大区块高度',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 0-失败, 1-成功, 2-已提交',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_biz_id` (`biz_id`),
   KEY `idx_tx_hash` (`tx_hash`),
   KEY `idx_block_height` (`block_height`),
-  KEY `idx_contract_address` (`contract_address`)
+  KEY `idx_contract_address` (`contract_address`),
+  KEY `idx_status` (`status`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='链上锚定表';
 
 -- ========================================
@@ -81,9 +93,10 @@ CREATE TABLE `reconciliations` (
   `biz_id` VARCHAR(64) NOT NULL COMMENT '业务流水号',
   `party_a` VARCHAR(64) NOT NULL COMMENT '机构A(发起方)',
   `party_b` VARCHAR(64) NOT NULL COMMENT '机构B(对手方)',
-  `status` TINYINT NOT NULL COMMENT '对账状态: 2-成功, 3-失败',
+  `status` TINYINT NOT NULL COMMENT '对账状态: 2-成功, 3-失败, 4-容差匹配',
   `matched_at` DATETIME DEFAULT NULL COMMENT '对账时间',
   `block_height` BIGINT DEFAULT NULL COMMENT '对账成功时的区块高度',
+  `adjustment_id` BIGINT UNSIGNED DEFAULT NULL COMMENT '容差匹配调整记录ID',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
@@ -91,7 +104,8 @@ CREATE TABLE `reconciliations` (
   KEY `idx_party_a` (`party_a`),
   KEY `idx_party_b` (`party_b`),
   KEY `idx_status` (`status`),
-  KEY `idx_matched_at` (`matched_at`)
+  KEY `idx_matched_at` (`matched_at`),
+  KEY `idx_adjustment_id` (`adjustment_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对账记录表';
 
 -- ========================================
@@ -103,12 +117,14 @@ CREATE TABLE `event_logs` (
   `event_type` VARCHAR(64) NOT NULL COMMENT '事件类型',
   `biz_id` VARCHAR(64) DEFAULT NULL COMMENT '业务流水号',
   `tx_hash` VARCHAR(128) NOT NULL COMMENT '交易哈希',
+  `log_index` INT NOT NULL DEFAULT 0 COMMENT '日志在交易回执中的序号',
   `block_height` BIGINT NOT NULL COMMENT '区块高度',
   `contract_address` VARCHAR(42) NOT NULL COMMENT '合约地址',
   `data` TEXT COMMENT '事件数据(JSON)',
   `processed` TINYINT NOT NULL DEFAULT 0 COMMENT '是否已处理: 0-未处理, 1-已处理',
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_tx_log` (`tx_hash`, `log_index`),
   KEY `idx_event_type` (`event_type`),
   KEY `idx_biz_id` (`biz_id`),
   KEY `idx_tx_hash` (`tx_hash`),
@@ -152,6 +168,364 @@ CREATE TABLE `users` (
   KEY `idx_status` (`status`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
 
+-- ========================================
+-- 表8: 导入配置方案表 (import_profiles)
+-- ========================================
+DROP TABLE IF EXISTS `import_profiles`;
+CREATE TABLE `import_profiles` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '方案名称',
+  `description` VARCHAR(256) DEFAULT NULL COMMENT '方案说明',
+  `file_format` VARCHAR(16) DEFAULT NULL COMMENT '文件格式: excel, csv, fixed_width, mt940, camt053(为空按扩展名和内容推断)',
+  `encoding` VARCHAR(16) DEFAULT NULL COMMENT '文本编码: auto, utf-8, gbk, gb18030',
+  `delimiter` VARCHAR(4) DEFAULT NULL COMMENT 'CSV分隔符',
+  `quote_char` VARCHAR(4) DEFAULT NULL COMMENT 'CSV引号字符',
+  `fixed_columns` JSON DEFAULT NULL COMMENT '定长列定义: [{name, start, width}]',
+  `fixed_unit` VARCHAR(8) DEFAULT NULL COMMENT '定长计量单位: byte, char',
+  `skip_lines` INT NOT NULL DEFAULT 0 COMMENT '定长文本开头跳过的行数',
+  `sheet_name` VARCHAR(64) DEFAULT NULL COMMENT '工作表名称(为空取第一个)',
+  `header_row` INT NOT NULL DEFAULT 1 COMMENT '表头所在行(从1开始)',
+  `columns` JSON NOT NULL COMMENT '列映射: 字段 -> {aliases, position}',
+  `date_format` VARCHAR(32) DEFAULT NULL COMMENT '日期格式, 如 yyyy-MM-dd',
+  `decimal_separator` VARCHAR(4) NOT NULL DEFAULT '.' COMMENT '小数分隔符',
+  `thousands_separator` VARCHAR(4) NOT NULL DEFAULT ',' COMMENT '千分位分隔符',
+  `defaults` JSON DEFAULT NULL COMMENT '常量默认值: 字段 -> 值',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 0-禁用, 1-启用',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_institution_name` (`institution_id`, `name`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='导入配置方案表';
+
+-- ========================================
+-- 表9: 对账证明表 (reconciliation_certificates)
+-- ========================================
+DROP TABLE IF EXISTS `reconciliation_certificates`;
+CREATE TABLE `reconciliation_certificates` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `cert_no` VARCHAR(32) NOT NULL COMMENT '证明编号(加锚定前缀作为链上ID)',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '出具机构ID',
+  `counterparty` VARCHAR(64) NOT NULL COMMENT '对手机构ID',
+  `period_start` DATE NOT NULL COMMENT '期间起始日期',
+  `period_end` DATE NOT NULL COMMENT '期间截止日期',
+  `item_count` INT NOT NULL DEFAULT 0 COMMENT '交易笔数',
+  `payload` MEDIUMTEXT NOT NULL COMMENT '规范化证明内容(JSON)',
+  `digest` VARCHAR(64) NOT NULL COMMENT '证明摘要: SHA256(payload)',
+  `signature` VARCHAR(132) NOT NULL COMMENT '机构签名(secp256k1, hex)',
+  `signer` VARCHAR(42) NOT NULL COMMENT '签名地址',
+  `anchor_tx_hash` VARCHAR(128) DEFAULT NULL COMMENT '锚定交易哈希',
+  `anchor_block_height` BIGINT NOT NULL DEFAULT 0 COMMENT '锚定区块高度',
+  `anchored_at` DATETIME DEFAULT NULL COMMENT '锚定时间',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-待锚定, 1-已锚定, 2-锚定失败',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_cert_no` (`cert_no`),
+  KEY `idx_institution_id` (`institution_id`),
+  KEY `idx_counterparty` (`counterparty`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对账证明表';
+
+-- ========================================
+-- 表10: 导入批次表 (import_batches)
+-- ========================================
+DROP TABLE IF EXISTS `import_batches`;
+CREATE TABLE `import_batches` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `batch_no` VARCHAR(32) NOT NULL COMMENT '批次号',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `file_name` VARCHAR(256) DEFAULT NULL COMMENT '原始文件名',
+  `file_digest` VARCHAR(64) NOT NULL COMMENT '文件摘要(SHA256), 用于重复文件检测',
+  `file_size` BIGINT NOT NULL DEFAULT 0 COMMENT '文件大小(字节)',
+  `file_format` VARCHAR(16) DEFAULT NULL COMMENT '文件格式',
+  `uploader` VARCHAR(64) DEFAULT NULL COMMENT '上传人',
+  `profile_name` VARCHAR(64) DEFAULT NULL COMMENT '导入方案名称',
+  `mode` VARCHAR(16) DEFAULT NULL COMMENT '导入模式: partial, strict',
+  `total_rows` INT NOT NULL DEFAULT 0 COMMENT '数据总行数',
+  `success_rows` INT NOT NULL DEFAULT 0 COMMENT '成功导入行数',
+  `failed_rows` INT NOT NULL DEFAULT 0 COMMENT '失败行数',
+  `rollback_rows` INT NOT NULL DEFAULT 0 COMMENT '回滚删除行数',
+  `error_report_id` VARCHAR(32) DEFAULT NULL COMMENT '错误标注工作簿ID',
+  `error_message` VARCHAR(512) DEFAULT NULL COMMENT '失败原因',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-导入中, 1-已完成, 2-已拒绝, 3-导入失败, 4-已回滚',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_batch_no` (`batch_no`),
+  KEY `idx_institution_digest` (`institution_id`, `file_digest`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='导入批次表';
+
+-- ========================================
+-- 表11: 对账组表 (reconciliation_groups)
+-- ========================================
+DROP TABLE IF EXISTS `reconciliation_groups`;
+CREATE TABLE `reconciliation_groups` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `group_ref` VARCHAR(64) NOT NULL COMMENT '组参考号(链上ID)',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `counterparty` VARCHAR(64) DEFAULT NULL COMMENT '对手机构ID',
+  `group_type` VARCHAR(16) NOT NULL COMMENT '分组类型: one_to_many, many_to_one',
+  `parent_biz_id` VARCHAR(64) DEFAULT NULL COMMENT '本方汇总交易业务流水号',
+  `currency` CHAR(3) NOT NULL DEFAULT 'CNY' COMMENT '币种(ISO 4217)',
+  `total_cipher` VARCHAR(256) NOT NULL COMMENT '合计金额密文',
+  `item_count` INT NOT NULL DEFAULT 0 COMMENT '明细笔数',
+  `commitment` VARCHAR(64) NOT NULL COMMENT '组承诺(上链哈希)',
+  `tx_hash` VARCHAR(128) DEFAULT NULL COMMENT '上链交易哈希',
+  `block_height` BIGINT NOT NULL DEFAULT 0 COMMENT '上链区块高度',
+  `chain_counterparty` VARCHAR(42) DEFAULT NULL COMMENT '链上对手方地址',
+  `matched_at` DATETIME DEFAULT NULL COMMENT '对账时间',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-待上链, 1-已上链, 2-对账成功, 3-对账失败, 4-上链中',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_institution_group_ref` (`institution_id`, `group_ref`),
+  KEY `idx_counterparty` (`counterparty`),
+  KEY `idx_parent_biz_id` (`parent_biz_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对账组表';
+
+-- ========================================
+-- 表12: 对账组明细表 (reconciliation_group_items)
+-- ========================================
+DROP TABLE IF EXISTS `reconciliation_group_items`;
+CREATE TABLE `reconciliation_group_items` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `group_id` BIGINT UNSIGNED NOT NULL COMMENT '对账组ID',
+  `seq` INT NOT NULL DEFAULT 0 COMMENT '序号',
+  `child_ref` VARCHAR(64) NOT NULL COMMENT '明细参考号',
+  `biz_id` VARCHAR(64) DEFAULT NULL COMMENT '关联的本方交易业务流水号',
+  `amount_cipher` VARCHAR(256) NOT NULL COMMENT '明细金额密文',
+  `item_hash` VARCHAR(64) NOT NULL COMMENT '明细承诺',
+  PRIMARY KEY (`id`),
+  KEY `idx_group_id` (`group_id`),
+  KEY `idx_biz_id` (`biz_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对账组明细表';
+
+-- ========================================
+-- 表13: 容差匹配调整表 (match_adjustments)
+-- ========================================
+DROP TABLE IF EXISTS `match_adjustments`;
+CREATE TABLE `match_adjustments` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `biz_id` VARCHAR(64) NOT NULL COMMENT '本方业务流水号',
+  `counterparty` VARCHAR(64) NOT NULL COMMENT '对手机构ID',
+  `counterparty_biz_id` VARCHAR(64) NOT NULL COMMENT '对手方业务流水号',
+  `amount_delta` VARCHAR(64) NOT NULL COMMENT '金额差额(本方-对手方)',
+  `date_delta_days` INT NOT NULL DEFAULT 0 COMMENT '交易日期相差天数',
+  `score` DOUBLE NOT NULL DEFAULT 0 COMMENT '匹配得分',
+  `adjustment_type` VARCHAR(16) NOT NULL COMMENT '调整类型: fee-手续费, fx_rounding-汇率尾差, timing-日期差异, other-其他',
+  `reason` VARCHAR(512) NOT NULL COMMENT '调整原因',
+  `operator` VARCHAR(64) DEFAULT NULL COMMENT '提出人',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-待对手方确认, 1-已确认',
+  `local_status` TINYINT NOT NULL DEFAULT 0 COMMENT '本方交易确认前状态',
+  `counterparty_status` TINYINT NOT NULL DEFAULT 0 COMMENT '对手方交易确认前状态',
+  `confirmed_by` VARCHAR(64) DEFAULT NULL COMMENT '对手方确认人',
+  `confirmed_at` DATETIME DEFAULT NULL COMMENT '确认时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_match_adjustments_biz_id` (`biz_id`),
+  UNIQUE KEY `idx_match_adjustments_counterparty_biz_id` (`counterparty_biz_id`),
+  KEY `idx_institution_id` (`institution_id`),
+  KEY `idx_counterparty` (`counterparty`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='容差匹配调整表';
+
+-- ========================================
+-- 表14: 对账失败字段诊断表 (mismatch_diagnoses)
+-- ========================================
+DROP TABLE IF EXISTS `mismatch_diagnoses`;
+CREATE TABLE `mismatch_diagnoses` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `biz_id` VARCHAR(64) NOT NULL COMMENT '业务流水号',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `counterparty` VARCHAR(64) DEFAULT NULL COMMENT '对手机构ID',
+  `role` VARCHAR(16) NOT NULL COMMENT '角色: initiator-发起方, responder-响应方',
+  `key_cipher` VARCHAR(256) NOT NULL COMMENT '本方诊断私钥密文',
+  `key_id` VARCHAR(16) NOT NULL COMMENT '诊断会话标识(发起方私钥指纹)',
+  `local_amount_hash` VARCHAR(64) NOT NULL COMMENT '本方金额盲化值',
+  `local_sender_hash` VARCHAR(64) NOT NULL COMMENT '本方付款方盲化值',
+  `local_receiver_hash` VARCHAR(64) NOT NULL COMMENT '本方收款方盲化值',
+  `local_type_hash` VARCHAR(64) NOT NULL COMMENT '本方交易类型盲化值',
+  `local_currency_hash` VARCHAR(64) DEFAULT NULL COMMENT '本方币种盲化值',
+  `remote_amount_hash` VARCHAR(64) DEFAULT NULL COMMENT '对手方金额二次盲化值(本方计算)',
+  `remote_sender_hash` VARCHAR(64) DEFAULT NULL COMMENT '对手方付款方二次盲化值(本方计算)',
+  `remote_receiver_hash` VARCHAR(64) DEFAULT NULL COMMENT '对手方收款方二次盲化值(本方计算)',
+  `remote_type_hash` VARCHAR(64) DEFAULT NULL COMMENT '对手方交易类型二次盲化值(本方计算)',
+  `remote_currency_hash` VARCHAR(64) DEFAULT NULL COMMENT '对手方币种二次盲化值(本方计算)',
+  `returned_amount_hash` VARCHAR(64) DEFAULT NULL COMMENT '本方金额二次盲化值(对手方回传)',
+  `returned_sender_hash` VARCHAR(64) DEFAULT NULL COMMENT '本方付款方二次盲化值(对手方回传)',
+  `returned_receiver_hash` VARCHAR(64) DEFAULT NULL COMMENT '本方收款方二次盲化值(对手方回传)',
+  `returned_type_hash` VARCHAR(64) DEFAULT NULL COMMENT '本方交易类型二次盲化值(对手方回传)',
+  `returned_currency_hash` VARCHAR(64) DEFAULT NULL COMMENT '本方币种二次盲化值(对手方回传)',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-等待对手方, 1-已完成',
+  `completed_at` DATETIME DEFAULT NULL COMMENT '完成比对时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_mismatch_diagnoses_biz_id` (`biz_id`),
+  KEY `idx_institution_id` (`institution_id`),
+  KEY `idx_counterparty` (`counterparty`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对账失败字段诊断表';
+
+-- ========================================
+-- 表15: 汇率参考表 (fx_rates)
+-- ========================================
+DROP TABLE IF EXISTS `fx_rates`;
+CREATE TABLE `fx_rates` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `base_currency` CHAR(3) NOT NULL COMMENT '基准币种',
+  `quote_currency` CHAR(3) NOT NULL COMMENT '报价币种',
+  `rate_date` DATE NOT NULL COMMENT '汇率日期',
+  `rate` VARCHAR(40) NOT NULL COMMENT '汇率: 1单位基准币种 = rate单位报价币种',
+  `source` VARCHAR(64) DEFAULT NULL COMMENT '来源',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_fx_pair_date` (`base_currency`, `quote_currency`, `rate_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='汇率参考表';
+
+-- ========================================
+-- 表16: 单边上链逾期告警表 (aging_alerts)
+-- ========================================
+DROP TABLE IF EXISTS `aging_alerts`;
+CREATE TABLE `aging_alerts` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `counterparty` VARCHAR(128) NOT NULL COMMENT '对手方',
+  `overdue_count` BIGINT NOT NULL DEFAULT 0 COMMENT '逾期笔数',
+  `threshold` INT NOT NULL COMMENT '告警阈值',
+  `oldest_days` INT NOT NULL DEFAULT 0 COMMENT '最长账龄(天)',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-告警中 1-已确认 2-已解除',
+  `acknowledged_by` VARCHAR(64) DEFAULT NULL COMMENT '确认人',
+  `acknowledged_at` DATETIME DEFAULT NULL COMMENT '确认时间',
+  `resolved_at` DATETIME DEFAULT NULL COMMENT '解除时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_aging_alert_party` (`institution_id`, `counterparty`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='单边上链逾期告警表';
+
+-- ========================================
+-- 表17: Webhook订阅表 (webhook_subscriptions)
+-- ========================================
+DROP TABLE IF EXISTS `webhook_subscriptions`;
+CREATE TABLE `webhook_subscriptions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `url` VARCHAR(512) NOT NULL COMMENT '推送地址',
+  `event_types` VARCHAR(255) NOT NULL COMMENT '订阅事件类型(逗号分隔)',
+  `secret_cipher` VARCHAR(512) NOT NULL COMMENT '签名密钥密文(AES)',
+  `description` VARCHAR(255) DEFAULT NULL COMMENT '说明',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `created_by` VARCHAR(64) DEFAULT NULL COMMENT '创建人',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_institution_id` (`institution_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Webhook订阅表';
+
+-- ========================================
+-- 表18: Webhook推送记录表 (webhook_deliveries)
+-- ========================================
+DROP TABLE IF EXISTS `webhook_deliveries`;
+CREATE TABLE `webhook_deliveries` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `subscription_id` BIGINT UNSIGNED NOT NULL COMMENT '订阅ID',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `event_id` VARCHAR(64) NOT NULL COMMENT '事件ID(重试及重放不变)',
+  `event_type` VARCHAR(64) NOT NULL COMMENT '事件类型',
+  `biz_id` VARCHAR(64) DEFAULT NULL COMMENT '业务流水号',
+  `payload` TEXT NOT NULL COMMENT '推送内容(JSON)',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-待推送 1-推送中 2-推送成功 3-推送失败',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+  `response_code` INT DEFAULT NULL COMMENT '最近一次响应码',
+  `last_error` VARCHAR(512) DEFAULT NULL COMMENT '最近一次错误',
+  `next_retry_at` DATETIME DEFAULT NULL COMMENT '下次重试时间',
+  `delivered_at` DATETIME DEFAULT NULL COMMENT '推送成功时间',
+  `replay_of` BIGINT UNSIGNED DEFAULT NULL COMMENT '重放的原推送记录ID',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_subscription_id` (`subscription_id`),
+  KEY `idx_institution_id` (`institution_id`),
+  KEY `idx_event_id` (`event_id`),
+  KEY `idx_biz_id` (`biz_id`),
+  KEY `idx_status` (`status`),
+  KEY `idx_next_retry_at` (`next_retry_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Webhook推送记录表';
+
+-- ========================================
+-- 表19: 邮件通知偏好表 (notification_preferences)
+-- ========================================
+DROP TABLE IF EXISTS `notification_preferences`;
+CREATE TABLE `notification_preferences` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `language` VARCHAR(8) NOT NULL DEFAULT 'zh' COMMENT '邮件语言: zh, en',
+  `immediate_mismatch` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '对账失败时立即通知',
+  `daily_digest` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '接收每日摘要',
+  `last_digest_at` DATETIME DEFAULT NULL COMMENT '最近一次摘要截止时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邮件通知偏好表';
+
+-- ========================================
+-- 表20: 链上一致性检查记录表 (consistency_runs)
+-- ========================================
+DROP TABLE IF EXISTS `consistency_runs`;
+CREATE TABLE `consistency_runs` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `trigger` VARCHAR(16) NOT NULL COMMENT '触发方式: scheduled, manual',
+  `biz_id_from` VARCHAR(64) DEFAULT NULL COMMENT '业务流水号起(含), 为空不限',
+  `biz_id_to` VARCHAR(64) DEFAULT NULL COMMENT '业务流水号止(含), 为空不限',
+  `auto_heal` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否按链上状态自动修复本地状态',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态: 0-进行中 1-已完成 2-异常中止',
+  `checked` BIGINT NOT NULL DEFAULT 0 COMMENT '已检查笔数',
+  `drifted` BIGINT NOT NULL DEFAULT 0 COMMENT '存在偏差笔数',
+  `healed` BIGINT NOT NULL DEFAULT 0 COMMENT '已修复笔数',
+  `errors` BIGINT NOT NULL DEFAULT 0 COMMENT '查询链上失败笔数',
+  `last_error` VARCHAR(512) DEFAULT NULL COMMENT '最近一次错误',
+  `started_by` VARCHAR(64) DEFAULT NULL COMMENT '发起人, 定时检查为空',
+  `started_at` DATETIME NOT NULL COMMENT '开始时间',
+  `finished_at` DATETIME DEFAULT NULL COMMENT '结束时间',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='链上一致性检查记录表';
+
+-- ========================================
+-- 表21: 链上一致性偏差明细表 (consistency_drifts)
+-- ========================================
+DROP TABLE IF EXISTS `consistency_drifts`;
+CREATE TABLE `consistency_drifts` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `run_id` BIGINT UNSIGNED NOT NULL COMMENT '检查记录ID',
+  `biz_id` VARCHAR(64) NOT NULL COMMENT '业务流水号',
+  `institution_id` VARCHAR(64) NOT NULL COMMENT '机构ID',
+  `drift_type` VARCHAR(32) NOT NULL COMMENT '偏差类型: missing_on_chain, hash_mismatch, status_mismatch',
+  `local_status` TINYINT NOT NULL COMMENT '检查时的本地状态',
+  `chain_status` TINYINT DEFAULT NULL COMMENT '链上状态, 链上无记录时为空',
+  `local_hash` VARCHAR(66) DEFAULT NULL COMMENT '本地数据哈希',
+  `chain_hash` VARCHAR(66) DEFAULT NULL COMMENT '链上数据哈希',
+  `healed` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否已自动修复',
+  `detail` VARCHAR(255) DEFAULT NULL COMMENT '说明',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_run_id` (`run_id`),
+  KEY `idx_biz_id` (`biz_id`),
+  KEY `idx_institution_id` (`institution_id`),
+  KEY `idx_drift_type` (`drift_type`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='链上一致性偏差明细表';
+
 -- ========================================
 -- 初始化数据
 -- ========================================